@@ -0,0 +1,277 @@
+// autoscale-sim replays a recorded CPU/memory series through the autoscaler's decision
+// code and prints the replica count it would have run at each step.
+//
+//	autoscale-sim -metrics metrics.csv -service service.json -replicas 2
+//
+// Metrics are CSV with a header row (timestamp,cpu,memory) or a JSON array of
+// {"timestamp": ..., "cpu": ..., "memory": ...} objects. cpu and memory are fractions
+// of the limit, timestamps are RFC 3339 or unix seconds and can be left out, in which
+// case samples are spaced by -interval. The service config uses the same fields as
+// POST /autoscale/register-service.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/autoscale"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+type jsonSample struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	Cpu       float64         `json:"cpu"`
+	Memory    float64         `json:"memory"`
+}
+
+// samples without timestamps start here so runs are reproducible
+var simulationStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func main() {
+	metricsPath := flag.String("metrics", "", "CSV or JSON file with the cpu/memory series (required)")
+	servicePath := flag.String("service", "", "JSON file with the service config, defaults are used if left out")
+	replicas := flag.Int("replicas", 1, "replica count at the start of the series")
+	interval := flag.Duration("interval", 10*time.Second, "time between samples that have no timestamp")
+	historySize := flag.Int("history-size", 12, "number of samples kept in the metric history (METRIC_HISTORY_SIZE)")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+
+	if err := run(*metricsPath, *servicePath, *replicas, *interval, *historySize, *format); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(metricsPath string, servicePath string, replicas int, interval time.Duration, historySize int, format string) error {
+	if metricsPath == "" {
+		return errors.New("-metrics is required")
+	}
+
+	samples, err := readSamples(metricsPath, interval)
+	if err != nil {
+		return err
+	}
+
+	var serviceRequest autoscale.RegisterServiceRequest
+	if servicePath != "" {
+		serviceBytes, err := os.ReadFile(servicePath)
+		if err != nil {
+			return fmt.Errorf("error reading service config: %w", err)
+		}
+
+		if err := json.Unmarshal(serviceBytes, &serviceRequest); err != nil {
+			return fmt.Errorf("error parsing service config: %w", err)
+		}
+	}
+
+	service, err := autoscale.NewSimulatedService(serviceRequest)
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	steps, err := autoscale.Simulate(logger, &types.Config{MetricHistorySize: historySize}, service, replicas, samples)
+	if err != nil {
+		return fmt.Errorf("error simulating: %w", err)
+	}
+
+	switch format {
+	case "json":
+		return printJSON(steps)
+	case "text":
+		printTimeline(steps)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func readSamples(path string, interval time.Duration) ([]autoscale.SimulationSample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening metrics: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return readJSONSamples(file, interval)
+	}
+
+	return readCSVSamples(file, interval)
+}
+
+func readCSVSamples(r io.Reader, interval time.Duration) ([]autoscale.SimulationSample, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("csv has no header row")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	cpuColumn, hasCpu := columns["cpu"]
+	memColumn, hasMem := columns["memory"]
+	timeColumn, hasTime := columns["timestamp"]
+
+	if !hasCpu || !hasMem {
+		return nil, errors.New("csv needs cpu and memory columns")
+	}
+
+	samples := make([]autoscale.SimulationSample, 0, len(records)-1)
+
+	for i, record := range records[1:] {
+		sample := autoscale.SimulationSample{Time: simulationStart.Add(time.Duration(i) * interval)}
+
+		if sample.CpuPercent, err = strconv.ParseFloat(strings.TrimSpace(record[cpuColumn]), 64); err != nil {
+			return nil, fmt.Errorf("row %d: error parsing cpu: %w", i+2, err)
+		}
+		if sample.MemPercent, err = strconv.ParseFloat(strings.TrimSpace(record[memColumn]), 64); err != nil {
+			return nil, fmt.Errorf("row %d: error parsing memory: %w", i+2, err)
+		}
+
+		if hasTime && strings.TrimSpace(record[timeColumn]) != "" {
+			if sample.Time, err = parseTimestamp(strings.TrimSpace(record[timeColumn])); err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+func readJSONSamples(r io.Reader, interval time.Duration) ([]autoscale.SimulationSample, error) {
+	var jsonSamples []jsonSample
+	if err := json.NewDecoder(r).Decode(&jsonSamples); err != nil {
+		return nil, fmt.Errorf("error reading json: %w", err)
+	}
+
+	samples := make([]autoscale.SimulationSample, 0, len(jsonSamples))
+
+	for i, jsonSample := range jsonSamples {
+		sample := autoscale.SimulationSample{
+			Time:       simulationStart.Add(time.Duration(i) * interval),
+			CpuPercent: jsonSample.Cpu,
+			MemPercent: jsonSample.Memory,
+		}
+
+		if len(jsonSample.Timestamp) > 0 && string(jsonSample.Timestamp) != "null" {
+			timestamp := strings.Trim(string(jsonSample.Timestamp), `"`)
+
+			var err error
+			if sample.Time, err = parseTimestamp(timestamp); err != nil {
+				return nil, fmt.Errorf("sample %d: %w", i, err)
+			}
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q is neither unix seconds nor RFC 3339", value)
+	}
+
+	return t, nil
+}
+
+func printTimeline(steps []autoscale.SimulationStep) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TIME\tCPU\tMEM\tAVG CPU\tAVG MEM\tCPU TREND\tMEM TREND\tREPLICAS\tDECISION\tREASON")
+
+	for _, step := range steps {
+		ctx := step.Context
+
+		fmt.Fprintf(w, "%s\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%+.4f\t%+.4f\t%d\t%+d\t%s\n",
+			ctx.Now.Format(time.RFC3339),
+			ctx.CpuPercent*100,
+			ctx.MemPercent*100,
+			ctx.AvgCpu*100,
+			ctx.AvgMem*100,
+			ctx.CpuTrend,
+			ctx.MemTrend,
+			ctx.CurrentReplicas,
+			step.Decision,
+			step.Reason,
+		)
+	}
+
+	w.Flush()
+
+	fmt.Println()
+	fmt.Println("Events:")
+
+	upscales, downscales, skipped := 0, 0, 0
+	for _, step := range steps {
+		switch step.Status {
+		case "applied":
+			if step.Decision > 0 {
+				upscales++
+			} else {
+				downscales++
+			}
+
+			fmt.Printf("  %s  %d -> %d replicas (%s)\n", step.Context.Now.Format(time.RFC3339), step.Context.CurrentReplicas, step.NewReplicas, step.Reason)
+		case "skipped":
+			skipped++
+
+			fmt.Printf("  %s  %+d replicas skipped, outside of min/max (%s)\n", step.Context.Now.Format(time.RFC3339), step.Decision, step.Reason)
+		}
+	}
+
+	finalReplicas := 0
+	if len(steps) > 0 {
+		finalReplicas = steps[len(steps)-1].NewReplicas
+	}
+
+	fmt.Println()
+	fmt.Printf("%d samples, %d upscales, %d downscales, %d skipped, %d replicas at the end\n", len(steps), upscales, downscales, skipped, finalReplicas)
+}
+
+func printJSON(steps []autoscale.SimulationStep) error {
+	type jsonStep struct {
+		Context     types.ScalingContext `json:"context"`
+		Decision    int                  `json:"decision"`
+		Reason      string               `json:"reason"`
+		NewReplicas int                  `json:"new_replicas"`
+		Status      string               `json:"status"`
+	}
+
+	output := make([]jsonStep, 0, len(steps))
+	for _, step := range steps {
+		output = append(output, jsonStep(step))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(output)
+}
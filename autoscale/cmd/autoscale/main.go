@@ -5,22 +5,31 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/caarlos0/env"
 	"github.com/ferretcode/switchyard/autoscale/internal/autoscale"
+	"github.com/ferretcode/switchyard/autoscale/internal/leader"
+	messagebus "github.com/ferretcode/switchyard/autoscale/internal/message_bus"
+	"github.com/ferretcode/switchyard/autoscale/internal/metrics"
+	"github.com/ferretcode/switchyard/autoscale/internal/prometheus"
 	"github.com/ferretcode/switchyard/autoscale/internal/railway"
 	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/internal/webhook"
 	"github.com/ferretcode/switchyard/autoscale/pkg/types"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	amqp "github.com/rabbitmq/amqp091-go"
 
 	_ "github.com/lib/pq"
 )
 
 var logger *slog.Logger
 var config types.Config
+var prometheusMetrics types.PrometheusMetrics
 
 func main() {
 	ctx := context.Background()
@@ -49,20 +58,56 @@ func main() {
 
 	gqlClient, err := railway.NewClient(&railway.GraphQLClient{
 		AuthToken: config.RailwayApiKey,
-		BaseURL:   "https://backboard.railway.app/graphql/v2",
+		BaseURL:   config.RailwayApiUrl,
+		Logger:    logger,
 	})
 	if err != nil {
 		logger.Error("error creating graphql client", "err", err)
 		return
 	}
 
+	prometheusMetrics = prometheus.Init()
+
 	queries := repositories.New(conn)
-	serviceHistoryCache := types.ServiceHistoryCache{
-		ServiceHistories: make(map[string]types.MetricHistory),
+	serviceStateCache := types.ServiceStateCache{
+		ServiceStates: make(map[string]*types.ServiceState),
+	}
+
+	gqlQueries := railway.NewQueryService(gqlClient, ctx, config, logger, &prometheusMetrics)
+	railwayMetrics := metrics.NewRailwayProvider(&gqlQueries)
+	metricProviders := map[string]metrics.Provider{
+		metrics.ProviderRailway: &railwayMetrics,
+	}
+
+	if config.PrometheusUrl != "" {
+		prometheusMetrics := metrics.NewPrometheusProvider(config.PrometheusUrl)
+		metricProviders[metrics.ProviderPrometheus] = &prometheusMetrics
+	}
+
+	// notifications still go to the webhook when the message bus is down or not configured
+	var messageBusService *messagebus.MessageBusService
+
+	if config.MessageBusUrl != "" {
+		messageBusConn, err := amqp.Dial(config.MessageBusUrl)
+		if err != nil {
+			logger.Error("error connecting to the message bus", "err", err)
+		} else {
+			defer messageBusConn.Close()
+
+			service := messagebus.NewMessageBusService(logger, messageBusConn, &config, ctx)
+			messageBusService = &service
+		}
+	}
+
+	webhookService := webhook.NewWebhookService(logger, &config, ctx, messageBusService)
+
+	autoscalingService := autoscale.NewAutoscaleService(logger, &config, &gqlQueries, queries, conn, ctx, &serviceStateCache, &railwayMetrics, metricProviders, &prometheusMetrics, &webhookService)
+
+	if config.InstanceId == "" {
+		config.InstanceId = instanceId()
 	}
 
-	gqlQueries := railway.NewQueryService(gqlClient, ctx, config, logger)
-	autoscalingService := autoscale.NewAutoscaleService(logger, &config, &gqlQueries, queries, ctx, &serviceHistoryCache)
+	leaderService := leader.NewLeaderService(logger, &config, conn, ctx)
 
 	r := chi.NewRouter()
 
@@ -70,6 +115,10 @@ func main() {
 	r.Use(middleware.Recoverer)
 
 	r.Route("/autoscale", func(r chi.Router) {
+		r.Get("/status", func(w http.ResponseWriter, r *http.Request) {
+			handleError(leaderService.GetStatus(w, r), w, "autoscale/status")
+		})
+
 		r.Post("/upsert-service", func(w http.ResponseWriter, r *http.Request) {
 			handleError(autoscalingService.UpsertService(w, r), w, "autoscale/upsert")
 		})
@@ -89,12 +138,117 @@ func main() {
 		r.Patch("/set-service-enabled/{id}", func(w http.ResponseWriter, r *http.Request) {
 			handleError(autoscalingService.SetServiceEnabled(w, r), w, "autoscale/set-enabled")
 		})
+
+		r.Get("/services/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ListServiceEvents(w, r), w, "autoscale/list-events")
+		})
+
+		r.Get("/services/{id}/shadow-report", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.GetShadowReport(w, r), w, "autoscale/shadow-report")
+		})
+
+		r.Get("/services/{id}/forecast", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.GetServiceForecast(w, r), w, "autoscale/forecast")
+		})
+
+		r.Get("/services/{id}/schedules", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ListServiceSchedules(w, r), w, "autoscale/list-schedules")
+		})
+
+		r.Post("/services/{id}/schedules", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.CreateServiceSchedule(w, r), w, "autoscale/create-schedule")
+		})
+
+		r.Put("/services/{id}/schedules/{scheduleId}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.UpdateServiceSchedule(w, r), w, "autoscale/update-schedule")
+		})
+
+		r.Delete("/services/{id}/schedules/{scheduleId}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.DeleteServiceSchedule(w, r), w, "autoscale/delete-schedule")
+		})
+
+		r.Get("/services/{id}/metrics", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ListServiceMetrics(w, r), w, "autoscale/list-metrics")
+		})
+
+		r.Put("/services/{id}/metrics/{name}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.UpsertServiceMetric(w, r), w, "autoscale/upsert-metric")
+		})
+
+		r.Delete("/services/{id}/metrics/{name}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.DeleteServiceMetric(w, r), w, "autoscale/delete-metric")
+		})
+
+		r.Post("/services/{id}/override", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.SetServiceOverride(w, r), w, "autoscale/set-override")
+		})
+
+		r.Delete("/services/{id}/override", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.DeleteServiceOverride(w, r), w, "autoscale/delete-override")
+		})
+
+		r.Get("/services/{id}/regions", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ListServiceRegions(w, r), w, "autoscale/list-regions")
+		})
+
+		r.Put("/services/{id}/regions/{region}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.UpsertServiceRegion(w, r), w, "autoscale/upsert-region")
+		})
+
+		r.Delete("/services/{id}/regions/{region}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.DeleteServiceRegion(w, r), w, "autoscale/delete-region")
+		})
+
+		r.Get("/export", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ExportServices(w, r), w, "autoscale/export")
+		})
+
+		r.Post("/apply", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ApplyServices(w, r), w, "autoscale/apply")
+		})
+
+		r.Get("/budgets", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.ListReplicaBudgets(w, r), w, "autoscale/list-budgets")
+		})
+
+		r.Put("/budgets/{group}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.UpsertReplicaBudget(w, r), w, "autoscale/upsert-budget")
+		})
+
+		r.Delete("/budgets/{group}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(autoscalingService.DeleteReplicaBudget(w, r), w, "autoscale/delete-budget")
+		})
+	})
+
+	r.Handle("/metrics", promhttp.Handler())
+
+	// every instance serves the api, only the leader scales
+	go leaderService.Run(func(ctx context.Context) {
+		if err := autoscalingService.LoadServiceStates(); err != nil {
+			logger.Error("error loading autoscaler state", "err", err)
+			return
+		}
+
+		autoscalingService.StartAutoscaling(ctx)
 	})
 
-	go autoscalingService.StartAutoscaling()
 	http.ListenAndServe(":"+config.Port, r)
 }
 
+// instanceId identifies this instance in the leader election, preferring Railway's replica id
+func instanceId() string {
+	if replicaId := os.Getenv("RAILWAY_REPLICA_ID"); replicaId != "" {
+		return replicaId
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "autoscale-" + strconv.Itoa(os.Getpid())
+	}
+
+	return hostname
+}
+
 func handleError(err error, w http.ResponseWriter, svc string) {
 	if err != nil {
 		http.Error(w, "there was an error processing your request: "+err.Error(), http.StatusInternalServerError)
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
 	"github.com/go-chi/chi/v5"
@@ -19,77 +21,10 @@ func (a *AutoscaleService) UpsertService(w http.ResponseWriter, r *http.Request)
 
 	var upsertServiceRequest UpsertServiceRequest
 	if err := json.Unmarshal(requestBytes, &upsertServiceRequest); err != nil {
-		return fmt.Errorf("error parsing request body: %w", err)
+		return writeValidationErrors(w, "invalid service config", []FieldError{requestFieldError(err)})
 	}
 
-	if upsertServiceRequest.ServiceId == nil || *upsertServiceRequest.ServiceId == "" {
-		http.Error(w, "Service ID is required", http.StatusBadRequest)
-		return nil
-	}
-
-	_, err = a.Queries.GetService(a.Context, *upsertServiceRequest.ServiceId)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("error fetching service from database: %w", err)
-	}
-
-	params := populateUpsertServiceParams(&upsertServiceRequest)
-
-	if err == sql.ErrNoRows {
-		createParams := repositories.CreateServiceParams{
-			ServiceID: *upsertServiceRequest.ServiceId,
-			JobName:   sql.NullString{Valid: false},
-			Enabled:   false,
-		}
-
-		if upsertServiceRequest.JobName != nil {
-			createParams.JobName = sql.NullString{String: *upsertServiceRequest.JobName, Valid: true}
-		}
-		if upsertServiceRequest.Enabled != nil {
-			createParams.Enabled = *upsertServiceRequest.Enabled
-		}
-		if upsertServiceRequest.RailwayMemoryUpscaleThreshold != nil {
-			createParams.RailwayMemoryUpscaleThreshold = *upsertServiceRequest.RailwayMemoryUpscaleThreshold
-		}
-		if upsertServiceRequest.RailwayCPUUpscaleThreshold != nil {
-			createParams.RailwayCpuUpscaleThreshold = *upsertServiceRequest.RailwayCPUUpscaleThreshold
-		}
-		if upsertServiceRequest.RailwayMemoryDownscaleThreshold != nil {
-			createParams.RailwayMemoryDownscaleThreshold = *upsertServiceRequest.RailwayMemoryDownscaleThreshold
-		}
-		if upsertServiceRequest.RailwayCPUDownscaleThreshold != nil {
-			createParams.RailwayCpuDownscaleThreshold = *upsertServiceRequest.RailwayCPUDownscaleThreshold
-		}
-		if upsertServiceRequest.UpscaleCooldown != nil {
-			createParams.UpscaleCooldown = *upsertServiceRequest.UpscaleCooldown
-		}
-		if upsertServiceRequest.DownscaleCooldown != nil {
-			createParams.DownscaleCooldown = *upsertServiceRequest.DownscaleCooldown
-		}
-		if upsertServiceRequest.MinReplicaCount != nil {
-			createParams.MinReplicaCount = int32(*upsertServiceRequest.MinReplicaCount)
-		}
-		if upsertServiceRequest.MaxReplicaCount != nil {
-			createParams.MaxReplicaCount = int32(*upsertServiceRequest.MaxReplicaCount)
-		}
-
-		createParams = applyDefaultsForCreate(createParams)
-
-		_, err = a.Queries.CreateService(a.Context, createParams)
-		if err != nil {
-			return fmt.Errorf("error creating service: %w", err)
-		}
-
-		w.WriteHeader(http.StatusOK)
-		return nil
-	}
-
-	_, err = a.Queries.UpdateService(a.Context, params)
-	if err != nil {
-		return fmt.Errorf("error updating service: %w", err)
-	}
-
-	w.WriteHeader(http.StatusOK)
-	return nil
+	return a.upsertService(w, &upsertServiceRequest)
 }
 
 func (a *AutoscaleService) RegisterService(w http.ResponseWriter, r *http.Request) error {
@@ -100,30 +35,50 @@ func (a *AutoscaleService) RegisterService(w http.ResponseWriter, r *http.Reques
 
 	var registerServiceRequest RegisterServiceRequest
 	if err := json.Unmarshal(requestBytes, &registerServiceRequest); err != nil {
-		return fmt.Errorf("error parsing request body: %w", err)
+		return writeValidationErrors(w, "invalid service config", []FieldError{requestFieldError(err)})
 	}
 
-	if registerServiceRequest.ServiceId == nil || *registerServiceRequest.ServiceId == "" {
-		http.Error(w, "Service ID is required", http.StatusBadRequest)
-		return nil
+	// registering an existing service updates it the same way an upsert would
+	return a.upsertService(w, (*UpsertServiceRequest)(&registerServiceRequest))
+}
+
+// upsertService merges the request over the existing service, or the defaults if there
+// isn't one, and only writes it if the merged config is valid
+func (a *AutoscaleService) upsertService(w http.ResponseWriter, req *UpsertServiceRequest) error {
+	if req.ServiceId == nil || *req.ServiceId == "" {
+		return writeValidationErrors(w, "invalid service config", []FieldError{
+			{Field: "/service_id", Message: "is required"},
+		})
 	}
 
-	_, err = a.Queries.GetService(a.Context, *registerServiceRequest.ServiceId)
+	existingService, err := a.Queries.GetService(a.Context, *req.ServiceId)
 	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("error fetching service from database: %w", err)
 	}
 
-	if err == nil {
-		a.Logger.Info("service already exists, updating instead of registering", "service_id", registerServiceRequest.ServiceId)
-		return a.UpsertService(w, r)
+	exists := err == nil
+
+	base := defaultServiceConfig(*req.ServiceId)
+	if exists {
+		base = existingService
 	}
 
-	params := populateRegisterServiceParams(&registerServiceRequest)
-	params = applyDefaultsForCreate(params)
+	service := mergeServiceConfig(base, req)
+
+	fieldErrors := validateServiceConfig(service)
+	if exists {
+		if fieldError := a.validateEnvironmentChange(base, service); fieldError != nil {
+			fieldErrors = append(fieldErrors, *fieldError)
+		}
+	}
 
-	_, err = a.Queries.CreateService(a.Context, params)
+	if len(fieldErrors) > 0 {
+		return writeValidationErrors(w, "invalid service config", fieldErrors)
+	}
+
+	_, err = a.Queries.UpsertService(a.Context, upsertServiceParams(service))
 	if err != nil {
-		return fmt.Errorf("error creating service: %w", err)
+		return fmt.Errorf("error saving service: %w", err)
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -190,37 +145,71 @@ func (a *AutoscaleService) SetServiceEnabled(w http.ResponseWriter, r *http.Requ
 }
 
 func (a *AutoscaleService) ListServices(w http.ResponseWriter, r *http.Request) error {
-	project, err := a.GqlQueries.QueryProjectData()
+	projectIdFilter := r.URL.Query().Get("project_id")
+	environmentIdFilter := r.URL.Query().Get("environment_id")
+
+	registeredServices, err := a.Queries.ListServices(a.Context)
 	if err != nil {
-		return fmt.Errorf("error querying project data: %w", err)
+		return fmt.Errorf("error fetching services from database: %w", err)
 	}
 
-	currentEnvironmentName := ""
-	for _, environment := range project.Project.Environments.Edges {
-		if environment.Node.Id == a.Config.RailwayEnvironmentId {
-			currentEnvironmentName = environment.Node.Name
-			break
-		}
+	servicesById := make(map[string]repositories.Service, len(registeredServices))
+	for _, registeredService := range registeredServices {
+		servicesById[registeredService.ServiceID] = registeredService
+	}
+
+	overridesByServiceId, err := a.listActiveOverrides(time.Now())
+	if err != nil {
+		return err
+	}
+
+	projectIds := a.listProjectIds(registeredServices)
+	if projectIdFilter != "" {
+		projectIds = []string{projectIdFilter}
 	}
 
 	var serviceContexts []ServiceContext
+	var totalHourlyCost float64
 
-	for _, service := range project.Project.Services.Edges {
-		serviceContext := ServiceContext{}
+	for _, projectId := range projectIds {
+		project, err := a.GqlQueries.QueryProjectData(projectId)
+		if err != nil {
+			return fmt.Errorf("error querying project data: %w", err)
+		}
 
-		currentReplicas := a.getCurrentReplicas(project, service.Node.Id)
+		for _, service := range project.Project.Services.Edges {
+			dbService, isRegistered := servicesById[service.Node.Id]
 
-		serviceContext.ServiceName = service.Node.Name
-		serviceContext.ServiceId = service.Node.Id
-		serviceContext.ProjectId = project.Project.Id
-		serviceContext.EnvironmentId = a.Config.RailwayEnvironmentId
-		serviceContext.EnvironmentName = currentEnvironmentName
-		serviceContext.Replicas = currentReplicas
-		serviceContext.LastScaledAt = service.Node.ServiceInstances.Edges[0].Node.LatestDeployment.CreatedAt
+			// registered services are listed in the environment they're scaled in, the rest
+			// in the environment being filtered on
+			environmentId := environmentIdFilter
+			if isRegistered {
+				environmentId = a.serviceEnvironmentId(dbService)
+			} else if environmentId == "" {
+				environmentId = a.Config.RailwayEnvironmentId
+			}
 
-		dbService, err := a.Queries.GetService(a.Context, service.Node.Id)
-		if err != nil {
-			if err == sql.ErrNoRows {
+			if environmentIdFilter != "" && environmentId != environmentIdFilter {
+				continue
+			}
+
+			serviceInstance, ok := findServiceInstance(service.Node, environmentId)
+			if !ok {
+				continue
+			}
+
+			serviceContext := ServiceContext{}
+
+			serviceContext.ServiceName = service.Node.Name
+			serviceContext.ServiceId = service.Node.Id
+			serviceContext.ProjectId = project.Project.Id
+			serviceContext.EnvironmentId = environmentId
+			serviceContext.EnvironmentName = environmentName(project, environmentId)
+			serviceContext.LastScaledAt = serviceInstance.LatestDeployment.CreatedAt
+
+			var regions []serviceRegion
+
+			if !isRegistered {
 				serviceContext.Enabled = false
 
 				serviceContext.MinReplicas = a.Config.MinReplicaCount
@@ -231,33 +220,410 @@ func (a *AutoscaleService) ListServices(w http.ResponseWriter, r *http.Request)
 				serviceContext.MemoryDownscaleThreshold = a.Config.RailwayMemoryDownscaleThreshold * 100
 				serviceContext.UpscaleCooldown = a.Config.UpscaleCooldown.String()
 				serviceContext.DownscaleCooldown = a.Config.DownscaleCooldown.String()
+				serviceContext.Mode = defaultMode
+				serviceContext.Policy = defaultPolicy
+				serviceContext.TargetCpuUtilization = defaultTargetCpuUtilization
+				serviceContext.TargetMemoryUtilization = defaultTargetMemoryUtilization
+				serviceContext.MaxScaleUpStep = defaultMaxScaleUpStep
+				serviceContext.MaxScaleDownStep = defaultMaxScaleDownStep
+				serviceContext.ScaleDownStabilization = defaultScaleDownStabilization
+				serviceContext.SeasonalPeriod = defaultSeasonalPeriod
+				serviceContext.PredictiveLeadTime = defaultPredictiveLeadTime
+
+				regions = []serviceRegion{{
+					MinReplicaCount: int32(a.Config.MinReplicaCount),
+					MaxReplicaCount: int32(a.Config.MaxReplicaCount),
+				}}
 			} else {
-				return fmt.Errorf("error fetching service from database: %w", err)
-			}
-		} else {
-			if dbService.JobName.Valid && dbService.JobName.String != "" {
-				serviceContext.JobName = dbService.JobName.String
+				if dbService.JobName.Valid && dbService.JobName.String != "" {
+					serviceContext.JobName = dbService.JobName.String
+				}
+
+				serviceContext.MinReplicas = int(dbService.MinReplicaCount)
+				serviceContext.MaxReplicas = int(dbService.MaxReplicaCount)
+				serviceContext.CpuUpscaleThreshold = dbService.RailwayCpuUpscaleThreshold * 100
+				serviceContext.MemoryUpscaleThreshold = dbService.RailwayMemoryUpscaleThreshold * 100
+				serviceContext.CpuDownscaleThreshold = dbService.RailwayCpuDownscaleThreshold * 100
+				serviceContext.MemoryDownscaleThreshold = dbService.RailwayMemoryDownscaleThreshold * 100
+				serviceContext.UpscaleCooldown = dbService.UpscaleCooldown
+				serviceContext.DownscaleCooldown = dbService.DownscaleCooldown
+				serviceContext.Mode = dbService.Mode
+				serviceContext.Policy = dbService.Policy
+				serviceContext.TargetCpuUtilization = dbService.TargetCpuUtilization
+				serviceContext.TargetMemoryUtilization = dbService.TargetMemoryUtilization
+				serviceContext.MaxScaleUpStep = int(dbService.MaxScaleUpStep)
+				serviceContext.MaxScaleDownStep = int(dbService.MaxScaleDownStep)
+				serviceContext.ScaleDownStabilization = dbService.ScaleDownStabilization
+				serviceContext.SeasonalPeriod = dbService.SeasonalPeriod
+				serviceContext.PredictiveLeadTime = dbService.PredictiveLeadTime
+				serviceContext.ReplicaGroup = dbService.ReplicaGroup
+				serviceContext.Priority = int(dbService.Priority)
+				serviceContext.ReplicaHourlyCost = dbService.ReplicaHourlyCost
+
+				serviceContext.Enabled = dbService.Enabled
+				serviceContext.Override = overridesByServiceId[dbService.ServiceID]
+
+				regions, err = a.listServiceRegions(dbService)
+				if err != nil {
+					return err
+				}
 			}
 
-			serviceContext.MinReplicas = int(dbService.MinReplicaCount)
-			serviceContext.MaxReplicas = int(dbService.MaxReplicaCount)
-			serviceContext.CpuUpscaleThreshold = dbService.RailwayCpuUpscaleThreshold * 100
-			serviceContext.MemoryUpscaleThreshold = dbService.RailwayMemoryUpscaleThreshold * 100
-			serviceContext.CpuDownscaleThreshold = dbService.RailwayCpuDownscaleThreshold * 100
-			serviceContext.MemoryDownscaleThreshold = dbService.RailwayMemoryDownscaleThreshold * 100
-			serviceContext.UpscaleCooldown = dbService.UpscaleCooldown
-			serviceContext.DownscaleCooldown = dbService.DownscaleCooldown
+			multiRegionConfig := a.getMultiRegionConfig(project, service.Node.Id, environmentId)
 
-			serviceContext.Enabled = dbService.Enabled
-		}
+			serviceContext.Replicas = a.getCurrentReplicas(multiRegionConfig, regions)
+			serviceContext.Regions = a.regionContexts(multiRegionConfig, regions)
 
-		serviceContexts = append(serviceContexts, serviceContext)
+			// unregistered services have the zero value, so they're priced at REPLICA_HOURLY_COST
+			hourlyCost := float64(totalReplicas(multiRegionConfig)) * a.replicaHourlyCost(dbService)
+			serviceContext.EstimatedHourlyCost = roundCost(hourlyCost)
+			totalHourlyCost += hourlyCost
+
+			serviceContexts = append(serviceContexts, serviceContext)
+		}
 	}
 
 	response := ListServicesResponse{
-		Services: serviceContexts,
+		Services:            serviceContexts,
+		EstimatedHourlyCost: roundCost(totalHourlyCost),
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+func (a *AutoscaleService) ListServiceSchedules(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	schedules, err := a.Queries.ListServiceSchedulesByServiceID(a.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error fetching service schedules: %w", err)
+	}
+
+	now := time.Now()
+	response := ListServiceSchedulesResponse{
+		Schedules: []ServiceScheduleResponse{},
+	}
+
+	for _, schedule := range schedules {
+		response.Schedules = append(response.Schedules, toServiceScheduleResponse(schedule, now))
+	}
+
+	return writeJSON(w, response)
+}
+
+func (a *AutoscaleService) CreateServiceSchedule(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	scheduleRequest, ok := parseServiceScheduleRequest(w, r)
+	if !ok {
+		return nil
+	}
+
+	_, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	now := time.Now()
+
+	schedule, err := a.Queries.CreateServiceSchedule(a.Context, repositories.CreateServiceScheduleParams{
+		ServiceID:          serviceId,
+		Name:               scheduleRequest.Name,
+		CronExpression:     scheduleRequest.CronExpression,
+		Duration:           scheduleRequest.Duration,
+		Timezone:           scheduleRequest.Timezone,
+		MinReplicaCount:    toNullInt32(scheduleRequest.MinReplicaCount),
+		MaxReplicaCount:    toNullInt32(scheduleRequest.MaxReplicaCount),
+		PinnedReplicaCount: toNullInt32(scheduleRequest.PinnedReplicaCount),
+		Enabled:            scheduleRequest.Enabled == nil || *scheduleRequest.Enabled,
+		CreatedAt:          now.Unix(),
+		UpdatedAt:          now.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating service schedule: %w", err)
+	}
+
+	return writeJSON(w, toServiceScheduleResponse(schedule, now))
+}
+
+func (a *AutoscaleService) UpdateServiceSchedule(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	scheduleId, err := strconv.ParseInt(chi.URLParam(r, "scheduleId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Schedule ID must be a number", http.StatusBadRequest)
+		return nil
+	}
+
+	scheduleRequest, ok := parseServiceScheduleRequest(w, r)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	schedule, err := a.Queries.UpdateServiceSchedule(a.Context, repositories.UpdateServiceScheduleParams{
+		ID:                 scheduleId,
+		ServiceID:          serviceId,
+		Name:               scheduleRequest.Name,
+		CronExpression:     scheduleRequest.CronExpression,
+		Duration:           scheduleRequest.Duration,
+		Timezone:           scheduleRequest.Timezone,
+		MinReplicaCount:    toNullInt32(scheduleRequest.MinReplicaCount),
+		MaxReplicaCount:    toNullInt32(scheduleRequest.MaxReplicaCount),
+		PinnedReplicaCount: toNullInt32(scheduleRequest.PinnedReplicaCount),
+		Enabled:            scheduleRequest.Enabled == nil || *scheduleRequest.Enabled,
+		UpdatedAt:          now.Unix(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Schedule not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error updating service schedule: %w", err)
+	}
+
+	return writeJSON(w, toServiceScheduleResponse(schedule, now))
+}
+
+func (a *AutoscaleService) DeleteServiceSchedule(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	scheduleId, err := strconv.ParseInt(chi.URLParam(r, "scheduleId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Schedule ID must be a number", http.StatusBadRequest)
+		return nil
+	}
+
+	deleted, err := a.Queries.DeleteServiceSchedule(a.Context, repositories.DeleteServiceScheduleParams{
+		ID:        scheduleId,
+		ServiceID: serviceId,
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting service schedule: %w", err)
+	}
+
+	if deleted == 0 {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *AutoscaleService) ListServiceMetrics(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	serviceMetrics, err := a.Queries.ListServiceMetricsByServiceID(a.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error fetching custom metrics: %w", err)
+	}
+
+	if serviceMetrics == nil {
+		serviceMetrics = []repositories.ServiceMetric{}
+	}
+
+	return writeJSON(w, ListServiceMetricsResponse{Metrics: serviceMetrics})
+}
+
+func (a *AutoscaleService) UpsertServiceMetric(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+	name := chi.URLParam(r, "name")
+
+	var metricRequest ServiceMetricRequest
+	if err := json.NewDecoder(r.Body).Decode(&metricRequest); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return nil
+	}
+
+	if _, ok := a.MetricProviders[metricRequest.Provider]; !ok {
+		http.Error(w, "Provider must be one of the configured metric providers", http.StatusBadRequest)
+		return nil
+	}
+	if metricRequest.Query == "" {
+		http.Error(w, "Query is required", http.StatusBadRequest)
+		return nil
+	}
+	if metricRequest.TargetValue <= 0 {
+		http.Error(w, "Target value must be positive", http.StatusBadRequest)
+		return nil
+	}
+
+	_, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	now := time.Now().Unix()
+
+	serviceMetric, err := a.Queries.UpsertServiceMetric(a.Context, repositories.UpsertServiceMetricParams{
+		ServiceID:   serviceId,
+		Name:        name,
+		Provider:    metricRequest.Provider,
+		Query:       metricRequest.Query,
+		TargetValue: metricRequest.TargetValue,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting custom metric: %w", err)
+	}
+
+	return writeJSON(w, serviceMetric)
+}
+
+func (a *AutoscaleService) DeleteServiceMetric(w http.ResponseWriter, r *http.Request) error {
+	deleted, err := a.Queries.DeleteServiceMetric(a.Context, repositories.DeleteServiceMetricParams{
+		ServiceID: chi.URLParam(r, "id"),
+		Name:      chi.URLParam(r, "name"),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting custom metric: %w", err)
 	}
 
+	if deleted == 0 {
+		http.Error(w, "Metric not found", http.StatusNotFound)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (a *AutoscaleService) ListServiceRegions(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	serviceRegions, err := a.Queries.ListServiceRegionsByServiceID(a.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error fetching service regions: %w", err)
+	}
+
+	if serviceRegions == nil {
+		serviceRegions = []repositories.ServiceRegion{}
+	}
+
+	return writeJSON(w, ListServiceRegionsResponse{Regions: serviceRegions})
+}
+
+func (a *AutoscaleService) UpsertServiceRegion(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+	region := chi.URLParam(r, "region")
+
+	var regionRequest ServiceRegionRequest
+	if err := json.NewDecoder(r.Body).Decode(&regionRequest); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return nil
+	}
+
+	if message := validateServiceRegionRequest(&regionRequest); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return nil
+	}
+
+	_, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	now := time.Now().Unix()
+
+	serviceRegion, err := a.Queries.UpsertServiceRegion(a.Context, repositories.UpsertServiceRegionParams{
+		ServiceID:       serviceId,
+		Region:          region,
+		MinReplicaCount: int32(*regionRequest.MinReplicaCount),
+		MaxReplicaCount: int32(*regionRequest.MaxReplicaCount),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting service region: %w", err)
+	}
+
+	return writeJSON(w, serviceRegion)
+}
+
+func (a *AutoscaleService) DeleteServiceRegion(w http.ResponseWriter, r *http.Request) error {
+	deleted, err := a.Queries.DeleteServiceRegion(a.Context, repositories.DeleteServiceRegionParams{
+		ServiceID: chi.URLParam(r, "id"),
+		Region:    chi.URLParam(r, "region"),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting service region: %w", err)
+	}
+
+	if deleted == 0 {
+		http.Error(w, "Region not found", http.StatusNotFound)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func parseServiceScheduleRequest(w http.ResponseWriter, r *http.Request) (ServiceScheduleRequest, bool) {
+	var scheduleRequest ServiceScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&scheduleRequest); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return ServiceScheduleRequest{}, false
+	}
+
+	if message := validateServiceScheduleRequest(&scheduleRequest); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return ServiceScheduleRequest{}, false
+	}
+
+	return scheduleRequest, true
+}
+
+func toServiceScheduleResponse(schedule repositories.ServiceSchedule, now time.Time) ServiceScheduleResponse {
+	response := ServiceScheduleResponse{
+		ID:                 schedule.ID,
+		ServiceId:          schedule.ServiceID,
+		Name:               schedule.Name,
+		CronExpression:     schedule.CronExpression,
+		Duration:           schedule.Duration,
+		Timezone:           schedule.Timezone,
+		MinReplicaCount:    fromNullInt32(schedule.MinReplicaCount),
+		MaxReplicaCount:    fromNullInt32(schedule.MaxReplicaCount),
+		PinnedReplicaCount: fromNullInt32(schedule.PinnedReplicaCount),
+		Enabled:            schedule.Enabled,
+		CreatedAt:          schedule.CreatedAt,
+		UpdatedAt:          schedule.UpdatedAt,
+	}
+
+	// schedules are validated on the way in, so this only fails if the timezone database changed
+	if parsed, err := parseServiceSchedule(schedule.CronExpression, schedule.Duration, schedule.Timezone); err == nil {
+		response.Active = schedule.Enabled && parsed.isActive(now)
+		response.NextStart = parsed.nextStart(now).Unix()
+	}
+
+	return response
+}
+
+func writeJSON(w http.ResponseWriter, response any) error {
 	responseBytes, err := json.Marshal(response)
 	if err != nil {
 		return fmt.Errorf("error marshalling response: %w", err)
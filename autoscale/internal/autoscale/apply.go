@@ -0,0 +1,358 @@
+package autoscale
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	servicesFileFormatYaml = "yaml"
+	servicesFileFormatJson = "json"
+)
+
+// ExportServices writes every registered service as a services file, in YAML unless
+// ?format=json is given
+func (a *AutoscaleService) ExportServices(w http.ResponseWriter, r *http.Request) error {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = servicesFileFormatYaml
+	}
+
+	if format != servicesFileFormatYaml && format != servicesFileFormatJson {
+		http.Error(w, "format must be one of yaml or json", http.StatusBadRequest)
+		return nil
+	}
+
+	services, err := a.Queries.ListServices(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching services from database: %w", err)
+	}
+
+	servicesFile := ServicesFile{
+		Services: make([]UpsertServiceRequest, 0, len(services)),
+	}
+
+	for _, service := range services {
+		servicesFile.Services = append(servicesFile.Services, toServiceSpec(service))
+	}
+
+	if format == servicesFileFormatJson {
+		return writeJSON(w, servicesFile)
+	}
+
+	responseBytes, err := yaml.Marshal(servicesFile)
+	if err != nil {
+		return fmt.Errorf("error marshalling services file: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+// ApplyServices makes the services table match a services file. The file is the whole
+// config, so a field left out of a service is set back to its default. Services missing
+// from the file are only deleted with ?prune=true, and ?dry_run=true reports the changes
+// without making them. Nothing is changed unless every service in the file is valid
+func (a *AutoscaleService) ApplyServices(w http.ResponseWriter, r *http.Request) error {
+	dryRun, err := parseBoolQuery(r, "dry_run")
+	if err != nil {
+		http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+		return nil
+	}
+
+	prune, err := parseBoolQuery(r, "prune")
+	if err != nil {
+		http.Error(w, "prune must be true or false", http.StatusBadRequest)
+		return nil
+	}
+
+	servicesFile, fieldError := parseServicesFile(r)
+	if fieldError != nil {
+		return writeValidationErrors(w, "invalid services file", []FieldError{*fieldError})
+	}
+
+	// an empty body shouldn't prune every service, an empty list has to be asked for
+	if servicesFile.Services == nil {
+		return writeValidationErrors(w, "invalid services file", []FieldError{
+			{Field: "/services", Message: "is required"},
+		})
+	}
+
+	existingServices, err := a.Queries.ListServices(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching services from database: %w", err)
+	}
+
+	existingById := make(map[string]repositories.Service, len(existingServices))
+	for _, existingService := range existingServices {
+		existingById[existingService.ServiceID] = existingService
+	}
+
+	fieldErrors := []FieldError{}
+	desiredById := map[string]repositories.Service{}
+
+	var creates, updates []repositories.Service
+
+	response := ApplyServicesResponse{
+		DryRun:  dryRun,
+		Prune:   prune,
+		Creates: []ServiceChange{},
+		Updates: []ServiceChange{},
+		Deletes: []ServiceChange{},
+	}
+
+	for i, spec := range servicesFile.Services {
+		path := fmt.Sprintf("/services/%d", i)
+
+		if spec.ServiceId == nil || *spec.ServiceId == "" {
+			fieldErrors = append(fieldErrors, FieldError{Field: path + "/service_id", Message: "is required"})
+			continue
+		}
+
+		serviceId := *spec.ServiceId
+
+		if _, ok := desiredById[serviceId]; ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: path + "/service_id", Message: fmt.Sprintf("%s is listed more than once", serviceId)})
+			continue
+		}
+
+		desired := mergeServiceConfig(defaultServiceConfig(serviceId), &spec)
+		desiredById[serviceId] = desired
+
+		for _, serviceFieldError := range validateServiceConfig(desired) {
+			serviceFieldError.Field = path + serviceFieldError.Field
+			fieldErrors = append(fieldErrors, serviceFieldError)
+		}
+
+		existing, exists := existingById[serviceId]
+		if !exists {
+			creates = append(creates, desired)
+			response.Creates = append(response.Creates, ServiceChange{ServiceId: serviceId, Fields: diffServiceConfig(nil, desired)})
+			continue
+		}
+
+		if fieldError := a.validateEnvironmentChange(existing, desired); fieldError != nil {
+			fieldError.Field = path + fieldError.Field
+			fieldErrors = append(fieldErrors, *fieldError)
+			continue
+		}
+
+		fieldChanges := diffServiceConfig(&existing, desired)
+		if len(fieldChanges) == 0 {
+			response.Unchanged++
+			continue
+		}
+
+		updates = append(updates, desired)
+		response.Updates = append(response.Updates, ServiceChange{ServiceId: serviceId, Fields: fieldChanges})
+	}
+
+	if len(fieldErrors) > 0 {
+		return writeValidationErrors(w, "invalid services file", fieldErrors)
+	}
+
+	var deletes []string
+
+	if prune {
+		for _, existingService := range existingServices {
+			if _, ok := desiredById[existingService.ServiceID]; ok {
+				continue
+			}
+
+			// the schedules, metrics, regions, overrides and history aren't in the file, so
+			// they're counted to show what deleting the service takes with it
+			cascades, err := a.Queries.CountServiceDependents(a.Context, existingService.ServiceID)
+			if err != nil {
+				return fmt.Errorf("error counting rows of service %s: %w", existingService.ServiceID, err)
+			}
+
+			deletes = append(deletes, existingService.ServiceID)
+			response.Deletes = append(response.Deletes, ServiceChange{ServiceId: existingService.ServiceID, Cascades: &cascades})
+		}
+	}
+
+	if dryRun {
+		return writeJSON(w, response)
+	}
+
+	if err := a.applyServiceChanges(creates, updates, deletes); err != nil {
+		return err
+	}
+
+	a.Logger.Info("applied services file",
+		"creates", len(creates),
+		"updates", len(updates),
+		"deletes", len(deletes),
+		"unchanged", response.Unchanged,
+	)
+
+	return writeJSON(w, response)
+}
+
+// applyServiceChanges makes every change in one transaction, so a failure part way through
+// doesn't leave the table half applied
+func (a *AutoscaleService) applyServiceChanges(creates []repositories.Service, updates []repositories.Service, deletes []string) error {
+	tx, err := a.DB.BeginTx(a.Context, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := repositories.New(tx)
+
+	for _, service := range slices.Concat(creates, updates) {
+		if _, err := queries.UpsertService(a.Context, upsertServiceParams(service)); err != nil {
+			return fmt.Errorf("error saving service %s: %w", service.ServiceID, err)
+		}
+	}
+
+	for _, serviceId := range deletes {
+		if err := queries.DeleteService(a.Context, serviceId); err != nil {
+			return fmt.Errorf("error deleting service %s: %w", serviceId, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing services file: %w", err)
+	}
+
+	return nil
+}
+
+// parseServicesFile reads a JSON body when the content type says so, and YAML otherwise.
+// Unknown fields are rejected, since a misspelled one would otherwise be reset to its default
+func parseServicesFile(r *http.Request) (ServicesFile, *FieldError) {
+	var servicesFile ServicesFile
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&servicesFile); err != nil {
+			fieldError := requestFieldError(err)
+			return ServicesFile{}, &fieldError
+		}
+
+		return servicesFile, nil
+	}
+
+	decoder := yaml.NewDecoder(r.Body)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&servicesFile); err != nil && err != io.EOF {
+		return ServicesFile{}, &FieldError{Field: "", Message: err.Error()}
+	}
+
+	return servicesFile, nil
+}
+
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// toServiceSpec sets every field, so an exported file applies back without changes
+func toServiceSpec(service repositories.Service) UpsertServiceRequest {
+	spec := UpsertServiceRequest{
+		ServiceId:                       &service.ServiceID,
+		Enabled:                         &service.Enabled,
+		RailwayMemoryUpscaleThreshold:   &service.RailwayMemoryUpscaleThreshold,
+		RailwayCPUUpscaleThreshold:      &service.RailwayCpuUpscaleThreshold,
+		RailwayMemoryDownscaleThreshold: &service.RailwayMemoryDownscaleThreshold,
+		RailwayCPUDownscaleThreshold:    &service.RailwayCpuDownscaleThreshold,
+		UpscaleCooldown:                 &service.UpscaleCooldown,
+		DownscaleCooldown:               &service.DownscaleCooldown,
+		MinReplicaCount:                 intPointer(service.MinReplicaCount),
+		MaxReplicaCount:                 intPointer(service.MaxReplicaCount),
+		Mode:                            &service.Mode,
+		ProjectId:                       &service.ProjectID,
+		EnvironmentId:                   &service.EnvironmentID,
+		ReplicaGroup:                    &service.ReplicaGroup,
+		Priority:                        intPointer(service.Priority),
+		ReplicaHourlyCost:               &service.ReplicaHourlyCost,
+		ScalingPolicyRequest: ScalingPolicyRequest{
+			Policy:                  &service.Policy,
+			TargetCpuUtilization:    &service.TargetCpuUtilization,
+			TargetMemoryUtilization: &service.TargetMemoryUtilization,
+			MaxScaleUpStep:          intPointer(service.MaxScaleUpStep),
+			MaxScaleDownStep:        intPointer(service.MaxScaleDownStep),
+			ScaleDownStabilization:  &service.ScaleDownStabilization,
+			SeasonalPeriod:          &service.SeasonalPeriod,
+			PredictiveLeadTime:      &service.PredictiveLeadTime,
+		},
+	}
+
+	if service.JobName.Valid {
+		spec.JobName = &service.JobName.String
+	}
+
+	return spec
+}
+
+func intPointer(value int32) *int {
+	v := int(value)
+	return &v
+}
+
+// diffServiceConfig compares every column of the two configs, named by their json tags,
+// which match the columns. from is nil for a service that doesn't exist yet
+func diffServiceConfig(from *repositories.Service, to repositories.Service) []FieldChange {
+	fieldChanges := []FieldChange{}
+
+	toValue := reflect.ValueOf(to)
+
+	for i := range toValue.NumField() {
+		field := toValue.Type().Field(i)
+		if field.Name == "ServiceID" {
+			continue
+		}
+
+		fieldChange := FieldChange{
+			Field: field.Tag.Get("json"),
+			To:    diffValue(toValue.Field(i).Interface()),
+		}
+
+		if from != nil {
+			fromField := reflect.ValueOf(*from).Field(i).Interface()
+			if reflect.DeepEqual(fromField, toValue.Field(i).Interface()) {
+				continue
+			}
+
+			fieldChange.From = diffValue(fromField)
+		}
+
+		fieldChanges = append(fieldChanges, fieldChange)
+	}
+
+	return fieldChanges
+}
+
+// diffValue reports a missing job name as null rather than sql.NullString's struct
+func diffValue(value any) any {
+	if nullString, ok := value.(sql.NullString); ok {
+		if !nullString.Valid {
+			return nil
+		}
+		return nullString.String
+	}
+
+	return value
+}
@@ -0,0 +1,85 @@
+package autoscale
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+)
+
+func TestDiffServiceConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(config *repositories.Service)
+		want   []FieldChange
+	}{
+		{name: "unchanged", change: func(config *repositories.Service) {}, want: []FieldChange{}},
+		{
+			name:   "one field",
+			change: func(config *repositories.Service) { config.MaxReplicaCount = 20 },
+			want:   []FieldChange{{Field: "max_replica_count", From: int32(10), To: int32(20)}},
+		},
+		{
+			name: "fields in column order",
+			change: func(config *repositories.Service) {
+				config.ReplicaHourlyCost = 0.5
+				config.Enabled = false
+			},
+			want: []FieldChange{
+				{Field: "enabled", From: true, To: false},
+				{Field: "replica_hourly_cost", From: float64(0), To: 0.5},
+			},
+		},
+		{
+			name:   "job name set",
+			change: func(config *repositories.Service) { config.JobName = sql.NullString{String: "nightly", Valid: true} },
+			want:   []FieldChange{{Field: "job_name", From: nil, To: "nightly"}},
+		},
+		{
+			name:   "service id isn't a change",
+			change: func(config *repositories.Service) { config.ServiceID = "other" },
+			want:   []FieldChange{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from := defaultServiceConfig("svc")
+			to := defaultServiceConfig("svc")
+			test.change(&to)
+
+			if got := diffServiceConfig(&from, to); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffServiceConfigCreate(t *testing.T) {
+	fieldChanges := diffServiceConfig(nil, defaultServiceConfig("svc"))
+
+	// every column but the service id, which the change is keyed by
+	if want := reflect.TypeFor[repositories.Service]().NumField() - 1; len(fieldChanges) != want {
+		t.Errorf("got %d fields, want %d", len(fieldChanges), want)
+	}
+
+	for _, fieldChange := range fieldChanges {
+		if fieldChange.From != nil {
+			t.Errorf("%s: got from %v for a created service, want nil", fieldChange.Field, fieldChange.From)
+		}
+
+		switch fieldChange.Field {
+		case "service_id":
+			t.Error("service_id is listed as a field")
+		case "job_name":
+			if fieldChange.To != nil {
+				t.Errorf("got job_name %v, want null", fieldChange.To)
+			}
+		case "max_replica_count":
+			if fieldChange.To != int32(10) {
+				t.Errorf("got max_replica_count %v, want 10", fieldChange.To)
+			}
+		}
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/ferretcode/switchyard/autoscale/internal/metrics"
 	"github.com/ferretcode/switchyard/autoscale/internal/railway"
 	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
 	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/internal/webhook"
 	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/jmoiron/sqlx"
 )
 
 const (
@@ -17,170 +21,493 @@ const (
 	spikeWindow    = 3
 )
 
-var (
-	lastUpscaleTime     time.Time
-	lastDownscaleTime   time.Time
-	consecutiveHighLoad int
-	consecutiveLowLoad  int
-)
-
 type AutoscaleService struct {
-	Logger              *slog.Logger
-	Config              *types.Config
-	GqlQueries          *railway.QueryService
-	Queries             *repositories.Queries
-	Context             context.Context
-	ServiceHistoryCache *types.ServiceHistoryCache
+	Logger            *slog.Logger
+	Config            *types.Config
+	GqlQueries        *railway.QueryService
+	Queries           repositories.Querier
+	DB                *sqlx.DB
+	Context           context.Context
+	ServiceStateCache *types.ServiceStateCache
+	RailwayMetrics    *metrics.RailwayProvider
+	MetricProviders   map[string]metrics.Provider
+	PrometheusMetrics *types.PrometheusMetrics
+	WebhookService    *webhook.WebhookService
+	// reportedRegions are the regions each service has exported gauges for, and evaluatedRegions
+	// the ones reported so far this tick
+	reportedRegions  map[string]map[string]bool
+	evaluatedRegions map[string]map[string]bool
+	regionsMutex     sync.Mutex
+	// replicaBudgetWaiting carries the priorities the replica budget turned away into the next tick
+	replicaBudgetWaiting map[string]int32
 }
 
-func NewAutoscaleService(logger *slog.Logger, config *types.Config, gqlQueries *railway.QueryService, queries *repositories.Queries, context context.Context, serviceHistoryCache *types.ServiceHistoryCache) AutoscaleService {
+func NewAutoscaleService(logger *slog.Logger, config *types.Config, gqlQueries *railway.QueryService, queries repositories.Querier, db *sqlx.DB, context context.Context, serviceStateCache *types.ServiceStateCache, railwayMetrics *metrics.RailwayProvider, metricProviders map[string]metrics.Provider, prometheusMetrics *types.PrometheusMetrics, webhookService *webhook.WebhookService) AutoscaleService {
 	return AutoscaleService{
-		Logger:              logger,
-		Config:              config,
-		GqlQueries:          gqlQueries,
-		Queries:             queries,
-		Context:             context,
-		ServiceHistoryCache: serviceHistoryCache,
+		Logger:            logger,
+		Config:            config,
+		GqlQueries:        gqlQueries,
+		Queries:           queries,
+		DB:                db,
+		Context:           context,
+		ServiceStateCache: serviceStateCache,
+		RailwayMetrics:    railwayMetrics,
+		MetricProviders:   metricProviders,
+		PrometheusMetrics: prometheusMetrics,
+		WebhookService:    webhookService,
+		reportedRegions:   map[string]map[string]bool{},
+		evaluatedRegions:  map[string]map[string]bool{},
 	}
 }
 
-func (a *AutoscaleService) StartAutoscaling() {
+// StartAutoscaling evaluates services every MONITORING_INTERVAL until ctx is cancelled,
+// i.e. until this instance stops being the leader
+func (a *AutoscaleService) StartAutoscaling(ctx context.Context) {
 	monitoringTimer := time.NewTicker(a.Config.MonitoringInterval)
 	defer monitoringTimer.Stop()
+	defer a.resetServiceMetrics()
 
 	for {
-		<-monitoringTimer.C
-
-		registeredServices, err := a.Queries.ListServices(a.Context)
-		if err != nil {
-			a.Logger.Error("error fetching registered services", "err", err)
-			continue
+		select {
+		case <-ctx.Done():
+			return
+		case <-monitoringTimer.C:
 		}
 
-		project, err := a.GqlQueries.QueryProjectData()
-		if err != nil {
-			a.Logger.Error("error fetching project state", "err", err)
-		}
+		start := time.Now()
+
+		evaluated := a.evaluateServices(ctx)
 
-		validServices := getValidServiceIds(registeredServices, project.Project.Services.Edges)
+		elapsed := time.Since(start)
+		a.PrometheusMetrics.TickDuration.Observe(elapsed.Seconds())
 
-		for _, serviceId := range validServices {
-			a.processServiceId(serviceId, project)
+		// the ticker drops the ticks that fire while an evaluation is still running
+		if elapsed > a.Config.MonitoringInterval {
+			a.PrometheusMetrics.TickOverruns.Inc()
+
+			a.Logger.Warn("autoscaling tick overran the monitoring interval",
+				"elapsed", elapsed,
+				"monitoring-interval", a.Config.MonitoringInterval,
+				"skipped-ticks", int(elapsed/a.Config.MonitoringInterval),
+				"services", evaluated,
+			)
 		}
+	}
+}
+
+// evaluateServices processes every registered service on a pool of AUTOSCALE_WORKERS
+// workers and returns how many were evaluated
+func (a *AutoscaleService) evaluateServices(ctx context.Context) int {
+	registeredServices, err := a.Queries.ListServices(a.Context)
+	if err != nil {
+		a.Logger.Error("error fetching registered services", "err", err)
+		return 0
+	}
 
+	groupBudgets, err := a.Queries.ListReplicaBudgets(a.Context)
+	if err != nil {
+		a.Logger.Error("error fetching replica budgets", "err", err)
+		return 0
 	}
+
+	a.clearExpiredOverrides(time.Now())
+	a.clearOldMetricRollups(time.Now())
+
+	jobs := a.listServiceJobs(registeredServices)
+
+	budget := a.newReplicaBudget(groupBudgets, a.replicaBudgetWaiting)
+	a.countReplicas(budget, jobs)
+
+	a.runServiceJobs(jobs, func(job serviceJob) {
+		a.processServiceId(ctx, job.ValidService, job.Project, budget)
+	})
+
+	a.replicaBudgetWaiting = budget.denied
+
+	evaluated := map[string]bool{}
+	for _, job := range jobs {
+		evaluated[job.ValidService.ServiceId] = true
+	}
+
+	a.forgetServiceMetrics(evaluated)
+
+	return len(evaluated)
 }
 
-func (a *AutoscaleService) processServiceId(validService ValidService, project *gql.ProjectData) {
-	startDate := time.Now().Format(time.RFC3339)
+// runServiceJobs runs process for every job on a pool of AUTOSCALE_WORKERS workers. The jobs
+// are sorted by priority, and a priority only starts once every job above it is done, so a
+// lower priority service can't reserve replicas from the replica budget ahead of a higher one
+func (a *AutoscaleService) runServiceJobs(jobs []serviceJob, process func(job serviceJob)) {
+	workers := make(chan struct{}, max(a.Config.AutoscaleWorkers, 1))
+	wg := sync.WaitGroup{}
 
-	history, ok := a.ServiceHistoryCache.ServiceHistories[validService.ServiceId]
-	if !ok {
-		history = types.MetricHistory{
-			CPU:    make([]float64, 0, a.Config.MetricHistorySize),
-			Memory: make([]float64, 0, a.Config.MetricHistorySize),
-			Times:  make([]time.Time, 0, a.Config.MetricHistorySize),
+	for i, job := range jobs {
+		if i > 0 && job.ValidService.Service.Priority != jobs[i-1].ValidService.Service.Priority {
+			wg.Wait()
 		}
-		a.ServiceHistoryCache.ServiceHistories[validService.ServiceId] = history
+
+		workers <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			process(job)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// processServiceId gives up on fetching the service's metrics after SERVICE_EVALUATION_TIMEOUT,
+// so one slow service can't hold up the rest. Scaling isn't bound by the timeout, since
+// abandoning it between the update and the redeploy would leave the change half applied
+func (a *AutoscaleService) processServiceId(ctx context.Context, validService ValidService, project *gql.ProjectData, budget *replicaBudget) {
+	metricsCtx, cancel := context.WithTimeout(ctx, a.Config.ServiceEvaluationTimeout)
+	defer cancel()
+
+	regions, err := a.listServiceRegions(validService.Service)
+	if err != nil {
+		a.Logger.Error("error fetching service regions", "err", err, "service-id", validService.ServiceId)
+		return
 	}
 
-	metrics, err := a.GqlQueries.QueryServiceMetrics(validService.ServiceId, startDate)
+	cpuPercent, memPercent, err := a.RailwayMetrics.Utilization(metricsCtx, validService.ServiceId, validService.EnvironmentId)
 	if err != nil {
 		a.Logger.Error("error fetching service metrics", "err", err)
 		return
 	}
 
-	currentReplicas := a.getCurrentReplicas(project, validService.ServiceId)
+	// configured regions are scaled from their own metrics where Railway has them, and
+	// from the service's metrics otherwise
+	var regionUtilization map[string]metrics.Utilization
+	if regions[0].Name != "" {
+		regionUtilization, err = a.RailwayMetrics.RegionUtilization(metricsCtx, validService.ServiceId, validService.EnvironmentId)
+		if err != nil {
+			a.Logger.Error("error fetching region metrics", "err", err, "service-id", validService.ServiceId)
+		}
+	}
 
-	cpuPercent, memPercent := extractMetrics(metrics)
+	customMetrics := a.queryServiceMetrics(metricsCtx, validService.ServiceId, validService.EnvironmentId)
 
-	now := time.Now()
-	history.CPU = append(history.CPU, cpuPercent)
-	history.Memory = append(history.Memory, memPercent)
-	history.Times = append(history.Times, now)
-
-	if len(history.CPU) > a.Config.MetricHistorySize {
-		history.CPU = history.CPU[1:]
-		history.Memory = history.Memory[1:]
-		history.Times = history.Times[1:]
-	}
-
-	a.ServiceHistoryCache.ServiceHistories[validService.ServiceId] = history
-
-	avgCpu := calculateWeightedAverage(history.CPU)
-	avgMem := calculateWeightedAverage(history.Memory)
-
-	hasCpuSpike := detectSpike(history.CPU, spikeThreshold)
-	hasMemSpike := detectSpike(history.Memory, spikeThreshold)
-
-	cpuTrend := calculateTrend(history.CPU, history.Times)
-	memTrend := calculateTrend(history.Memory, history.Times)
-
-	scalingDecision, reason, err := a.makeScalingDecision(
-		types.ScalingContext{
-			CpuPercent:      cpuPercent,
-			MemPercent:      memPercent,
-			AvgCpu:          avgCpu,
-			AvgMem:          avgMem,
-			HasCpuSpike:     hasCpuSpike,
-			HasMemSpike:     hasMemSpike,
-			CpuTrend:        cpuTrend,
-			MemTrend:        memTrend,
-			CurrentReplicas: currentReplicas,
-			Now:             now,
-			Service:         validService.Service,
-		},
-	)
+	// every mutation sends back the whole region config, so it's kept up to date as regions are scaled
+	multiRegionConfig := a.getMultiRegionConfig(project, validService.ServiceId, validService.EnvironmentId)
+
+	deferral := a.scalingDeferral(project, validService, regions)
+
+	serviceOverride, err := a.getActiveOverride(validService.ServiceId, time.Now())
 	if err != nil {
-		a.Logger.Error("error making scaling decision", "err", err)
+		a.Logger.Error("error fetching service override", "err", err, "service-id", validService.ServiceId)
 		return
 	}
 
+	for _, region := range regions {
+		utilization, ok := regionUtilization[region.Name]
+		if !ok {
+			utilization = metrics.Utilization{Cpu: cpuPercent, Memory: memPercent}
+		}
+
+		a.processServiceRegion(ctx, validService, region, utilization, customMetrics, multiRegionConfig, deferral, serviceOverride, budget)
+	}
+}
+
+// processServiceRegion only records the region's metrics when deferral is set. An active
+// override pins every managed region at its replica count, in place of the schedules and policy
+func (a *AutoscaleService) processServiceRegion(ctx context.Context, validService ValidService, region serviceRegion, utilization metrics.Utilization, customMetrics []types.MetricSample, multiRegionConfig map[string]gql.RegionConfig, deferral string, serviceOverride *repositories.ServiceOverride, budget *replicaBudget) {
+	state := a.getServiceState(validService.ServiceId, region.Name)
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	cpuPercent, memPercent := utilization.Cpu, utilization.Memory
+
+	railwayRegion := a.railwayRegion(region.Name)
+	currentReplicas := multiRegionConfig[railwayRegion].NumReplicas
+
+	now := time.Now()
+	a.appendMetricSample(state, cpuPercent, memPercent, now)
+
+	// saved once the decision below has updated the cooldowns and counters
+	defer func() {
+		if err := a.saveServiceState(validService.ServiceId, region.Name, state, cpuPercent, memPercent, currentReplicas, now); err != nil {
+			a.Logger.Error("error saving service state", "err", err, "service-id", validService.ServiceId)
+		}
+	}()
+
+	if deferral != "" {
+		a.Logger.Info("deferring scaling", "service-id", validService.ServiceId, "region", railwayRegion, "reason", deferral)
+		return
+	}
+
+	validService.Service.MinReplicaCount = region.MinReplicaCount
+	validService.Service.MaxReplicaCount = region.MaxReplicaCount
+
+	var scalingDecision int
+	var reason string
+	var err error
+
+	if serviceOverride != nil {
+		scalingDecision = int(serviceOverride.ReplicaCount) - currentReplicas
+		reason = overrideReason(serviceOverride)
+	} else {
+		// schedules run before the reactive policy and can narrow the bounds it works within
+		var service repositories.Service
+
+		service, scalingDecision, reason, err = a.applyServiceSchedules(validService.Service, currentReplicas, now)
+		if err != nil {
+			a.Logger.Error("error applying service schedules", "err", err, "service-id", validService.ServiceId)
+		}
+
+		validService.Service = service
+	}
+
+	scalingContext := newScalingContext(state, cpuPercent, memPercent, currentReplicas, now, validService.Service)
+	scalingContext.Region = railwayRegion
+	scalingContext.Metrics = customMetrics
+
+	if reason == "" {
+		if validService.Service.Policy == policyPredictive {
+			scalingContext.Forecast, err = a.forecastPeak(validService.Service, region.Name, now)
+			if err != nil {
+				a.Logger.Error("error forecasting service utilization", "err", err, "service-id", validService.ServiceId)
+			}
+		}
+
+		scalingDecision, reason, err = a.decide(scalingContext, state)
+		if err != nil {
+			a.Logger.Error("error making scaling decision", "err", err)
+			return
+		}
+	}
+
+	event := ScalingEvent{
+		Region:      railwayRegion,
+		OldReplicas: currentReplicas,
+		NewReplicas: currentReplicas,
+		Reason:      reason,
+		Status:      scalingEventStatusNoOp,
+		Context:     scalingContext,
+	}
+
 	if scalingDecision != 0 {
 		newReplicas := currentReplicas + scalingDecision
-		if newReplicas >= int(validService.Service.MinReplicaCount) && newReplicas <= int(validService.Service.MaxReplicaCount) {
+
+		// overrides are allowed outside of the bounds, that's usually the point of them. Any
+		// other decision that overshoots them goes to the nearest bound instead
+		if serviceOverride == nil {
+			boundedReplicas := min(max(newReplicas, int(validService.Service.MinReplicaCount)), int(validService.Service.MaxReplicaCount))
+
+			if boundedReplicas != newReplicas {
+				event.Message = fmt.Sprintf("%d replicas is outside of %d-%d", newReplicas, validService.Service.MinReplicaCount, validService.Service.MaxReplicaCount)
+				newReplicas = boundedReplicas
+			}
+		}
+
+		step := newReplicas - currentReplicas
+		event.NewReplicas = newReplicas
+
+		switch {
+		case step == 0:
+			event.Status = scalingEventStatusSkipped
+		case validService.Service.Mode == serviceModeShadow:
+			a.Logger.Info("shadow scaling decision reached",
+				"region", railwayRegion,
+				"current_replicas", currentReplicas,
+				"new_replicas", newReplicas,
+				"reason", reason,
+			)
+
+			event.Status = scalingEventStatusShadow
+		case ctx.Err() != nil:
+			// the new leader evaluates the service again
+			event.Status = scalingEventStatusSkipped
+			event.Message = "lost autoscaler leadership before scaling"
+		default:
+			// overrides count against the replica budget, but aren't held back by it
+			granted := step
+			if step > 0 && serviceOverride == nil {
+				var budgetMessage string
+
+				granted, budgetMessage = budget.reserve(validService.Service, step)
+				if budgetMessage != "" {
+					event.Message = budgetMessage
+				}
+			} else if step > 0 {
+				budget.add(validService.Service, step)
+			}
+
+			if granted == 0 {
+				event.Status = scalingEventStatusSkipped
+				break
+			}
+
+			newReplicas = currentReplicas + granted
+			event.NewReplicas = newReplicas
+
 			a.Logger.Info("scaling decision reached",
+				"region", railwayRegion,
 				"current_replicas", currentReplicas,
 				"new_replicas", newReplicas,
 				"reason", reason,
 			)
 
-			err = a.GqlQueries.MutationUpdateReplicas(a.Config.RailwayEnvironmentId, validService.ServiceId, a.Config.RailwaySelectedRegion, newReplicas)
-			if err != nil {
+			// applied once verifyScaling sees the new replicas go live
+			event.Status = scalingEventStatusPending
+
+			if err := a.scaleService(validService.ServiceId, validService.EnvironmentId, multiRegionConfig, railwayRegion, newReplicas); err != nil {
 				a.Logger.Error("error scaling service", "err", err)
-			}
 
-			err = a.GqlQueries.MutationServiceInstanceRedeploy(a.Config.RailwayEnvironmentId, validService.ServiceId)
-			if err != nil {
-				a.Logger.Error("error redeploying scaled service", "err", err)
+				event.Status = scalingEventStatusFailed
+				event.Message = err.Error()
+
+				if granted > 0 {
+					budget.add(validService.Service, -granted)
+				}
+			} else if granted < 0 {
+				// scale downs only free up replicas once railway has taken them
+				budget.add(validService.Service, granted)
 			}
 		}
 	}
 
+	if scalingDecision != 0 {
+		a.recordScalingAction(validService.ServiceId, railwayRegion, reason, event.Status, serviceOverride != nil)
+	}
+
+	notification := types.ScalingNotification{
+		ServiceId:     validService.ServiceId,
+		EnvironmentId: validService.EnvironmentId,
+		Region:        railwayRegion,
+		OldReplicas:   currentReplicas,
+		NewReplicas:   event.NewReplicas,
+		MaxReplicas:   int(validService.Service.MaxReplicaCount),
+		Reason:        reason,
+		Message:       event.Message,
+		CpuPercent:    cpuPercent,
+		MemPercent:    memPercent,
+	}
+
+	// shadow services never scale, and overrides pin the replica count on purpose
+	if validService.Service.Mode != serviceModeShadow && serviceOverride == nil {
+		a.notifyCapacityBound(state, notification, scalingDecision)
+	}
+
+	if event.Status == scalingEventStatusFailed {
+		notification.Type = notificationScalingFailed
+		a.notify(notification)
+	}
+
+	// shadow services record every evaluation so their report can line up against the actual replica count
+	if event.Status != scalingEventStatusNoOp || a.Config.RecordNoOpScalingEvents || validService.Service.Mode == serviceModeShadow {
+		scalingEvent, err := a.recordScalingEvent(validService.ServiceId, event)
+		if err != nil {
+			a.Logger.Error("error recording scaling event", "err", err, "service-id", validService.ServiceId)
+		} else if event.Status == scalingEventStatusPending {
+			state.PendingScalingEventId = scalingEvent.ID
+
+			go a.verifyScaling(pendingScaling{
+				EventId:       scalingEvent.ID,
+				ServiceId:     validService.ServiceId,
+				EnvironmentId: validService.EnvironmentId,
+				Region:        railwayRegion,
+				OldReplicas:   currentReplicas,
+				Replicas:      event.NewReplicas,
+				MaxReplicas:   int(validService.Service.MaxReplicaCount),
+				Reason:        reason,
+				State:         state,
+			})
+		}
+	}
+
+	a.recordServiceMetrics(validService.ServiceId, railwayRegion, scalingContext)
+
 	a.Logger.Info("monitoring metrics",
+		"region", railwayRegion,
 		"current-cpu", fmt.Sprintf("%.2f%%", cpuPercent*100),
 		"current-mem", fmt.Sprintf("%.2f%%", memPercent*100),
-		"avg-cpu", fmt.Sprintf("%.2f%%", avgCpu*100),
-		"avg-mem", fmt.Sprintf("%.2f%%", avgMem*100),
-		"cpu-trend", fmt.Sprintf("%.4f", cpuTrend),
-		"mem-trend", fmt.Sprintf("%.4f", memTrend),
-		"cpu-spike", hasCpuSpike,
-		"mem-spike", hasMemSpike,
+		"avg-cpu", fmt.Sprintf("%.2f%%", scalingContext.AvgCpu*100),
+		"avg-mem", fmt.Sprintf("%.2f%%", scalingContext.AvgMem*100),
+		"cpu-trend", fmt.Sprintf("%.4f", scalingContext.CpuTrend),
+		"mem-trend", fmt.Sprintf("%.4f", scalingContext.MemTrend),
+		"cpu-spike", scalingContext.HasCpuSpike,
+		"mem-spike", scalingContext.HasMemSpike,
 		"replicas", currentReplicas,
-		"consecutive-high", consecutiveHighLoad,
-		"consecutive-low", consecutiveLowLoad,
+		"consecutive-high", state.ConsecutiveHighLoad,
+		"consecutive-low", state.ConsecutiveLowLoad,
 	)
 }
 
-func getValidServiceIds(registeredServices []repositories.Service, projectServiceNodes []gql.ServiceEdge) []ValidService {
+// newScalingContext summarizes the service's metric history for a scaling decision
+func newScalingContext(state *types.ServiceState, cpuPercent float64, memPercent float64, currentReplicas int, now time.Time, service repositories.Service) types.ScalingContext {
+	history := state.History
+
+	return types.ScalingContext{
+		CpuPercent:      cpuPercent,
+		MemPercent:      memPercent,
+		AvgCpu:          calculateWeightedAverage(history.CPU),
+		AvgMem:          calculateWeightedAverage(history.Memory),
+		HasCpuSpike:     detectSpike(history.CPU, spikeThreshold),
+		HasMemSpike:     detectSpike(history.Memory, spikeThreshold),
+		CpuTrend:        calculateTrend(history.CPU, history.Times),
+		MemTrend:        calculateTrend(history.Memory, history.Times),
+		CurrentReplicas: currentReplicas,
+		Now:             now,
+		Service:         service,
+	}
+}
+
+// decide runs the scaling policy selected for the service
+func (a *AutoscaleService) decide(ctx types.ScalingContext, state *types.ServiceState) (int, string, error) {
+	if ctx.Service.Policy == policyTargetTracking {
+		return a.makeTargetTrackingDecision(ctx, state)
+	}
+	if ctx.Service.Policy == policyPredictive {
+		return a.makePredictiveDecision(ctx, state)
+	}
+
+	return a.makeScalingDecision(ctx, state)
+}
+
+// scaleService updates multiRegionConfig once the region has been scaled, so later
+// mutations in the same evaluation don't undo it
+func (a *AutoscaleService) scaleService(serviceId string, environmentId string, multiRegionConfig map[string]gql.RegionConfig, region string, newReplicas int) error {
+	err := a.retryWithBackoff("update replicas", func() error {
+		return a.GqlQueries.MutationUpdateReplicas(environmentId, serviceId, multiRegionConfig, region, newReplicas)
+	})
+	if err != nil {
+		return fmt.Errorf("error updating replicas: %w", err)
+	}
+
+	multiRegionConfig[region] = gql.RegionConfig{NumReplicas: newReplicas}
+
+	err = a.retryWithBackoff("redeploy", func() error {
+		return a.GqlQueries.MutationServiceInstanceRedeploy(environmentId, serviceId)
+	})
+	if err != nil {
+		return fmt.Errorf("error redeploying scaled service: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AutoscaleService) getValidServiceIds(registeredServices []repositories.Service, projectServiceNodes []gql.ServiceEdge) []ValidService {
 	var validServiceIds []ValidService
 
 	for _, registeredService := range registeredServices {
+		environmentId := a.serviceEnvironmentId(registeredService)
+
 		for _, service := range projectServiceNodes {
-			if registeredService.ServiceID == service.Node.Id && registeredService.Enabled {
+			if registeredService.ServiceID != service.Node.Id || !registeredService.Enabled {
+				continue
+			}
+
+			// the service may not be deployed to the environment it was registered in
+			if _, ok := findServiceInstance(service.Node, environmentId); ok {
 				validServiceIds = append(validServiceIds, ValidService{
-					ServiceId: service.Node.Id,
-					Service:   registeredService,
+					ServiceId:     service.Node.Id,
+					EnvironmentId: environmentId,
+					Service:       registeredService,
 				})
 			}
 		}
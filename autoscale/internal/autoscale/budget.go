@@ -0,0 +1,287 @@
+package autoscale
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/go-chi/chi/v5"
+)
+
+// globalReplicaBudget keys the MAX_TOTAL_REPLICAS budget, which every service is in
+const globalReplicaBudget = ""
+
+// replicaUsage is how many replicas a budget allows and how many are running in it.
+// MaxReplicas is 0 when the budget only tracks usage
+type replicaUsage struct {
+	MaxReplicas int
+	Replicas    int
+	HourlyCost  float64
+}
+
+// replicaBudget caps the replicas the autoscaler runs across every active service, and
+// within each replica group. It's counted from the running replicas at the start of every
+// tick and shared by the workers, which reserve replicas from it before scaling up
+type replicaBudget struct {
+	usage map[string]*replicaUsage
+	// waiting is the highest priority each budget turned away last tick. Lower priority
+	// services can't grow in that budget until it's been given the replicas
+	waiting map[string]int32
+	denied  map[string]int32
+	mutex   sync.Mutex
+}
+
+// serviceJob is a service to evaluate along with the project data it was validated against
+type serviceJob struct {
+	ValidService ValidService
+	Project      *gql.ProjectData
+}
+
+func (a *AutoscaleService) newReplicaBudget(groupBudgets []repositories.ReplicaBudget, waiting map[string]int32) *replicaBudget {
+	budget := &replicaBudget{
+		usage: map[string]*replicaUsage{
+			globalReplicaBudget: {MaxReplicas: max(a.Config.MaxTotalReplicas, 0)},
+		},
+		waiting: waiting,
+		denied:  map[string]int32{},
+	}
+
+	for _, groupBudget := range groupBudgets {
+		budget.usage[groupBudget.ReplicaGroup] = &replicaUsage{MaxReplicas: int(groupBudget.MaxReplicas)}
+	}
+
+	return budget
+}
+
+// listServiceJobs queries every project with registered services, ordered so the highest
+// priority services are evaluated, and get to the replica budget, first
+func (a *AutoscaleService) listServiceJobs(registeredServices []repositories.Service) []serviceJob {
+	servicesByProject := a.groupServicesByProject(registeredServices)
+
+	var jobs []serviceJob
+
+	for _, projectId := range slices.Sorted(maps.Keys(servicesByProject)) {
+		project, err := a.GqlQueries.QueryProjectData(projectId)
+		if err != nil {
+			a.Logger.Error("error fetching project state", "err", err, "project-id", projectId)
+			continue
+		}
+
+		for _, validService := range a.getValidServiceIds(servicesByProject[projectId], project.Project.Services.Edges) {
+			jobs = append(jobs, serviceJob{ValidService: validService, Project: project})
+		}
+	}
+
+	slices.SortStableFunc(jobs, func(a serviceJob, b serviceJob) int {
+		return cmp.Compare(b.ValidService.Service.Priority, a.ValidService.Service.Priority)
+	})
+
+	return jobs
+}
+
+// countReplicas adds the replicas every active service is running to the budget. Shadow
+// services aren't scaled by the autoscaler, so they don't count against it
+func (a *AutoscaleService) countReplicas(budget *replicaBudget, jobs []serviceJob) {
+	for _, job := range jobs {
+		if job.ValidService.Service.Mode == serviceModeShadow {
+			continue
+		}
+
+		multiRegionConfig := a.getMultiRegionConfig(job.Project, job.ValidService.ServiceId, job.ValidService.EnvironmentId)
+		replicas := totalReplicas(multiRegionConfig)
+
+		budget.add(job.ValidService.Service, replicas)
+
+		for _, key := range budget.keys(job.ValidService.Service) {
+			budget.usage[key].HourlyCost += float64(replicas) * a.replicaHourlyCost(job.ValidService.Service)
+		}
+	}
+}
+
+// keys lists the budgets the service is in
+func (b *replicaBudget) keys(service repositories.Service) []string {
+	keys := []string{globalReplicaBudget}
+
+	if _, ok := b.usage[service.ReplicaGroup]; ok && service.ReplicaGroup != globalReplicaBudget {
+		keys = append(keys, service.ReplicaGroup)
+	}
+
+	return keys
+}
+
+// add counts replicas the service started or stopped against every budget it's in
+func (b *replicaBudget) add(service repositories.Service, replicas int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, key := range b.keys(service) {
+		b.usage[key].Replicas += replicas
+	}
+}
+
+// reserve takes as many of the replicas as every budget the service is in has room for,
+// returning how many it got and, if it didn't get them all, why not
+func (b *replicaBudget) reserve(service repositories.Service, replicas int) (int, string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	granted := replicas
+	message := ""
+
+	for _, key := range b.keys(service) {
+		usage := b.usage[key]
+
+		available := replicas
+		if waitingPriority, ok := b.waiting[key]; ok && service.Priority < waitingPriority {
+			available = 0
+			message = fmt.Sprintf("waiting for services with priority %d in the %s", waitingPriority, replicaBudgetName(key))
+		} else if usage.MaxReplicas > 0 && usage.MaxReplicas-usage.Replicas < replicas {
+			available = max(usage.MaxReplicas-usage.Replicas, 0)
+			message = fmt.Sprintf("the %s has %d of %d replicas in use", replicaBudgetName(key), usage.Replicas, usage.MaxReplicas)
+		}
+
+		if available < replicas {
+			if deniedPriority, ok := b.denied[key]; !ok || service.Priority > deniedPriority {
+				b.denied[key] = service.Priority
+			}
+		}
+
+		granted = min(granted, available)
+	}
+
+	for _, key := range b.keys(service) {
+		b.usage[key].Replicas += granted
+	}
+
+	return granted, message
+}
+
+func replicaBudgetName(key string) string {
+	if key == globalReplicaBudget {
+		return "global replica budget"
+	}
+
+	return fmt.Sprintf("%q replica budget", key)
+}
+
+// totalReplicas counts the replicas in every region, managed or not, since they all cost the same
+func totalReplicas(multiRegionConfig map[string]gql.RegionConfig) int {
+	replicas := 0
+	for _, regionConfig := range multiRegionConfig {
+		replicas += regionConfig.NumReplicas
+	}
+
+	return replicas
+}
+
+// replicaHourlyCost is the service's price per replica per hour, falling back to REPLICA_HOURLY_COST
+func (a *AutoscaleService) replicaHourlyCost(service repositories.Service) float64 {
+	if service.ReplicaHourlyCost > 0 {
+		return service.ReplicaHourlyCost
+	}
+
+	return a.Config.ReplicaHourlyCost
+}
+
+// roundCost keeps estimates to a hundredth of a cent
+func roundCost(cost float64) float64 {
+	return math.Round(cost*10000) / 10000
+}
+
+// ListReplicaBudgets reports how many replicas each budget allows and how many are in use
+func (a *AutoscaleService) ListReplicaBudgets(w http.ResponseWriter, r *http.Request) error {
+	groupBudgets, err := a.Queries.ListReplicaBudgets(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching replica budgets: %w", err)
+	}
+
+	registeredServices, err := a.Queries.ListServices(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching services from database: %w", err)
+	}
+
+	budget := a.newReplicaBudget(groupBudgets, nil)
+	a.countReplicas(budget, a.listServiceJobs(registeredServices))
+
+	global := budget.usage[globalReplicaBudget]
+
+	response := ListReplicaBudgetsResponse{
+		Global: ReplicaBudgetContext{
+			MaxReplicas:         global.MaxReplicas,
+			Replicas:            global.Replicas,
+			EstimatedHourlyCost: roundCost(global.HourlyCost),
+		},
+		Groups: []ReplicaBudgetContext{},
+	}
+
+	for _, groupBudget := range groupBudgets {
+		usage := budget.usage[groupBudget.ReplicaGroup]
+
+		response.Groups = append(response.Groups, ReplicaBudgetContext{
+			ReplicaGroup:        groupBudget.ReplicaGroup,
+			MaxReplicas:         usage.MaxReplicas,
+			Replicas:            usage.Replicas,
+			EstimatedHourlyCost: roundCost(usage.HourlyCost),
+		})
+	}
+
+	return writeJSON(w, response)
+}
+
+// UpsertReplicaBudget caps the replicas the autoscaler runs across the services in a group
+func (a *AutoscaleService) UpsertReplicaBudget(w http.ResponseWriter, r *http.Request) error {
+	replicaGroup := chi.URLParam(r, "group")
+
+	var budgetRequest ReplicaBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&budgetRequest); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return nil
+	}
+
+	if budgetRequest.MaxReplicas == nil {
+		http.Error(w, "max_replicas is required", http.StatusBadRequest)
+		return nil
+	}
+	if *budgetRequest.MaxReplicas < 1 {
+		http.Error(w, "max_replicas must be at least 1", http.StatusBadRequest)
+		return nil
+	}
+
+	now := time.Now().Unix()
+
+	replicaBudget, err := a.Queries.UpsertReplicaBudget(a.Context, repositories.UpsertReplicaBudgetParams{
+		ReplicaGroup: replicaGroup,
+		MaxReplicas:  int32(*budgetRequest.MaxReplicas),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting replica budget: %w", err)
+	}
+
+	return writeJSON(w, replicaBudget)
+}
+
+// DeleteReplicaBudget lifts a group's cap, its services are still in the global budget
+func (a *AutoscaleService) DeleteReplicaBudget(w http.ResponseWriter, r *http.Request) error {
+	deleted, err := a.Queries.DeleteReplicaBudget(a.Context, chi.URLParam(r, "group"))
+	if err != nil {
+		return fmt.Errorf("error deleting replica budget: %w", err)
+	}
+
+	if deleted == 0 {
+		http.Error(w, "Replica budget not found", http.StatusNotFound)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
@@ -0,0 +1,231 @@
+package autoscale
+
+import (
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+func TestReplicaBudgetReserve(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxTotalReplicas int
+		// groupMaxReplicas caps the "workers" group, which has no budget when it's 0
+		groupMaxReplicas int32
+		// running is how many replicas the service is already running
+		running  int
+		group    string
+		priority int32
+		waiting  map[string]int32
+		replicas int
+		granted  int
+		message  string
+		// usage is the replicas in use in each budget after the reservation
+		usage  map[string]int
+		denied map[string]int32
+	}{
+		{
+			name:     "no budget",
+			running:  4,
+			replicas: 3,
+			granted:  3,
+			usage:    map[string]int{globalReplicaBudget: 7},
+			denied:   map[string]int32{},
+		},
+		{
+			name:             "room in the global budget",
+			maxTotalReplicas: 10,
+			running:          4,
+			replicas:         3,
+			granted:          3,
+			usage:            map[string]int{globalReplicaBudget: 7},
+			denied:           map[string]int32{},
+		},
+		{
+			name:             "global budget runs out",
+			maxTotalReplicas: 10,
+			running:          8,
+			priority:         5,
+			replicas:         3,
+			granted:          2,
+			message:          "the global replica budget has 8 of 10 replicas in use",
+			usage:            map[string]int{globalReplicaBudget: 10},
+			denied:           map[string]int32{globalReplicaBudget: 5},
+		},
+		{
+			name:             "global budget over its cap",
+			maxTotalReplicas: 10,
+			running:          12,
+			replicas:         1,
+			message:          "the global replica budget has 12 of 10 replicas in use",
+			usage:            map[string]int{globalReplicaBudget: 12},
+			denied:           map[string]int32{globalReplicaBudget: 0},
+		},
+		{
+			name:             "group budget is tighter",
+			maxTotalReplicas: 10,
+			groupMaxReplicas: 5,
+			running:          4,
+			group:            "workers",
+			replicas:         3,
+			granted:          1,
+			message:          `the "workers" replica budget has 4 of 5 replicas in use`,
+			usage:            map[string]int{globalReplicaBudget: 5, "workers": 5},
+			denied:           map[string]int32{"workers": 0},
+		},
+		{
+			name:             "group without a budget",
+			maxTotalReplicas: 10,
+			running:          4,
+			group:            "workers",
+			replicas:         3,
+			granted:          3,
+			usage:            map[string]int{globalReplicaBudget: 7},
+			denied:           map[string]int32{},
+		},
+		{
+			name:             "waiting for a higher priority service",
+			maxTotalReplicas: 10,
+			running:          4,
+			priority:         1,
+			waiting:          map[string]int32{globalReplicaBudget: 2},
+			replicas:         3,
+			message:          "waiting for services with priority 2 in the global replica budget",
+			usage:            map[string]int{globalReplicaBudget: 4},
+			denied:           map[string]int32{globalReplicaBudget: 1},
+		},
+		{
+			name:             "priority that was waiting",
+			maxTotalReplicas: 10,
+			running:          4,
+			priority:         2,
+			waiting:          map[string]int32{globalReplicaBudget: 2},
+			replicas:         3,
+			granted:          3,
+			usage:            map[string]int{globalReplicaBudget: 7},
+			denied:           map[string]int32{},
+		},
+		{
+			name:             "waiting in the group budget",
+			maxTotalReplicas: 10,
+			groupMaxReplicas: 8,
+			running:          4,
+			group:            "workers",
+			waiting:          map[string]int32{"workers": 3},
+			replicas:         3,
+			message:          `waiting for services with priority 3 in the "workers" replica budget`,
+			usage:            map[string]int{globalReplicaBudget: 4, "workers": 4},
+			denied:           map[string]int32{"workers": 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &AutoscaleService{Config: &types.Config{MaxTotalReplicas: test.maxTotalReplicas}}
+
+			var groupBudgets []repositories.ReplicaBudget
+			if test.groupMaxReplicas > 0 {
+				groupBudgets = append(groupBudgets, repositories.ReplicaBudget{ReplicaGroup: "workers", MaxReplicas: test.groupMaxReplicas})
+			}
+
+			budget := a.newReplicaBudget(groupBudgets, test.waiting)
+
+			service := targetTrackingService()
+			service.ReplicaGroup = test.group
+			service.Priority = test.priority
+
+			budget.add(service, test.running)
+
+			granted, message := budget.reserve(service, test.replicas)
+			if granted != test.granted || message != test.message {
+				t.Errorf("got %d replicas %q, want %d replicas %q", granted, message, test.granted, test.message)
+			}
+
+			usage := map[string]int{}
+			for key, replicaUsage := range budget.usage {
+				usage[key] = replicaUsage.Replicas
+			}
+
+			if !maps.Equal(usage, test.usage) {
+				t.Errorf("got usage %v, want %v", usage, test.usage)
+			}
+			if !maps.Equal(budget.denied, test.denied) {
+				t.Errorf("got denied %v, want %v", budget.denied, test.denied)
+			}
+		})
+	}
+}
+
+func TestReplicaBudgetReserveAcrossServices(t *testing.T) {
+	a := &AutoscaleService{Config: &types.Config{MaxTotalReplicas: 6}}
+	budget := a.newReplicaBudget(nil, nil)
+
+	high := targetTrackingService()
+	high.Priority = 2
+	low := targetTrackingService()
+	low.Priority = 1
+
+	// the higher priority service reserves first, like the workers evaluate them
+	if granted, _ := budget.reserve(high, 4); granted != 4 {
+		t.Errorf("high priority service got %d replicas, want 4", granted)
+	}
+	if granted, _ := budget.reserve(low, 4); granted != 2 {
+		t.Errorf("low priority service got %d replicas, want 2", granted)
+	}
+
+	// scaling down hands replicas back
+	budget.add(high, -3)
+
+	if granted, message := budget.reserve(low, 2); granted != 2 || message != "" {
+		t.Errorf("got %d replicas %q after a scale down, want 2", granted, message)
+	}
+
+	if denied := budget.denied[globalReplicaBudget]; denied != 1 {
+		t.Errorf("got denied priority %d, want 1", denied)
+	}
+}
+
+func TestRunServiceJobsReservesByPriority(t *testing.T) {
+	a := &AutoscaleService{Config: &types.Config{MaxTotalReplicas: 6, AutoscaleWorkers: 4}}
+	budget := a.newReplicaBudget(nil, nil)
+
+	job := func(serviceId string, priority int32) serviceJob {
+		service := targetTrackingService()
+		service.ServiceID = serviceId
+		service.Priority = priority
+
+		return serviceJob{ValidService: ValidService{ServiceId: serviceId, Service: service}}
+	}
+
+	// sorted like listServiceJobs sorts them
+	jobs := []serviceJob{job("high", 2), job("low-1", 1), job("low-2", 1), job("low-3", 1)}
+
+	mutex := sync.Mutex{}
+	granted := map[string]int{}
+
+	a.runServiceJobs(jobs, func(job serviceJob) {
+		// the high priority service is slower to decide, while there are workers free for the rest
+		if job.ValidService.Service.Priority == 2 {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		replicas, _ := budget.reserve(job.ValidService.Service, 4)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		granted[job.ValidService.ServiceId] = replicas
+	})
+
+	if granted["high"] != 4 {
+		t.Errorf("high priority service got %d replicas, want 4", granted["high"])
+	}
+
+	if low := granted["low-1"] + granted["low-2"] + granted["low-3"]; low != 2 {
+		t.Errorf("low priority services got %d replicas, want 2", low)
+	}
+}
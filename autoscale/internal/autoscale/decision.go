@@ -3,32 +3,11 @@ package autoscale
 import (
 	"time"
 
-	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
 	"github.com/ferretcode/switchyard/autoscale/pkg/types"
 )
 
-func (a *AutoscaleService) getCurrentReplicas(project *gql.ProjectData, serviceId string) int {
-	if project == nil {
-		return 0
-	}
-
-	for _, service := range project.Project.Services.Edges {
-		if service.Node.Id != serviceId {
-			continue
-		}
-
-		for _, serviceInstance := range service.Node.ServiceInstances.Edges {
-			if serviceInstance.Node.EnvironmentId != a.Config.RailwayEnvironmentId {
-				continue
-			}
-
-			return serviceInstance.Node.LatestDeployment.Meta.ServiceManifest.Deploy.MultiRegionConfig[a.Config.RailwaySelectedRegion].NumReplicas
-		}
-	}
-	return 0
-}
-
-func (a *AutoscaleService) makeScalingDecision(ctx types.ScalingContext) (int, string, error) {
+// makeScalingDecision updates the service's cooldowns and load counters in state as it decides
+func (a *AutoscaleService) makeScalingDecision(ctx types.ScalingContext, state *types.ServiceState) (int, string, error) {
 	upscaleCooldown, err := time.ParseDuration(ctx.Service.UpscaleCooldown)
 	if err != nil {
 		return 0, "invalid-upscale-cooldown", err
@@ -39,16 +18,16 @@ func (a *AutoscaleService) makeScalingDecision(ctx types.ScalingContext) (int, s
 	}
 
 	// emergency high usage
-	if (ctx.CpuPercent > 0.9 || ctx.MemPercent > 0.9) && ctx.Now.Sub(lastUpscaleTime) > upscaleCooldown/3 {
-		lastUpscaleTime = ctx.Now
-		consecutiveLowLoad = 0
+	if (ctx.CpuPercent > 0.9 || ctx.MemPercent > 0.9) && ctx.Now.Sub(state.LastUpscaleTime) > upscaleCooldown/3 {
+		state.LastUpscaleTime = ctx.Now
+		state.ConsecutiveLowLoad = 0
 		return 2, "emergency-high-usage", nil
 	}
 
 	// spike detection
-	if (ctx.HasCpuSpike || ctx.HasMemSpike) && ctx.Now.Sub(lastUpscaleTime) > upscaleCooldown/2 {
-		lastUpscaleTime = ctx.Now
-		consecutiveLowLoad = 0
+	if (ctx.HasCpuSpike || ctx.HasMemSpike) && ctx.Now.Sub(state.LastUpscaleTime) > upscaleCooldown/2 {
+		state.LastUpscaleTime = ctx.Now
+		state.ConsecutiveLowLoad = 0
 		return 1, "spike-detected", nil
 	}
 
@@ -57,27 +36,43 @@ func (a *AutoscaleService) makeScalingDecision(ctx types.ScalingContext) (int, s
 	isIncreasingTrend := ctx.CpuTrend > 0.01 || ctx.MemTrend > 0.01
 	isNotInLowLoadZone := ctx.AvgCpu > ctx.Service.RailwayCpuDownscaleThreshold || ctx.AvgMem > ctx.Service.RailwayMemoryDownscaleThreshold
 
-	if (isSustainedHighLoad || (isIncreasingTrend && isNotInLowLoadZone)) && ctx.Now.Sub(lastUpscaleTime) > upscaleCooldown {
-		lastUpscaleTime = ctx.Now
-		consecutiveLowLoad = 0
+	if isSustainedHighLoad {
+		state.ConsecutiveHighLoad++
+	} else {
+		state.ConsecutiveHighLoad = 0
+	}
+
+	if (isSustainedHighLoad || (isIncreasingTrend && isNotInLowLoadZone)) && ctx.Now.Sub(state.LastUpscaleTime) > upscaleCooldown {
+		state.LastUpscaleTime = ctx.Now
+		state.ConsecutiveLowLoad = 0
 		return 1, "proactive-upscale", nil
 	}
 
+	// custom metrics
+	if isAnyMetricAboveTarget(ctx.Metrics) && ctx.Now.Sub(state.LastUpscaleTime) > upscaleCooldown {
+		state.LastUpscaleTime = ctx.Now
+		state.ConsecutiveLowLoad = 0
+		return 1, "metric-above-target", nil
+	}
+
 	// downscaling
 	isLowLoad := ctx.AvgCpu < ctx.Service.RailwayCpuDownscaleThreshold && ctx.AvgMem < ctx.Service.RailwayMemoryDownscaleThreshold
+	if len(ctx.Metrics) > 0 {
+		isLowLoad = isLowLoad && canRemoveReplica(ctx.Metrics, ctx.CurrentReplicas)
+	}
 
 	if isLowLoad {
-		consecutiveLowLoad++
+		state.ConsecutiveLowLoad++
 	} else {
-		consecutiveLowLoad = 0
+		state.ConsecutiveLowLoad = 0
 	}
 
-	if consecutiveLowLoad >= 4 &&
+	if state.ConsecutiveLowLoad >= 4 &&
 		ctx.CurrentReplicas > int(ctx.Service.MinReplicaCount) &&
-		ctx.Now.Sub(lastDownscaleTime) > downscaleCooldown {
+		ctx.Now.Sub(state.LastDownscaleTime) > downscaleCooldown {
 
-		lastDownscaleTime = ctx.Now
-		consecutiveLowLoad = 0
+		state.LastDownscaleTime = ctx.Now
+		state.ConsecutiveLowLoad = 0
 		return -1, "sustained-low-usage", nil
 	}
 
@@ -85,43 +80,6 @@ func (a *AutoscaleService) makeScalingDecision(ctx types.ScalingContext) (int, s
 	return 0, "no-scaling", nil
 }
 
-func extractMetrics(metrics *gql.MetricsData) (float64, float64) {
-	if metrics == nil {
-		return 0, 0
-	}
-
-	var memUsage, cpuUsage, memLimit, cpuLimit float64
-
-	for _, metric := range metrics.Metrics {
-		if len(metric.Values) == 0 {
-			continue
-		}
-
-		switch metric.Measurement {
-		case "MEMORY_USAGE_GB":
-			memUsage = metric.Values[0].Value
-		case "CPU_USAGE":
-			cpuUsage = metric.Values[0].Value
-		case "MEMORY_LIMIT_GB":
-			memLimit = metric.Values[0].Value
-		case "CPU_LIMIT":
-			cpuLimit = metric.Values[0].Value
-		}
-	}
-
-	cpuPercent := 0.0
-	memPercent := 0.0
-
-	if cpuLimit > 0 {
-		cpuPercent = cpuUsage / cpuLimit
-	}
-	if memLimit > 0 {
-		memPercent = memUsage / memLimit
-	}
-
-	return cpuPercent, memPercent
-}
-
 func calculateWeightedAverage(values []float64) float64 {
 	if len(values) == 0 {
 		return 0
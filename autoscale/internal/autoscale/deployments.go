@@ -0,0 +1,165 @@
+package autoscale
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+const scalingMutationBackoff = 2 * time.Second
+
+// pendingScaling is a replica change that Railway accepted but that isn't live yet
+type pendingScaling struct {
+	EventId       int64
+	ServiceId     string
+	EnvironmentId string
+	Region        string
+	OldReplicas   int
+	Replicas      int
+	MaxReplicas   int
+	Reason        string
+	State         *types.ServiceState
+}
+
+// scalingDeferral explains why the service shouldn't be scaled this tick, or is empty if
+// it can be. Scaling waits for the service's own deployments, and for the autoscaler's
+// previous change to go live, so decisions aren't made against a replica count that's
+// about to change
+func (a *AutoscaleService) scalingDeferral(project *gql.ProjectData, validService ValidService, regions []serviceRegion) string {
+	for _, region := range regions {
+		state := a.getServiceState(validService.ServiceId, region.Name)
+
+		state.Mutex.Lock()
+		pendingScalingEventId := state.PendingScalingEventId
+		state.Mutex.Unlock()
+
+		if pendingScalingEventId != 0 {
+			return fmt.Sprintf("waiting for scaling event %d to go live", pendingScalingEventId)
+		}
+	}
+
+	for _, service := range project.Project.Services.Edges {
+		if service.Node.Id != validService.ServiceId {
+			continue
+		}
+
+		serviceInstance, ok := findServiceInstance(service.Node, validService.EnvironmentId)
+		if ok && serviceInstance.LatestDeployment.IsInProgress() {
+			return fmt.Sprintf("deployment %s is %s", serviceInstance.LatestDeployment.Id, strings.ToLower(serviceInstance.LatestDeployment.Status))
+		}
+	}
+
+	return ""
+}
+
+// retryWithBackoff calls fn until it succeeds, retrying up to SCALING_MUTATION_RETRIES
+// times and doubling the wait after every failure
+func (a *AutoscaleService) retryWithBackoff(description string, fn func() error) error {
+	backoff := scalingMutationBackoff
+
+	for retry := 0; ; retry++ {
+		err := fn()
+		if err == nil || retry >= a.Config.ScalingMutationRetries {
+			return err
+		}
+
+		a.Logger.Warn("railway request failed, retrying", "err", err, "request", description, "retry", retry+1, "backoff", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-a.Context.Done():
+			return a.Context.Err()
+		}
+
+		backoff *= 2
+	}
+}
+
+// verifyScaling settles a pending scaling event as applied once its replicas are live,
+// or as failed if they aren't within SCALING_VERIFICATION_TIMEOUT, and notifies on-call
+// either way
+func (a *AutoscaleService) verifyScaling(pending pendingScaling) {
+	status, message := a.waitForReplicas(pending)
+
+	err := a.Queries.UpdateScalingEventStatus(a.Context, repositories.UpdateScalingEventStatusParams{
+		ID:      pending.EventId,
+		Status:  status,
+		Message: message,
+	})
+	if err != nil {
+		a.Logger.Error("error updating scaling event status", "err", err, "scaling-event-id", pending.EventId)
+	}
+
+	pending.State.Mutex.Lock()
+	if pending.State.PendingScalingEventId == pending.EventId {
+		pending.State.PendingScalingEventId = 0
+	}
+	pending.State.Mutex.Unlock()
+
+	notification := types.ScalingNotification{
+		Type:          notificationScaled,
+		ServiceId:     pending.ServiceId,
+		EnvironmentId: pending.EnvironmentId,
+		Region:        pending.Region,
+		OldReplicas:   pending.OldReplicas,
+		NewReplicas:   pending.Replicas,
+		MaxReplicas:   pending.MaxReplicas,
+		Reason:        pending.Reason,
+		Message:       message,
+	}
+	if status == scalingEventStatusFailed {
+		notification.Type = notificationScalingFailed
+	}
+
+	a.notify(notification)
+
+	a.Logger.Info("scaling event settled",
+		"service-id", pending.ServiceId,
+		"region", pending.Region,
+		"scaling-event-id", pending.EventId,
+		"status", status,
+		"message", message,
+	)
+}
+
+func (a *AutoscaleService) waitForReplicas(pending pendingScaling) (string, string) {
+	ticker := time.NewTicker(a.Config.ScalingVerificationInterval)
+	defer ticker.Stop()
+
+	timeout := time.NewTimer(a.Config.ScalingVerificationTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-a.Context.Done():
+			return scalingEventStatusFailed, "autoscaler stopped before the replicas went live"
+		case <-timeout.C:
+			return scalingEventStatusFailed, fmt.Sprintf("%d replicas weren't live after %s", pending.Replicas, a.Config.ScalingVerificationTimeout)
+		case <-ticker.C:
+			serviceInstance, err := a.GqlQueries.QueryServiceInstance(pending.ServiceId, pending.EnvironmentId)
+			if err != nil {
+				a.Logger.Warn("error checking scaled deployment", "err", err, "service-id", pending.ServiceId)
+				continue
+			}
+
+			deployment := serviceInstance.LatestDeployment
+
+			// until the redeploy shows up, the latest deployment still has the old replica count
+			if deployment.Meta.ServiceManifest.Deploy.MultiRegionConfig[pending.Region].NumReplicas != pending.Replicas {
+				continue
+			}
+
+			if deployment.HasFailed() {
+				return scalingEventStatusFailed, fmt.Sprintf("deployment %s %s", deployment.Id, strings.ToLower(deployment.Status))
+			}
+
+			if deployment.Status == "SUCCESS" {
+				return scalingEventStatusApplied, ""
+			}
+		}
+	}
+}
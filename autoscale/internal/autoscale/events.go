@@ -0,0 +1,115 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultScalingEventLimit = 50
+	maxScalingEventLimit     = 500
+)
+
+// ScalingEvent is one evaluation of a service, written to the audit log
+type ScalingEvent struct {
+	Region      string
+	OldReplicas int
+	NewReplicas int
+	Reason      string
+	Status      string
+	Message     string
+	Context     types.ScalingContext
+}
+
+func (a *AutoscaleService) recordScalingEvent(serviceId string, event ScalingEvent) (repositories.ScalingEvent, error) {
+	contextBytes, err := json.Marshal(event.Context)
+	if err != nil {
+		return repositories.ScalingEvent{}, fmt.Errorf("error encoding scaling context: %w", err)
+	}
+
+	scalingEvent, err := a.Queries.CreateScalingEvent(a.Context, repositories.CreateScalingEventParams{
+		ServiceID:      serviceId,
+		OldReplicas:    int32(event.OldReplicas),
+		NewReplicas:    int32(event.NewReplicas),
+		Reason:         event.Reason,
+		Status:         event.Status,
+		Message:        event.Message,
+		ScalingContext: json.RawMessage(contextBytes),
+		CreatedAt:      event.Context.Now.Unix(),
+		Region:         event.Region,
+	})
+	if err != nil {
+		return repositories.ScalingEvent{}, fmt.Errorf("error creating scaling event: %w", err)
+	}
+
+	return scalingEvent, nil
+}
+
+func (a *AutoscaleService) ListServiceEvents(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	limit, ok := parseQueryInt(w, r, "limit", defaultScalingEventLimit)
+	if !ok {
+		return nil
+	}
+	if limit <= 0 || limit > maxScalingEventLimit {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxScalingEventLimit), http.StatusBadRequest)
+		return nil
+	}
+
+	offset, ok := parseQueryInt(w, r, "offset", 0)
+	if !ok {
+		return nil
+	}
+	if offset < 0 {
+		http.Error(w, "offset can't be negative", http.StatusBadRequest)
+		return nil
+	}
+
+	events, err := a.Queries.ListScalingEventsByServiceID(a.Context, repositories.ListScalingEventsByServiceIDParams{
+		ServiceID: serviceId,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching scaling events: %w", err)
+	}
+
+	if events == nil {
+		events = []repositories.ScalingEvent{}
+	}
+
+	responseBytes, err := json.Marshal(ListServiceEventsResponse{Events: events})
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+// parseQueryInt writes a 400 and returns false if the parameter is set but isn't a number
+func parseQueryInt(w http.ResponseWriter, r *http.Request, name string, fallback int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, name+" must be a number", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return parsed, true
+}
@@ -0,0 +1,267 @@
+package autoscale
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// seasonalBucket is the resolution metric history is kept at for forecasting
+	seasonalBucket = 15 * time.Minute
+	// minForecastSeasons is how many previous days or weeks need history for a bucket
+	// before it's forecast, so one unusual day isn't taken as the pattern
+	minForecastSeasons = 2
+
+	defaultForecastHorizon = 6 * time.Hour
+	maxForecastHorizon     = 48 * time.Hour
+)
+
+// makePredictiveDecision is target tracking on the higher of the observed and the forecast
+// utilization, so replicas are added a lead time ahead of a seasonal peak and aren't
+// removed right before one. Without a forecast it's plain target tracking
+func (a *AutoscaleService) makePredictiveDecision(ctx types.ScalingContext, state *types.ServiceState) (int, string, error) {
+	if ctx.Forecast == nil {
+		return a.makeTargetTrackingDecision(ctx, state)
+	}
+
+	observedReplicas, _ := desiredReplicasForTargets(ctx)
+
+	// the forecast demand spread over the current replicas is the utilization they'd see,
+	// which target tracking turns back into the replicas that meet it
+	currentReplicas := float64(max(ctx.CurrentReplicas, 1))
+
+	ctx.AvgCpu = max(ctx.AvgCpu, ctx.Forecast.CpuDemand/currentReplicas)
+	ctx.AvgMem = max(ctx.AvgMem, ctx.Forecast.MemoryDemand/currentReplicas)
+
+	decision, reason, err := a.makeTargetTrackingDecision(ctx, state)
+
+	// only the forecast asked for more replicas
+	if decision > 0 && observedReplicas <= ctx.CurrentReplicas {
+		reason = "predictive-upscale"
+	}
+
+	return decision, reason, err
+}
+
+// forecastPeak returns the highest forecast demand within the service's lead time, or nil
+// if there isn't enough history to forecast it yet
+func (a *AutoscaleService) forecastPeak(service repositories.Service, region string, now time.Time) (*types.Forecast, error) {
+	leadTime, err := time.ParseDuration(service.PredictiveLeadTime)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing predictive lead time: %w", err)
+	}
+
+	until := now.Add(leadTime)
+
+	points, err := a.forecastService(service, region, now, until)
+	if err != nil || len(points) == 0 {
+		return nil, err
+	}
+
+	forecast := types.Forecast{Until: until}
+	for _, point := range points {
+		forecast.CpuDemand = max(forecast.CpuDemand, point.CpuDemand)
+		forecast.MemoryDemand = max(forecast.MemoryDemand, point.MemoryDemand)
+	}
+
+	return &forecast, nil
+}
+
+// forecastService forecasts each bucket between from and until from the service's rollups
+func (a *AutoscaleService) forecastService(service repositories.Service, region string, from time.Time, until time.Time) ([]ForecastPoint, error) {
+	period := seasonLength(service.SeasonalPeriod)
+	seasons := int(a.Config.SeasonalHistoryRetention / period)
+
+	targets := []time.Time{}
+	for bucket := from.Truncate(seasonalBucket); !bucket.After(until); bucket = bucket.Add(seasonalBucket) {
+		targets = append(targets, bucket)
+	}
+
+	bucketStarts := make([]int64, 0, len(targets)*seasons)
+	for _, target := range targets {
+		for season := 1; season <= seasons; season++ {
+			bucketStarts = append(bucketStarts, target.Add(-time.Duration(season)*period).Unix())
+		}
+	}
+
+	rollups, err := a.Queries.ListServiceMetricRollups(a.Context, repositories.ListServiceMetricRollupsParams{
+		ServiceID: service.ServiceID,
+		Region:    region,
+		Column3:   bucketStarts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching metric rollups: %w", err)
+	}
+
+	return seasonalForecast(service, rollups, targets, period, seasons), nil
+}
+
+// seasonalForecast forecasts each target bucket as the average demand of the same bucket in
+// previous days or weeks, weighted towards the most recent ones. Buckets without enough
+// history are left out
+func seasonalForecast(service repositories.Service, rollups []repositories.ServiceMetricRollup, targets []time.Time, period time.Duration, seasons int) []ForecastPoint {
+	rollupsByBucket := make(map[int64]repositories.ServiceMetricRollup, len(rollups))
+	for _, rollup := range rollups {
+		rollupsByBucket[rollup.BucketStart] = rollup
+	}
+
+	points := []ForecastPoint{}
+
+	for _, target := range targets {
+		var cpuDemand, memoryDemand, totalWeight float64
+		observed := 0
+
+		for season := 1; season <= seasons; season++ {
+			rollup, ok := rollupsByBucket[target.Add(-time.Duration(season)*period).Unix()]
+			if !ok {
+				continue
+			}
+
+			weight := float64(seasons - season + 1)
+			cpuDemand += rollup.CpuDemandSum / float64(rollup.Samples) * weight
+			memoryDemand += rollup.MemoryDemandSum / float64(rollup.Samples) * weight
+			totalWeight += weight
+			observed++
+		}
+
+		if observed < minForecastSeasons {
+			continue
+		}
+
+		point := ForecastPoint{
+			Timestamp:    target.Unix(),
+			CpuDemand:    cpuDemand / totalWeight,
+			MemoryDemand: memoryDemand / totalWeight,
+			Seasons:      observed,
+		}
+		point.Replicas = replicasForDemand(service, point.CpuDemand, point.MemoryDemand)
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// replicasForDemand is how many replicas keep the demand at the service's targets, within its bounds
+func replicasForDemand(service repositories.Service, cpuDemand float64, memoryDemand float64) int {
+	replicas := 0
+
+	if service.TargetCpuUtilization > 0 {
+		replicas = max(replicas, int(math.Ceil(cpuDemand/service.TargetCpuUtilization)))
+	}
+	if service.TargetMemoryUtilization > 0 {
+		replicas = max(replicas, int(math.Ceil(memoryDemand/service.TargetMemoryUtilization)))
+	}
+
+	return min(max(replicas, int(service.MinReplicaCount)), int(service.MaxReplicaCount))
+}
+
+// recordMetricRollup adds a sample's demand to the averages of its bucket, so forecasts hold
+// up when the replica count changes
+func (a *AutoscaleService) recordMetricRollup(serviceId string, region string, cpuPercent float64, memPercent float64, replicas int, sampledAt time.Time) error {
+	err := a.Queries.UpsertServiceMetricRollup(a.Context, repositories.UpsertServiceMetricRollupParams{
+		ServiceID:       serviceId,
+		Region:          region,
+		BucketStart:     sampledAt.Truncate(seasonalBucket).Unix(),
+		CpuDemandSum:    cpuPercent * float64(replicas),
+		MemoryDemandSum: memPercent * float64(replicas),
+	})
+	if err != nil {
+		return fmt.Errorf("error saving metric rollup: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AutoscaleService) clearOldMetricRollups(now time.Time) {
+	cleared, err := a.Queries.DeleteOldServiceMetricRollups(a.Context, now.Add(-a.Config.SeasonalHistoryRetention).Unix())
+	if err != nil {
+		a.Logger.Error("error clearing old metric rollups", "err", err)
+		return
+	}
+
+	if cleared > 0 {
+		a.Logger.Info("cleared old metric rollups", "count", cleared)
+	}
+}
+
+func seasonLength(seasonalPeriod string) time.Duration {
+	if seasonalPeriod == seasonalPeriodWeekly {
+		return 7 * 24 * time.Hour
+	}
+
+	return 24 * time.Hour
+}
+
+// GetServiceForecast returns the seasonal forecast of each of the service's regions, the
+// same forecast the predictive policy scales from
+func (a *AutoscaleService) GetServiceForecast(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	horizon := defaultForecastHorizon
+	if value := r.URL.Query().Get("horizon"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxForecastHorizon {
+			http.Error(w, "horizon must be a positive duration of at most 48h", http.StatusBadRequest)
+			return nil
+		}
+
+		horizon = parsed
+	}
+
+	service, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	regions, err := a.listServiceRegions(service)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	response := ForecastResponse{
+		ServiceId:          serviceId,
+		Policy:             service.Policy,
+		SeasonalPeriod:     service.SeasonalPeriod,
+		PredictiveLeadTime: service.PredictiveLeadTime,
+		Regions:            []RegionForecast{},
+	}
+
+	for _, region := range regions {
+		points, err := a.forecastService(service, region.Name, now, now.Add(horizon))
+		if err != nil {
+			return err
+		}
+
+		response.Regions = append(response.Regions, RegionForecast{
+			Region: a.railwayRegion(region.Name),
+			Points: points,
+		})
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
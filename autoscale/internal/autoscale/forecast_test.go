@@ -0,0 +1,123 @@
+package autoscale
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+func TestSeasonalForecast(t *testing.T) {
+	day := 24 * time.Hour
+	target := time.Date(2025, 6, 9, 9, 0, 0, 0, time.UTC)
+
+	service := targetTrackingService()
+
+	// rollup is a bucket seasons days before target where replicas ran at cpu utilization
+	rollup := func(seasons int, cpu float64, replicas int) repositories.ServiceMetricRollup {
+		return repositories.ServiceMetricRollup{
+			BucketStart:  target.Add(-time.Duration(seasons) * day).Unix(),
+			CpuDemandSum: cpu * float64(replicas) * 2,
+			Samples:      2,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		rollups   []repositories.ServiceMetricRollup
+		points    int
+		cpuDemand float64
+		replicas  int
+	}{
+		{
+			name:    "one season isn't enough",
+			rollups: []repositories.ServiceMetricRollup{rollup(1, 0.9, 2)},
+		},
+		{
+			// the same load ran on different replica counts, so utilization alone disagrees
+			name:      "same demand on different replicas",
+			rollups:   []repositories.ServiceMetricRollup{rollup(1, 0.9, 2), rollup(2, 0.3, 6)},
+			points:    1,
+			cpuDemand: 1.8,
+			replicas:  3,
+		},
+		{
+			name:      "weighted towards the most recent",
+			rollups:   []repositories.ServiceMetricRollup{rollup(1, 0.6, 4), rollup(3, 0.6, 1)},
+			points:    1,
+			cpuDemand: (2.4*3 + 0.6*1) / 4,
+			replicas:  4,
+		},
+		{
+			name:      "replicas held to max",
+			rollups:   []repositories.ServiceMetricRollup{rollup(1, 0.9, 20), rollup(2, 0.9, 20)},
+			points:    1,
+			cpuDemand: 18,
+			replicas:  10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			points := seasonalForecast(service, test.rollups, []time.Time{target}, day, 3)
+
+			if len(points) != test.points {
+				t.Fatalf("got %d points, want %d", len(points), test.points)
+			}
+			if test.points == 0 {
+				return
+			}
+
+			if math.Abs(points[0].CpuDemand-test.cpuDemand) > 1e-9 || points[0].Replicas != test.replicas {
+				t.Errorf("got demand %g for %d replicas, want %g for %d replicas", points[0].CpuDemand, points[0].Replicas, test.cpuDemand, test.replicas)
+			}
+		})
+	}
+}
+
+func TestMakePredictiveDecisionConvertsDemandToReplicas(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		currentReplicas int
+		cpu             float64
+		cpuDemand       float64
+		decision        int
+		reason          string
+	}{
+		// 2.4 replicas' worth of cpu needs 4 replicas at 60%
+		{name: "forecast peak", currentReplicas: 2, cpu: 0.6, cpuDemand: 2.4, decision: 2, reason: "predictive-upscale"},
+		{name: "forecast peak from more replicas", currentReplicas: 4, cpu: 0.3, cpuDemand: 2.4, reason: "no-scaling"},
+		{name: "observed load is higher", currentReplicas: 2, cpu: 0.9, cpuDemand: 0.6, decision: 1, reason: "target-tracking-upscale"},
+	}
+
+	a := &AutoscaleService{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := targetTrackingService()
+			service.Policy = policyPredictive
+			service.TargetMemoryUtilization = 0
+
+			ctx := types.ScalingContext{
+				AvgCpu:          test.cpu,
+				CurrentReplicas: test.currentReplicas,
+				Now:             now,
+				Service:         service,
+				Forecast:        &types.Forecast{CpuDemand: test.cpuDemand},
+			}
+
+			decision, reason, err := a.makePredictiveDecision(ctx, &types.ServiceState{})
+			if err != nil {
+				t.Fatalf("error making decision: %v", err)
+			}
+
+			if decision != test.decision || reason != test.reason {
+				t.Errorf("got decision %d %q, want %d %q", decision, reason, test.decision, test.reason)
+			}
+		})
+	}
+}
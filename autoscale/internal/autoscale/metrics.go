@@ -0,0 +1,75 @@
+package autoscale
+
+import (
+	"context"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+// queryServiceMetrics fetches the service's custom metrics. A metric that can't be
+// fetched is kept with its error, so the decision can tell it's flying blind
+func (a *AutoscaleService) queryServiceMetrics(ctx context.Context, serviceId string, environmentId string) []types.MetricSample {
+	serviceMetrics, err := a.Queries.ListServiceMetricsByServiceID(ctx, serviceId)
+	if err != nil {
+		a.Logger.Error("error fetching custom metrics", "err", err, "service-id", serviceId)
+		return []types.MetricSample{{Name: "custom-metrics", Error: err.Error()}}
+	}
+
+	samples := make([]types.MetricSample, 0, len(serviceMetrics))
+
+	for _, serviceMetric := range serviceMetrics {
+		sample := types.MetricSample{
+			Name:   serviceMetric.Name,
+			Target: serviceMetric.TargetValue,
+		}
+
+		provider, ok := a.MetricProviders[serviceMetric.Provider]
+		if !ok {
+			sample.Error = "metric provider " + serviceMetric.Provider + " is not configured"
+		} else if value, err := provider.Query(ctx, serviceId, environmentId, serviceMetric.Query); err != nil {
+			sample.Error = err.Error()
+		} else {
+			sample.Value = value
+		}
+
+		if sample.Error != "" {
+			a.Logger.Error("error querying custom metric", "err", sample.Error, "service-id", serviceId, "metric", serviceMetric.Name)
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples
+}
+
+// isAnyMetricAboveTarget ignores metrics that couldn't be fetched
+func isAnyMetricAboveTarget(samples []types.MetricSample) bool {
+	for _, sample := range samples {
+		if sample.Error == "" && sample.Value > sample.Target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canRemoveReplica checks that every metric would still be under its target if the
+// load were spread over one replica less. Metrics are expected to be per-replica
+// averages (or anything else that falls as replicas are added)
+func canRemoveReplica(samples []types.MetricSample, currentReplicas int) bool {
+	if currentReplicas <= 1 {
+		return false
+	}
+
+	for _, sample := range samples {
+		if sample.Error != "" {
+			return false
+		}
+
+		if sample.Value*float64(currentReplicas)/float64(currentReplicas-1) >= sample.Target {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package autoscale
+
+import (
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+const (
+	notificationScaled        = "scaled"
+	notificationMaxReplicas   = "max-replicas"
+	notificationScalingFailed = "scaling-failed"
+)
+
+// notify sends the notification in the background so a slow webhook or message bus
+// doesn't hold up the evaluation. It's a no-op when no notifier is configured
+func (a *AutoscaleService) notify(notification types.ScalingNotification) {
+	if a.WebhookService == nil {
+		return
+	}
+
+	notification.Timestamp = time.Now().Unix()
+
+	go func() {
+		if err := a.WebhookService.SendScalingNotification(notification); err != nil {
+			a.Logger.Error("error sending scaling notification", "err", err, "service-id", notification.ServiceId, "type", notification.Type)
+		}
+	}()
+}
+
+// notifyCapacityBound tells on-call the first time the policy wants more replicas than the
+// service's max allows, and again only once the policy has made a change within the bounds
+func (a *AutoscaleService) notifyCapacityBound(state *types.ServiceState, notification types.ScalingNotification, scalingDecision int) {
+	if scalingDecision == 0 {
+		return
+	}
+
+	// decisions are held to the max, so check the replicas the policy asked for
+	if scalingDecision < 0 || notification.OldReplicas+scalingDecision <= notification.MaxReplicas {
+		state.CapacityBound = false
+		return
+	}
+
+	if state.CapacityBound {
+		return
+	}
+
+	state.CapacityBound = true
+
+	notification.Type = notificationMaxReplicas
+	a.notify(notification)
+}
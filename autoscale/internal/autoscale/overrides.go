@@ -0,0 +1,122 @@
+package autoscale
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetServiceOverride pins a service's replicas until the override expires. While it's
+// active the autoscaler skips schedules and the scaling policy for the service
+func (a *AutoscaleService) SetServiceOverride(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	var overrideRequest ServiceOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&overrideRequest); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return nil
+	}
+
+	now := time.Now()
+
+	if message := validateServiceOverrideRequest(&overrideRequest, now); message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return nil
+	}
+
+	_, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	serviceOverride, err := a.Queries.UpsertServiceOverride(a.Context, repositories.UpsertServiceOverrideParams{
+		ServiceID:    serviceId,
+		ReplicaCount: int32(*overrideRequest.ReplicaCount),
+		Reason:       overrideRequest.Reason,
+		ExpiresAt:    *overrideRequest.ExpiresAt,
+		CreatedAt:    now.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting service override: %w", err)
+	}
+
+	return writeJSON(w, serviceOverride)
+}
+
+// DeleteServiceOverride hands the service back to the autoscaler before the override expires
+func (a *AutoscaleService) DeleteServiceOverride(w http.ResponseWriter, r *http.Request) error {
+	deleted, err := a.Queries.DeleteServiceOverride(a.Context, chi.URLParam(r, "id"))
+	if err != nil {
+		return fmt.Errorf("error deleting service override: %w", err)
+	}
+
+	if deleted == 0 {
+		http.Error(w, "Override not found", http.StatusNotFound)
+		return nil
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// getActiveOverride returns nil if the service has no override or it has expired
+func (a *AutoscaleService) getActiveOverride(serviceId string, now time.Time) (*repositories.ServiceOverride, error) {
+	serviceOverride, err := a.Queries.GetActiveServiceOverride(a.Context, repositories.GetActiveServiceOverrideParams{
+		ServiceID: serviceId,
+		ExpiresAt: now.Unix(),
+	})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching service override: %w", err)
+	}
+
+	return &serviceOverride, nil
+}
+
+// listActiveOverrides maps service ids to their active override
+func (a *AutoscaleService) listActiveOverrides(now time.Time) (map[string]*repositories.ServiceOverride, error) {
+	serviceOverrides, err := a.Queries.ListActiveServiceOverrides(a.Context, now.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching service overrides: %w", err)
+	}
+
+	overridesByServiceId := make(map[string]*repositories.ServiceOverride, len(serviceOverrides))
+	for i := range serviceOverrides {
+		overridesByServiceId[serviceOverrides[i].ServiceID] = &serviceOverrides[i]
+	}
+
+	return overridesByServiceId, nil
+}
+
+func (a *AutoscaleService) clearExpiredOverrides(now time.Time) {
+	cleared, err := a.Queries.DeleteExpiredServiceOverrides(a.Context, now.Unix())
+	if err != nil {
+		a.Logger.Error("error clearing expired service overrides", "err", err)
+		return
+	}
+
+	if cleared > 0 {
+		a.Logger.Info("cleared expired service overrides", "count", cleared)
+	}
+}
+
+func overrideReason(serviceOverride *repositories.ServiceOverride) string {
+	reason := fmt.Sprintf("manual override to %d replicas until %s", serviceOverride.ReplicaCount, time.Unix(serviceOverride.ExpiresAt, 0).UTC().Format(time.RFC3339))
+
+	if serviceOverride.Reason != "" {
+		reason += ": " + serviceOverride.Reason
+	}
+
+	return reason
+}
@@ -0,0 +1,148 @@
+package autoscale
+
+import (
+	"math"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+// targetTrackingTolerance is how far off target a metric has to be before target
+// tracking acts on it, so that small wobbles don't flap the replica count
+const targetTrackingTolerance = 0.1
+
+// makeTargetTrackingDecision scales replicas in proportion to how far each metric is from
+// its target: desired = ceil(current * observed / target), using the metric that asks for
+// the most replicas. Scale-ups are capped at max_scale_up_step, scale-downs at
+// max_scale_down_step and only go as low as the highest recommendation seen during the
+// stabilization window
+func (a *AutoscaleService) makeTargetTrackingDecision(ctx types.ScalingContext, state *types.ServiceState) (int, string, error) {
+	upscaleCooldown, err := time.ParseDuration(ctx.Service.UpscaleCooldown)
+	if err != nil {
+		return 0, "invalid-upscale-cooldown", err
+	}
+	downscaleCooldown, err := time.ParseDuration(ctx.Service.DownscaleCooldown)
+	if err != nil {
+		return 0, "invalid-downscale-cooldown", err
+	}
+	stabilizationWindow, err := time.ParseDuration(ctx.Service.ScaleDownStabilization)
+	if err != nil {
+		return 0, "invalid-scale-down-stabilization", err
+	}
+
+	desiredReplicas, ok := desiredReplicasForTargets(ctx)
+	if !ok {
+		return 0, "no-target-metrics", nil
+	}
+
+	// desired isn't held to the max, so a service that needs more replicas than it's allowed
+	// asks for them and is reported as capacity bound. The evaluation holds the decision to the max
+	desiredReplicas = max(desiredReplicas, int(ctx.Service.MinReplicaCount))
+
+	recordRecommendation(state, min(desiredReplicas, int(ctx.Service.MaxReplicaCount)), ctx.Now, stabilizationWindow)
+
+	if desiredReplicas > ctx.CurrentReplicas {
+		if ctx.Now.Sub(state.LastUpscaleTime) <= upscaleCooldown {
+			return 0, "upscale-cooldown", nil
+		}
+
+		step := min(desiredReplicas-ctx.CurrentReplicas, int(ctx.Service.MaxScaleUpStep))
+
+		state.LastUpscaleTime = ctx.Now
+		return step, "target-tracking-upscale", nil
+	}
+
+	if desiredReplicas < ctx.CurrentReplicas {
+		// the window has to be fully observed before trusting it, e.g. right after a restart
+		if len(state.Recommendations) == 0 || ctx.Now.Sub(state.Recommendations[0].Time) < stabilizationWindow {
+			return 0, "scale-down-stabilizing", nil
+		}
+
+		stabilizedReplicas := 0
+		for _, recommendation := range state.Recommendations {
+			if ctx.Now.Sub(recommendation.Time) > stabilizationWindow {
+				continue
+			}
+
+			stabilizedReplicas = max(stabilizedReplicas, recommendation.Replicas)
+		}
+
+		if stabilizedReplicas >= ctx.CurrentReplicas {
+			return 0, "scale-down-stabilizing", nil
+		}
+
+		if ctx.Now.Sub(state.LastDownscaleTime) <= downscaleCooldown {
+			return 0, "downscale-cooldown", nil
+		}
+
+		step := min(ctx.CurrentReplicas-stabilizedReplicas, int(ctx.Service.MaxScaleDownStep))
+
+		state.LastDownscaleTime = ctx.Now
+		return -step, "target-tracking-downscale", nil
+	}
+
+	return 0, "no-scaling", nil
+}
+
+// desiredReplicasForTargets returns the highest replica count asked for by cpu, memory and
+// the custom metrics, or false if none of them have a target
+func desiredReplicasForTargets(ctx types.ScalingContext) (int, bool) {
+	currentReplicas := max(ctx.CurrentReplicas, 1)
+
+	desiredReplicas := 0
+	hasTarget := false
+	hasMissingMetric := false
+
+	track := func(observed, target float64) {
+		hasTarget = true
+
+		ratio := observed / target
+		if math.Abs(ratio-1) <= targetTrackingTolerance {
+			desiredReplicas = max(desiredReplicas, ctx.CurrentReplicas)
+			return
+		}
+
+		desiredReplicas = max(desiredReplicas, int(math.Ceil(float64(currentReplicas)*ratio)))
+	}
+
+	if ctx.Service.TargetCpuUtilization > 0 {
+		track(ctx.AvgCpu, ctx.Service.TargetCpuUtilization)
+	}
+	if ctx.Service.TargetMemoryUtilization > 0 {
+		track(ctx.AvgMem, ctx.Service.TargetMemoryUtilization)
+	}
+
+	for _, sample := range ctx.Metrics {
+		if sample.Error != "" {
+			hasMissingMetric = true
+			continue
+		}
+
+		track(sample.Value, sample.Target)
+	}
+
+	// never scale down on partial information
+	if hasMissingMetric {
+		desiredReplicas = max(desiredReplicas, ctx.CurrentReplicas)
+	}
+
+	return desiredReplicas, hasTarget
+}
+
+func recordRecommendation(state *types.ServiceState, replicas int, now time.Time, window time.Duration) {
+	state.Recommendations = append(state.Recommendations, types.ReplicaRecommendation{
+		Replicas: replicas,
+		Time:     now,
+	})
+
+	// keep one recommendation from before the window so we can tell it has been fully observed
+	cutoff := 0
+	for i := 1; i < len(state.Recommendations); i++ {
+		if now.Sub(state.Recommendations[i].Time) < window {
+			break
+		}
+		cutoff = i
+	}
+
+	state.Recommendations = state.Recommendations[cutoff:]
+}
@@ -0,0 +1,171 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+func targetTrackingService() repositories.Service {
+	return defaultServiceConfig("svc")
+}
+
+func TestMakeTargetTrackingDecision(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	// a full stabilization window of recommendations for 3 replicas
+	stabilized := func(replicas int) []types.ReplicaRecommendation {
+		return []types.ReplicaRecommendation{
+			{Replicas: replicas, Time: now.Add(-6 * time.Minute)},
+			{Replicas: replicas, Time: now.Add(-2 * time.Minute)},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		currentReplicas int
+		cpu             float64
+		metrics         []types.MetricSample
+		lastUpscale     time.Time
+		recommendations []types.ReplicaRecommendation
+		decision        int
+		reason          string
+	}{
+		{
+			name:            "within tolerance",
+			currentReplicas: 4,
+			cpu:             0.62,
+			reason:          "no-scaling",
+		},
+		{
+			name:            "proportional upscale",
+			currentReplicas: 2,
+			cpu:             0.9,
+			decision:        1,
+			reason:          "target-tracking-upscale",
+		},
+		{
+			name:            "upscale capped at max step",
+			currentReplicas: 2,
+			cpu:             3,
+			decision:        4,
+			reason:          "target-tracking-upscale",
+		},
+		{
+			// held to max_replica_count by the evaluation, which reports it as capacity bound
+			name:            "upscale at max",
+			currentReplicas: 10,
+			cpu:             0.9,
+			decision:        4,
+			reason:          "target-tracking-upscale",
+		},
+		{
+			name:            "upscale cooldown",
+			currentReplicas: 2,
+			cpu:             0.9,
+			lastUpscale:     now.Add(-30 * time.Second),
+			reason:          "upscale-cooldown",
+		},
+		{
+			name:            "scale down waits for a full window",
+			currentReplicas: 6,
+			cpu:             0.1,
+			reason:          "scale-down-stabilizing",
+		},
+		{
+			name:            "scale down after a full window",
+			currentReplicas: 6,
+			cpu:             0.1,
+			recommendations: stabilized(1),
+			decision:        -1,
+			reason:          "target-tracking-downscale",
+		},
+		{
+			name:            "scale down held by a recent recommendation",
+			currentReplicas: 6,
+			cpu:             0.1,
+			recommendations: stabilized(6),
+			reason:          "scale-down-stabilizing",
+		},
+		{
+			name:            "custom metric asks for the most replicas",
+			currentReplicas: 2,
+			cpu:             0.6,
+			metrics:         []types.MetricSample{{Name: "queue", Value: 200, Target: 100}},
+			decision:        2,
+			reason:          "target-tracking-upscale",
+		},
+		{
+			name:            "missing metric never scales down",
+			currentReplicas: 6,
+			cpu:             0.1,
+			metrics:         []types.MetricSample{{Name: "queue", Target: 100, Error: "timeout"}},
+			recommendations: stabilized(1),
+			reason:          "no-scaling",
+		},
+	}
+
+	a := &AutoscaleService{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := targetTrackingService()
+			service.Policy = policyTargetTracking
+			service.TargetMemoryUtilization = 0
+
+			ctx := types.ScalingContext{
+				AvgCpu:          test.cpu,
+				CurrentReplicas: test.currentReplicas,
+				Metrics:         test.metrics,
+				Now:             now,
+				Service:         service,
+			}
+
+			state := &types.ServiceState{
+				LastUpscaleTime: test.lastUpscale,
+				Recommendations: test.recommendations,
+			}
+
+			decision, reason, err := a.makeTargetTrackingDecision(ctx, state)
+			if err != nil {
+				t.Fatalf("error making decision: %v", err)
+			}
+
+			if decision != test.decision || reason != test.reason {
+				t.Errorf("got decision %d %q, want %d %q", decision, reason, test.decision, test.reason)
+			}
+		})
+	}
+}
+
+func TestNotifyCapacityBound(t *testing.T) {
+	tests := []struct {
+		name          string
+		capacityBound bool
+		oldReplicas   int
+		decision      int
+		want          bool
+	}{
+		{name: "within max", oldReplicas: 4, decision: 2},
+		{name: "asks past max", oldReplicas: 8, decision: 4, want: true},
+		{name: "at max and still asking", oldReplicas: 10, decision: 1, want: true},
+		{name: "stays bound without a decision", capacityBound: true, oldReplicas: 10, want: true},
+		{name: "scale down clears it", capacityBound: true, oldReplicas: 10, decision: -1},
+	}
+
+	a := &AutoscaleService{}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &types.ServiceState{CapacityBound: test.capacityBound}
+
+			a.notifyCapacityBound(state, types.ScalingNotification{OldReplicas: test.oldReplicas, MaxReplicas: 10}, test.decision)
+
+			if state.CapacityBound != test.want {
+				t.Errorf("got capacity bound %v, want %v", state.CapacityBound, test.want)
+			}
+		})
+	}
+}
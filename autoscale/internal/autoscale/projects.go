@@ -0,0 +1,74 @@
+package autoscale
+
+import (
+	"slices"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+)
+
+// serviceProjectId is the Railway project the service lives in. Services registered
+// without one belong to RAILWAY_PROJECT_ID
+func (a *AutoscaleService) serviceProjectId(service repositories.Service) string {
+	if service.ProjectID == "" {
+		return a.Config.RailwayProjectId
+	}
+
+	return service.ProjectID
+}
+
+// serviceEnvironmentId is the Railway environment the service is scaled in. Services
+// registered without one are scaled in RAILWAY_ENVIRONMENT_ID
+func (a *AutoscaleService) serviceEnvironmentId(service repositories.Service) string {
+	if service.EnvironmentID == "" {
+		return a.Config.RailwayEnvironmentId
+	}
+
+	return service.EnvironmentID
+}
+
+// groupServicesByProject lets the autoscaler fetch each project once per tick
+func (a *AutoscaleService) groupServicesByProject(services []repositories.Service) map[string][]repositories.Service {
+	servicesByProject := map[string][]repositories.Service{}
+
+	for _, service := range services {
+		projectId := a.serviceProjectId(service)
+		servicesByProject[projectId] = append(servicesByProject[projectId], service)
+	}
+
+	return servicesByProject
+}
+
+// listProjectIds returns RAILWAY_PROJECT_ID followed by the other projects services are registered in
+func (a *AutoscaleService) listProjectIds(services []repositories.Service) []string {
+	projectIds := []string{a.Config.RailwayProjectId}
+
+	for _, service := range services {
+		projectId := a.serviceProjectId(service)
+		if !slices.Contains(projectIds, projectId) {
+			projectIds = append(projectIds, projectId)
+		}
+	}
+
+	return projectIds
+}
+
+func findServiceInstance(service gql.Service, environmentId string) (gql.ServiceInstance, bool) {
+	for _, serviceInstance := range service.ServiceInstances.Edges {
+		if serviceInstance.Node.EnvironmentId == environmentId {
+			return serviceInstance.Node, true
+		}
+	}
+
+	return gql.ServiceInstance{}, false
+}
+
+func environmentName(project *gql.ProjectData, environmentId string) string {
+	for _, environment := range project.Project.Environments.Edges {
+		if environment.Node.Id == environmentId {
+			return environment.Node.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,102 @@
+package autoscale
+
+import (
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// recordServiceMetrics exports what the monitoring metrics log line reports for a region
+func (a *AutoscaleService) recordServiceMetrics(serviceId string, region string, scalingContext types.ScalingContext) {
+	labels := prometheus.Labels{"service_id": serviceId, "region": region}
+
+	a.PrometheusMetrics.Replicas.With(labels).Set(float64(scalingContext.CurrentReplicas))
+	a.PrometheusMetrics.CpuUtilization.With(labels).Set(scalingContext.CpuPercent)
+	a.PrometheusMetrics.MemoryUtilization.With(labels).Set(scalingContext.MemPercent)
+	a.PrometheusMetrics.CpuWeightedAverage.With(labels).Set(scalingContext.AvgCpu)
+	a.PrometheusMetrics.MemoryWeightedAverage.With(labels).Set(scalingContext.AvgMem)
+	a.PrometheusMetrics.CpuTrend.With(labels).Set(scalingContext.CpuTrend)
+	a.PrometheusMetrics.MemoryTrend.With(labels).Set(scalingContext.MemTrend)
+
+	a.regionsMutex.Lock()
+	defer a.regionsMutex.Unlock()
+
+	if a.evaluatedRegions[serviceId] == nil {
+		a.evaluatedRegions[serviceId] = map[string]bool{}
+	}
+	a.evaluatedRegions[serviceId][region] = true
+}
+
+// recordScalingAction counts a replica change by its reason. Override reasons are free
+// text, so they're all counted under manual-override
+func (a *AutoscaleService) recordScalingAction(serviceId string, region string, reason string, status string, isOverride bool) {
+	if isOverride {
+		reason = "manual-override"
+	}
+
+	a.PrometheusMetrics.ScalingActions.WithLabelValues(serviceId, region, reason, status).Inc()
+}
+
+// forgetServiceMetrics drops the gauges of services that weren't evaluated this tick, and of
+// regions their service no longer reports, so unregistered services and regions don't keep
+// exporting their last values. A service that failed before reporting any region keeps them
+func (a *AutoscaleService) forgetServiceMetrics(evaluated map[string]bool) {
+	a.regionsMutex.Lock()
+	defer a.regionsMutex.Unlock()
+
+	for serviceId, regions := range a.reportedRegions {
+		if !evaluated[serviceId] {
+			a.deleteServiceGauges(prometheus.Labels{"service_id": serviceId})
+			delete(a.reportedRegions, serviceId)
+			continue
+		}
+
+		evaluatedRegions, ok := a.evaluatedRegions[serviceId]
+		if !ok {
+			continue
+		}
+
+		for region := range regions {
+			if !evaluatedRegions[region] {
+				a.deleteServiceGauges(prometheus.Labels{"service_id": serviceId, "region": region})
+			}
+		}
+	}
+
+	for serviceId, regions := range a.evaluatedRegions {
+		a.reportedRegions[serviceId] = regions
+	}
+
+	a.evaluatedRegions = map[string]map[string]bool{}
+}
+
+func (a *AutoscaleService) deleteServiceGauges(labels prometheus.Labels) {
+	for _, gauge := range a.serviceGauges() {
+		gauge.DeletePartialMatch(labels)
+	}
+}
+
+// resetServiceMetrics drops every service gauge once this instance stops scaling, since
+// only the leader's values are current
+func (a *AutoscaleService) resetServiceMetrics() {
+	for _, gauge := range a.serviceGauges() {
+		gauge.Reset()
+	}
+
+	a.regionsMutex.Lock()
+	defer a.regionsMutex.Unlock()
+
+	a.reportedRegions = map[string]map[string]bool{}
+	a.evaluatedRegions = map[string]map[string]bool{}
+}
+
+func (a *AutoscaleService) serviceGauges() []*prometheus.GaugeVec {
+	return []*prometheus.GaugeVec{
+		a.PrometheusMetrics.Replicas,
+		a.PrometheusMetrics.CpuUtilization,
+		a.PrometheusMetrics.MemoryUtilization,
+		a.PrometheusMetrics.CpuWeightedAverage,
+		a.PrometheusMetrics.MemoryWeightedAverage,
+		a.PrometheusMetrics.CpuTrend,
+		a.PrometheusMetrics.MemoryTrend,
+	}
+}
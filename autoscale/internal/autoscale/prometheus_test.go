@@ -0,0 +1,105 @@
+package autoscale
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func countLabelSets(gauge *prometheus.GaugeVec) int {
+	metrics := make(chan prometheus.Metric, 16)
+	gauge.Collect(metrics)
+	close(metrics)
+
+	return len(metrics)
+}
+
+func TestForgetServiceMetrics(t *testing.T) {
+	newGauge := func() *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "gauge"}, []string{"service_id", "region"})
+	}
+
+	a := NewAutoscaleService(nil, nil, nil, nil, nil, nil, nil, nil, nil, &types.PrometheusMetrics{
+		Replicas:              newGauge(),
+		CpuUtilization:        newGauge(),
+		MemoryUtilization:     newGauge(),
+		CpuWeightedAverage:    newGauge(),
+		MemoryWeightedAverage: newGauge(),
+		CpuTrend:              newGauge(),
+		MemoryTrend:           newGauge(),
+	}, nil)
+
+	// each tick runs after the ones before it
+	ticks := []struct {
+		name      string
+		evaluated []string
+		// reported are the regions each service got through to this tick
+		reported map[string][]string
+		want     map[string][]string
+	}{
+		{
+			name:      "first tick",
+			evaluated: []string{"api", "worker"},
+			reported:  map[string][]string{"api": {"us-west2", "europe-west4"}, "worker": {"us-west2"}},
+			want:      map[string][]string{"api": {"us-west2", "europe-west4"}, "worker": {"us-west2"}},
+		},
+		{
+			name:      "region removed",
+			evaluated: []string{"api", "worker"},
+			reported:  map[string][]string{"api": {"us-west2"}, "worker": {"us-west2"}},
+			want:      map[string][]string{"api": {"us-west2"}, "worker": {"us-west2"}},
+		},
+		{
+			name:      "evaluation failed before reporting",
+			evaluated: []string{"api", "worker"},
+			reported:  map[string][]string{"api": {"us-west2"}},
+			want:      map[string][]string{"api": {"us-west2"}, "worker": {"us-west2"}},
+		},
+		{
+			name:      "service unregistered",
+			evaluated: []string{"api"},
+			reported:  map[string][]string{"api": {"us-west2"}},
+			want:      map[string][]string{"api": {"us-west2"}},
+		},
+	}
+
+	for _, tick := range ticks {
+		evaluated := map[string]bool{}
+		for _, serviceId := range tick.evaluated {
+			evaluated[serviceId] = true
+		}
+
+		for serviceId, regions := range tick.reported {
+			for _, region := range regions {
+				a.recordServiceMetrics(serviceId, region, types.ScalingContext{})
+			}
+		}
+
+		a.forgetServiceMetrics(evaluated)
+
+		wantCount := 0
+		for _, regions := range tick.want {
+			wantCount += len(regions)
+		}
+
+		for _, gauge := range a.serviceGauges() {
+			if count := countLabelSets(gauge); count != wantCount {
+				t.Errorf("%s: got %d label sets, want %d", tick.name, count, wantCount)
+			}
+		}
+
+		want := map[string]map[string]bool{}
+		for serviceId, regions := range tick.want {
+			want[serviceId] = map[string]bool{}
+			for _, region := range regions {
+				want[serviceId][region] = true
+			}
+		}
+
+		if !maps.EqualFunc(a.reportedRegions, want, maps.Equal) {
+			t.Errorf("%s: got reported regions %v, want %v", tick.name, a.reportedRegions, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package autoscale
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+)
+
+// serviceRegion is a region the autoscaler manages a service's replicas in. Services
+// without region config have a single region named "", which is scaled in
+// RAILWAY_SELECTED_REGION within the service's own bounds
+type serviceRegion struct {
+	Name            string
+	MinReplicaCount int32
+	MaxReplicaCount int32
+}
+
+func (a *AutoscaleService) listServiceRegions(service repositories.Service) ([]serviceRegion, error) {
+	regions, err := a.Queries.ListServiceRegionsByServiceID(a.Context, service.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching service regions: %w", err)
+	}
+
+	if len(regions) == 0 {
+		return []serviceRegion{{
+			Name:            "",
+			MinReplicaCount: service.MinReplicaCount,
+			MaxReplicaCount: service.MaxReplicaCount,
+		}}, nil
+	}
+
+	serviceRegions := make([]serviceRegion, 0, len(regions))
+	for _, region := range regions {
+		serviceRegions = append(serviceRegions, serviceRegion{
+			Name:            region.Region,
+			MinReplicaCount: region.MinReplicaCount,
+			MaxReplicaCount: region.MaxReplicaCount,
+		})
+	}
+
+	return serviceRegions, nil
+}
+
+// railwayRegion is the region's name in the service's multiRegionConfig
+func (a *AutoscaleService) railwayRegion(region string) string {
+	if region == "" {
+		return a.Config.RailwaySelectedRegion
+	}
+
+	return region
+}
+
+// getMultiRegionConfig returns a copy of the service's region config in the environment
+func (a *AutoscaleService) getMultiRegionConfig(project *gql.ProjectData, serviceId string, environmentId string) map[string]gql.RegionConfig {
+	if project == nil {
+		return map[string]gql.RegionConfig{}
+	}
+
+	for _, service := range project.Project.Services.Edges {
+		if service.Node.Id != serviceId {
+			continue
+		}
+
+		for _, serviceInstance := range service.Node.ServiceInstances.Edges {
+			if serviceInstance.Node.EnvironmentId != environmentId {
+				continue
+			}
+
+			multiRegionConfig := serviceInstance.Node.LatestDeployment.Meta.ServiceManifest.Deploy.MultiRegionConfig
+			if multiRegionConfig == nil {
+				return map[string]gql.RegionConfig{}
+			}
+
+			return maps.Clone(multiRegionConfig)
+		}
+	}
+
+	return map[string]gql.RegionConfig{}
+}
+
+// getCurrentReplicas returns the number of replicas the service runs across the given regions
+func (a *AutoscaleService) getCurrentReplicas(multiRegionConfig map[string]gql.RegionConfig, regions []serviceRegion) int {
+	replicas := 0
+	for _, region := range regions {
+		replicas += multiRegionConfig[a.railwayRegion(region.Name)].NumReplicas
+	}
+
+	return replicas
+}
+
+// regionContexts lists the managed regions followed by any other region the service runs in
+func (a *AutoscaleService) regionContexts(multiRegionConfig map[string]gql.RegionConfig, regions []serviceRegion) []RegionContext {
+	regionContexts := make([]RegionContext, 0, len(multiRegionConfig))
+	managed := map[string]bool{}
+
+	for _, region := range regions {
+		railwayRegion := a.railwayRegion(region.Name)
+		managed[railwayRegion] = true
+
+		regionContexts = append(regionContexts, RegionContext{
+			Region:      railwayRegion,
+			Replicas:    multiRegionConfig[railwayRegion].NumReplicas,
+			Managed:     true,
+			MinReplicas: int(region.MinReplicaCount),
+			MaxReplicas: int(region.MaxReplicaCount),
+		})
+	}
+
+	for _, region := range slices.Sorted(maps.Keys(multiRegionConfig)) {
+		if managed[region] {
+			continue
+		}
+
+		regionContexts = append(regionContexts, RegionContext{
+			Region:   region,
+			Replicas: multiRegionConfig[region].NumReplicas,
+		})
+	}
+
+	return regionContexts
+}
@@ -0,0 +1,148 @@
+package autoscale
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/robfig/cron/v3"
+)
+
+type parsedSchedule struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+func parseServiceSchedule(cronExpression, duration, timezone string) (parsedSchedule, error) {
+	schedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return parsedSchedule{}, fmt.Errorf("error parsing cron expression: %w", err)
+	}
+
+	windowDuration, err := time.ParseDuration(duration)
+	if err != nil {
+		return parsedSchedule{}, fmt.Errorf("error parsing duration: %w", err)
+	}
+	if windowDuration <= 0 {
+		return parsedSchedule{}, fmt.Errorf("duration must be positive")
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return parsedSchedule{}, fmt.Errorf("error loading timezone: %w", err)
+	}
+
+	return parsedSchedule{
+		schedule: schedule,
+		duration: windowDuration,
+		location: location,
+	}, nil
+}
+
+// isActive reports whether a window started by the cron expression is still open at now
+func (p parsedSchedule) isActive(now time.Time) bool {
+	start := p.schedule.Next(now.In(p.location).Add(-p.duration))
+	return !start.After(now)
+}
+
+func (p parsedSchedule) nextStart(now time.Time) time.Time {
+	return p.schedule.Next(now.In(p.location))
+}
+
+// activeServiceSchedule returns the first enabled schedule whose window covers now
+func activeServiceSchedule(schedules []repositories.ServiceSchedule, now time.Time) (*repositories.ServiceSchedule, error) {
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+
+		parsed, err := parseServiceSchedule(schedule.CronExpression, schedule.Duration, schedule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing schedule %d: %w", schedule.ID, err)
+		}
+
+		if parsed.isActive(now) {
+			return &schedule, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// applyServiceSchedules narrows the service's replica bounds to those of its active
+// schedule. A pinned replica count, or replicas outside of the scheduled bounds, are
+// returned as a decision of their own and the reactive policy is skipped; an empty
+// reason means the reactive policy should decide
+func (a *AutoscaleService) applyServiceSchedules(service repositories.Service, currentReplicas int, now time.Time) (repositories.Service, int, string, error) {
+	schedules, err := a.Queries.ListServiceSchedulesByServiceID(a.Context, service.ServiceID)
+	if err != nil {
+		return service, 0, "", fmt.Errorf("error fetching service schedules: %w", err)
+	}
+
+	schedule, err := activeServiceSchedule(schedules, now)
+	if err != nil {
+		return service, 0, "", err
+	}
+
+	service, scalingDecision, reason := applyServiceSchedule(service, schedule, currentReplicas)
+
+	return service, scalingDecision, reason, nil
+}
+
+// applyServiceSchedule applies the active schedule, if there is one, to the service
+func applyServiceSchedule(service repositories.Service, schedule *repositories.ServiceSchedule, currentReplicas int) (repositories.Service, int, string) {
+	// a window that pinned or raised the replicas can close with them above the service's
+	// own max, which the reactive policy only moves away from when it decides to scale
+	if schedule == nil {
+		step := stepIntoBounds(service, currentReplicas)
+
+		switch {
+		case step > 0:
+			return service, step, "below-minimum"
+		case step < 0:
+			return service, step, "above-maximum"
+		}
+
+		return service, 0, ""
+	}
+
+	if schedule.PinnedReplicaCount.Valid {
+		service.MinReplicaCount = schedule.PinnedReplicaCount.Int32
+		service.MaxReplicaCount = schedule.PinnedReplicaCount.Int32
+
+		return service, int(schedule.PinnedReplicaCount.Int32) - currentReplicas, "scheduled-pin"
+	}
+
+	if schedule.MinReplicaCount.Valid {
+		service.MinReplicaCount = schedule.MinReplicaCount.Int32
+		service.MaxReplicaCount = max(service.MaxReplicaCount, service.MinReplicaCount)
+	}
+	if schedule.MaxReplicaCount.Valid {
+		service.MaxReplicaCount = schedule.MaxReplicaCount.Int32
+		service.MinReplicaCount = min(service.MinReplicaCount, service.MaxReplicaCount)
+	}
+
+	step := stepIntoBounds(service, currentReplicas)
+
+	switch {
+	case step > 0:
+		return service, step, "scheduled-minimum"
+	case step < 0:
+		return service, step, "scheduled-maximum"
+	}
+
+	return service, 0, ""
+}
+
+// stepIntoBounds is how far the replicas have to move to be within the service's min and max
+func stepIntoBounds(service repositories.Service, currentReplicas int) int {
+	if currentReplicas < int(service.MinReplicaCount) {
+		return int(service.MinReplicaCount) - currentReplicas
+	}
+	if currentReplicas > int(service.MaxReplicaCount) {
+		return int(service.MaxReplicaCount) - currentReplicas
+	}
+
+	return 0
+}
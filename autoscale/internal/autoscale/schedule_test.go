@@ -0,0 +1,154 @@
+package autoscale
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+)
+
+func TestParseServiceSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		cronExpression string
+		duration       string
+		timezone       string
+		wantErr        bool
+	}{
+		{name: "valid", cronExpression: "0 9 * * 1-5", duration: "8h", timezone: "UTC"},
+		{name: "descriptor", cronExpression: "@daily", duration: "1h", timezone: "Europe/London"},
+		{name: "invalid cron", cronExpression: "every day", duration: "1h", timezone: "UTC", wantErr: true},
+		{name: "invalid duration", cronExpression: "@daily", duration: "a while", timezone: "UTC", wantErr: true},
+		{name: "zero duration", cronExpression: "@daily", duration: "0s", timezone: "UTC", wantErr: true},
+		{name: "unknown timezone", cronExpression: "@daily", duration: "1h", timezone: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseServiceSchedule(test.cronExpression, test.duration, test.timezone)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestActiveServiceSchedule(t *testing.T) {
+	// weekdays from 9:00 to 17:00 in New York
+	businessHours := repositories.ServiceSchedule{ID: 1, CronExpression: "0 9 * * 1-5", Duration: "8h", Timezone: "America/New_York", Enabled: true}
+	disabled := repositories.ServiceSchedule{ID: 2, CronExpression: "* * * * *", Duration: "1h", Timezone: "UTC", Enabled: false}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		active int64
+	}{
+		{name: "window opening", now: time.Date(2025, 6, 2, 9, 0, 0, 0, newYork), active: 1},
+		{name: "inside window", now: time.Date(2025, 6, 2, 16, 59, 0, 0, newYork), active: 1},
+		{name: "window closed", now: time.Date(2025, 6, 2, 17, 1, 0, 0, newYork), active: 0},
+		{name: "before window", now: time.Date(2025, 6, 2, 8, 59, 0, 0, newYork), active: 0},
+		{name: "weekend", now: time.Date(2025, 6, 7, 12, 0, 0, 0, newYork), active: 0},
+		{name: "timezone is respected", now: time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC), active: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := activeServiceSchedule([]repositories.ServiceSchedule{disabled, businessHours}, test.now)
+			if err != nil {
+				t.Fatalf("error finding active schedule: %v", err)
+			}
+
+			var active int64
+			if schedule != nil {
+				active = schedule.ID
+			}
+
+			if active != test.active {
+				t.Errorf("got active schedule %d, want %d", active, test.active)
+			}
+		})
+	}
+}
+
+func TestApplyServiceSchedule(t *testing.T) {
+	service := repositories.Service{ServiceID: "svc", MinReplicaCount: 2, MaxReplicaCount: 5}
+
+	tests := []struct {
+		name            string
+		schedule        *repositories.ServiceSchedule
+		currentReplicas int
+		decision        int
+		reason          string
+		min, max        int32
+	}{
+		{
+			name:            "no window, within bounds",
+			currentReplicas: 3,
+			min:             2, max: 5,
+		},
+		{
+			name:            "no window, left above max",
+			currentReplicas: 8,
+			decision:        -3,
+			reason:          "above-maximum",
+			min:             2, max: 5,
+		},
+		{
+			name:            "no window, left below min",
+			currentReplicas: 0,
+			decision:        2,
+			reason:          "below-minimum",
+			min:             2, max: 5,
+		},
+		{
+			name:            "pinned",
+			schedule:        &repositories.ServiceSchedule{PinnedReplicaCount: sql.NullInt32{Int32: 8, Valid: true}},
+			currentReplicas: 3,
+			decision:        5,
+			reason:          "scheduled-pin",
+			min:             8, max: 8,
+		},
+		{
+			name:            "raised minimum above max",
+			schedule:        &repositories.ServiceSchedule{MinReplicaCount: sql.NullInt32{Int32: 7, Valid: true}},
+			currentReplicas: 3,
+			decision:        4,
+			reason:          "scheduled-minimum",
+			min:             7, max: 7,
+		},
+		{
+			name:            "lowered maximum",
+			schedule:        &repositories.ServiceSchedule{MaxReplicaCount: sql.NullInt32{Int32: 1, Valid: true}},
+			currentReplicas: 3,
+			decision:        -2,
+			reason:          "scheduled-maximum",
+			min:             1, max: 1,
+		},
+		{
+			name:            "within scheduled bounds",
+			schedule:        &repositories.ServiceSchedule{MinReplicaCount: sql.NullInt32{Int32: 3, Valid: true}, MaxReplicaCount: sql.NullInt32{Int32: 10, Valid: true}},
+			currentReplicas: 8,
+			min:             3, max: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduled, decision, reason := applyServiceSchedule(service, test.schedule, test.currentReplicas)
+
+			if decision != test.decision || reason != test.reason {
+				t.Errorf("got decision %d %q, want %d %q", decision, reason, test.decision, test.reason)
+			}
+
+			if scheduled.MinReplicaCount != test.min || scheduled.MaxReplicaCount != test.max {
+				t.Errorf("got bounds %d-%d, want %d-%d", scheduled.MinReplicaCount, scheduled.MaxReplicaCount, test.min, test.max)
+			}
+		})
+	}
+}
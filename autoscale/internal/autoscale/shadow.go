@@ -0,0 +1,120 @@
+package autoscale
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultShadowReportPeriod = 24 * time.Hour
+	maxShadowReportPeriod     = 30 * 24 * time.Hour
+)
+
+// GetShadowReport lines up what the autoscaler would have done against the
+// replica count the service actually had at each evaluation
+func (a *AutoscaleService) GetShadowReport(w http.ResponseWriter, r *http.Request) error {
+	serviceId := chi.URLParam(r, "id")
+
+	period := defaultShadowReportPeriod
+	if value := r.URL.Query().Get("period"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxShadowReportPeriod {
+			http.Error(w, "period must be a positive duration of at most 720h", http.StatusBadRequest)
+			return nil
+		}
+
+		period = parsed
+	}
+
+	service, err := a.Queries.GetService(a.Context, serviceId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Service not found", http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	since := time.Now().Add(-period).Unix()
+
+	events, err := a.Queries.ListScalingEventsByServiceIDSince(a.Context, repositories.ListScalingEventsByServiceIDSinceParams{
+		ServiceID: serviceId,
+		CreatedAt: since,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching scaling events: %w", err)
+	}
+
+	response := buildShadowReport(events)
+	response.ServiceId = serviceId
+	response.Mode = service.Mode
+	response.Since = since
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+func buildShadowReport(events []repositories.ScalingEvent) ShadowReportResponse {
+	report := ShadowReportResponse{
+		Points: []ShadowReportPoint{},
+	}
+
+	totalDifference := 0
+
+	for _, event := range events {
+		point := ShadowReportPoint{
+			Timestamp:      event.CreatedAt,
+			Region:         event.Region,
+			ActualReplicas: int(event.OldReplicas),
+			ShadowReplicas: int(event.OldReplicas),
+			Reason:         event.Reason,
+		}
+
+		if event.Status == scalingEventStatusShadow {
+			point.ShadowReplicas = int(event.NewReplicas)
+
+			if event.NewReplicas > event.OldReplicas {
+				report.Summary.Upscales++
+			} else {
+				report.Summary.Downscales++
+			}
+		}
+
+		difference := point.ShadowReplicas - point.ActualReplicas
+		if difference < 0 {
+			difference = -difference
+		}
+
+		if difference > 0 {
+			report.Summary.DivergedEvaluations++
+		}
+
+		report.Summary.MaxReplicaDifference = max(report.Summary.MaxReplicaDifference, difference)
+		totalDifference += difference
+
+		report.Points = append(report.Points, point)
+	}
+
+	report.Summary.Evaluations = len(report.Points)
+
+	if report.Summary.Evaluations > 0 {
+		report.Summary.MeanAbsoluteDifference = float64(totalDifference) / float64(report.Summary.Evaluations)
+	}
+
+	return report
+}
@@ -0,0 +1,99 @@
+package autoscale
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+// SimulationSample is one reading from a recorded metric series
+type SimulationSample struct {
+	Time       time.Time
+	CpuPercent float64
+	MemPercent float64
+}
+
+// SimulationStep is what the autoscaler made of one sample
+type SimulationStep struct {
+	Context     types.ScalingContext
+	Decision    int
+	Reason      string
+	NewReplicas int
+	Status      string
+}
+
+// NewSimulatedService builds a service from a register request, filling in the same
+// defaults and running the same validation as registering it would
+func NewSimulatedService(req RegisterServiceRequest) (repositories.Service, error) {
+	if req.ServiceId == nil {
+		serviceId := "simulated"
+		req.ServiceId = &serviceId
+	}
+
+	service := mergeServiceConfig(defaultServiceConfig(*req.ServiceId), (*UpsertServiceRequest)(&req))
+
+	if fieldErrors := validateServiceConfig(service); len(fieldErrors) > 0 {
+		messages := make([]string, 0, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			messages = append(messages, fieldError.Field+" "+fieldError.Message)
+		}
+		return repositories.Service{}, fmt.Errorf("invalid service config: %s", strings.Join(messages, ", "))
+	}
+
+	return service, nil
+}
+
+// Simulate replays samples through the same history, spike, trend and policy code the
+// autoscaler runs, without calling Railway or the database. Schedules, custom metrics and
+// forecasts aren't simulated. A replica change takes effect from the next sample on
+func Simulate(logger *slog.Logger, config *types.Config, service repositories.Service, initialReplicas int, samples []SimulationSample) ([]SimulationStep, error) {
+	a := &AutoscaleService{
+		Logger: logger,
+		Config: config,
+	}
+
+	state := a.newServiceState()
+	replicas := initialReplicas
+
+	steps := make([]SimulationStep, 0, len(samples))
+
+	for _, sample := range samples {
+		a.appendMetricSample(state, sample.CpuPercent, sample.MemPercent, sample.Time)
+
+		scalingContext := newScalingContext(state, sample.CpuPercent, sample.MemPercent, replicas, sample.Time, service)
+
+		decision, reason, err := a.decide(scalingContext, state)
+		if err != nil {
+			return nil, err
+		}
+
+		step := SimulationStep{
+			Context:     scalingContext,
+			Decision:    decision,
+			Reason:      reason,
+			NewReplicas: replicas,
+			Status:      scalingEventStatusNoOp,
+		}
+
+		if decision != 0 {
+			// held to the nearest bound, the same as the autoscaler does
+			newReplicas := min(max(replicas+decision, int(service.MinReplicaCount)), int(service.MaxReplicaCount))
+
+			if newReplicas != replicas {
+				step.NewReplicas = newReplicas
+				step.Status = scalingEventStatusApplied
+				replicas = newReplicas
+			} else {
+				step.Status = scalingEventStatusSkipped
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
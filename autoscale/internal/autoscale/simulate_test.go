@@ -0,0 +1,81 @@
+package autoscale
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+func TestSimulateHoldsDecisionsToBounds(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := &types.Config{MetricHistorySize: 30}
+
+	minReplicas, maxReplicas := 1, 5
+
+	service, err := NewSimulatedService(RegisterServiceRequest{
+		MinReplicaCount: &minReplicas,
+		MaxReplicaCount: &maxReplicas,
+	})
+	if err != nil {
+		t.Fatalf("error building simulated service: %v", err)
+	}
+
+	start := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	samples := func(cpu float64, count int) []SimulationSample {
+		var samples []SimulationSample
+		for i := range count {
+			samples = append(samples, SimulationSample{Time: start.Add(time.Duration(i) * time.Minute), CpuPercent: cpu, MemPercent: cpu})
+		}
+		return samples
+	}
+
+	tests := []struct {
+		name            string
+		initialReplicas int
+		samples         []SimulationSample
+		replicas        int
+		status          string
+	}{
+		{
+			// e.g. left behind by an expired override, the first scale down goes straight to max
+			name:            "above max",
+			initialReplicas: 8,
+			samples:         samples(0.05, 4),
+			replicas:        5,
+			status:          scalingEventStatusApplied,
+		},
+		{
+			name:            "emergency upscale next to max",
+			initialReplicas: 4,
+			samples:         samples(0.95, 1),
+			replicas:        5,
+			status:          scalingEventStatusApplied,
+		},
+		{
+			name:            "upscale at max",
+			initialReplicas: 5,
+			samples:         samples(0.95, 1),
+			replicas:        5,
+			status:          scalingEventStatusSkipped,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			steps, err := Simulate(logger, config, service, test.initialReplicas, test.samples)
+			if err != nil {
+				t.Fatalf("error simulating: %v", err)
+			}
+
+			last := steps[len(steps)-1]
+
+			if last.NewReplicas != test.replicas || last.Status != test.status {
+				t.Errorf("got %d replicas %s (%s), want %d replicas %s", last.NewReplicas, last.Status, last.Reason, test.replicas, test.status)
+			}
+		})
+	}
+}
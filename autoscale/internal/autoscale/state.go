@@ -0,0 +1,184 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+// LoadServiceStates restores cooldowns, load counters, target tracking recommendations and
+// metric history from the database, so a restart doesn't make the autoscaler forget what it
+// was doing
+func (a *AutoscaleService) LoadServiceStates() error {
+	scalingStates, err := a.Queries.ListServiceScalingStates(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching service scaling states: %w", err)
+	}
+
+	metricSamples, err := a.Queries.ListServiceMetricSamples(a.Context)
+	if err != nil {
+		return fmt.Errorf("error fetching service metric samples: %w", err)
+	}
+
+	// nothing is left watching changes that were in flight when the autoscaler stopped
+	if err := a.Queries.FailPendingScalingEvents(a.Context, "autoscaler restarted before the replicas went live"); err != nil {
+		return fmt.Errorf("error failing pending scaling events: %w", err)
+	}
+
+	a.ServiceStateCache.Mutex.Lock()
+	defer a.ServiceStateCache.Mutex.Unlock()
+
+	// loaded again whenever this instance becomes the leader, picking up where the previous one left off
+	a.ServiceStateCache.ServiceStates = make(map[string]*types.ServiceState)
+
+	for _, scalingState := range scalingStates {
+		state := a.newServiceState()
+
+		state.LastUpscaleTime = fromUnix(scalingState.LastUpscaleAt)
+		state.LastDownscaleTime = fromUnix(scalingState.LastDownscaleAt)
+		state.ConsecutiveHighLoad = int(scalingState.ConsecutiveHighLoad)
+		state.ConsecutiveLowLoad = int(scalingState.ConsecutiveLowLoad)
+
+		// without them target tracking waits out a whole stabilization window before scaling down
+		if err := json.Unmarshal(scalingState.Recommendations, &state.Recommendations); err != nil {
+			a.Logger.Error("error decoding replica recommendations", "err", err, "service-id", scalingState.ServiceID)
+		}
+
+		a.ServiceStateCache.ServiceStates[serviceStateKey(scalingState.ServiceID, scalingState.Region)] = state
+	}
+
+	for _, metricSample := range metricSamples {
+		key := serviceStateKey(metricSample.ServiceID, metricSample.Region)
+
+		state, ok := a.ServiceStateCache.ServiceStates[key]
+		if !ok {
+			state = a.newServiceState()
+			a.ServiceStateCache.ServiceStates[key] = state
+		}
+
+		a.appendMetricSample(state, metricSample.CpuPercent, metricSample.MemoryPercent, time.Unix(metricSample.SampledAt, 0))
+	}
+
+	a.Logger.Info("loaded autoscaler state", "services", len(a.ServiceStateCache.ServiceStates))
+
+	return nil
+}
+
+func (a *AutoscaleService) getServiceState(serviceId string, region string) *types.ServiceState {
+	a.ServiceStateCache.Mutex.Lock()
+	defer a.ServiceStateCache.Mutex.Unlock()
+
+	key := serviceStateKey(serviceId, region)
+
+	state, ok := a.ServiceStateCache.ServiceStates[key]
+	if !ok {
+		state = a.newServiceState()
+		a.ServiceStateCache.ServiceStates[key] = state
+	}
+
+	return state
+}
+
+// serviceStateKey keeps each managed region's history and cooldowns apart. Services
+// without region config use the service id on its own, as they did before regions
+func serviceStateKey(serviceId string, region string) string {
+	if region == "" {
+		return serviceId
+	}
+
+	return serviceId + "/" + region
+}
+
+// saveServiceState must be called with the state's mutex held
+func (a *AutoscaleService) saveServiceState(serviceId string, region string, state *types.ServiceState, cpuPercent float64, memPercent float64, replicas int, sampledAt time.Time) error {
+	err := a.Queries.CreateServiceMetricSample(a.Context, repositories.CreateServiceMetricSampleParams{
+		ServiceID:     serviceId,
+		CpuPercent:    cpuPercent,
+		MemoryPercent: memPercent,
+		SampledAt:     sampledAt.Unix(),
+		Region:        region,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving metric sample: %w", err)
+	}
+
+	if err := a.recordMetricRollup(serviceId, region, cpuPercent, memPercent, replicas, sampledAt); err != nil {
+		return err
+	}
+
+	err = a.Queries.DeleteOldServiceMetricSamples(a.Context, repositories.DeleteOldServiceMetricSamplesParams{
+		ServiceID: serviceId,
+		Region:    region,
+		Limit:     int32(a.Config.MetricHistorySize),
+	})
+	if err != nil {
+		return fmt.Errorf("error trimming metric samples: %w", err)
+	}
+
+	recommendations := state.Recommendations
+	if recommendations == nil {
+		recommendations = []types.ReplicaRecommendation{}
+	}
+
+	recommendationBytes, err := json.Marshal(recommendations)
+	if err != nil {
+		return fmt.Errorf("error encoding replica recommendations: %w", err)
+	}
+
+	err = a.Queries.UpsertServiceScalingState(a.Context, repositories.UpsertServiceScalingStateParams{
+		ServiceID:           serviceId,
+		LastUpscaleAt:       toUnix(state.LastUpscaleTime),
+		LastDownscaleAt:     toUnix(state.LastDownscaleTime),
+		ConsecutiveHighLoad: int32(state.ConsecutiveHighLoad),
+		ConsecutiveLowLoad:  int32(state.ConsecutiveLowLoad),
+		UpdatedAt:           time.Now().Unix(),
+		Region:              region,
+		Recommendations:     recommendationBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving scaling state: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AutoscaleService) newServiceState() *types.ServiceState {
+	return &types.ServiceState{
+		History: types.MetricHistory{
+			CPU:    make([]float64, 0, a.Config.MetricHistorySize),
+			Memory: make([]float64, 0, a.Config.MetricHistorySize),
+			Times:  make([]time.Time, 0, a.Config.MetricHistorySize),
+		},
+	}
+}
+
+func (a *AutoscaleService) appendMetricSample(state *types.ServiceState, cpuPercent float64, memPercent float64, sampledAt time.Time) {
+	state.History.CPU = append(state.History.CPU, cpuPercent)
+	state.History.Memory = append(state.History.Memory, memPercent)
+	state.History.Times = append(state.History.Times, sampledAt)
+
+	if overflow := len(state.History.CPU) - a.Config.MetricHistorySize; overflow > 0 {
+		state.History.CPU = state.History.CPU[overflow:]
+		state.History.Memory = state.History.Memory[overflow:]
+		state.History.Times = state.History.Times[overflow:]
+	}
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+func fromUnix(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
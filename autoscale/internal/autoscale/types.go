@@ -14,61 +14,317 @@ const (
 	defaultDownscaleCooldown        = "2m"
 	defaultMinReplicaCount          = 1
 	defaultMaxReplicaCount          = 10
+	defaultMode                     = serviceModeActive
+	defaultPolicy                   = policyHeuristic
+	defaultTargetCpuUtilization     = 0.60
+	defaultTargetMemoryUtilization  = 0.70
+	defaultMaxScaleUpStep           = 4
+	defaultMaxScaleDownStep         = 1
+	defaultScaleDownStabilization   = "5m"
+	defaultSeasonalPeriod           = seasonalPeriodDaily
+	defaultPredictiveLeadTime       = "15m"
+)
+
+const (
+	policyHeuristic      = "heuristic"
+	policyTargetTracking = "target-tracking"
+	policyPredictive     = "predictive"
+)
+
+const (
+	seasonalPeriodDaily  = "daily"
+	seasonalPeriodWeekly = "weekly"
+)
+
+const (
+	serviceModeActive = "active"
+	serviceModeShadow = "shadow"
+)
+
+const (
+	scalingEventStatusPending = "pending"
+	scalingEventStatusApplied = "applied"
+	scalingEventStatusFailed  = "failed"
+	scalingEventStatusSkipped = "skipped"
+	scalingEventStatusNoOp    = "no-op"
+	scalingEventStatusShadow  = "shadow"
 )
 
 type UpsertServiceRequest struct {
-	ServiceId                       *string  `json:"service_id,omitempty"`
-	JobName                         *string  `json:"job_name,omitempty"`
-	Enabled                         *bool    `json:"enabled,omitempty"`
-	RailwayMemoryUpscaleThreshold   *float64 `json:"railway_memory_upscale_threshold,omitempty"`
-	RailwayCPUUpscaleThreshold      *float64 `json:"railway_cpu_upscale_threshold,omitempty"`
-	RailwayMemoryDownscaleThreshold *float64 `json:"railway_memory_downscale_threshold,omitempty"`
-	RailwayCPUDownscaleThreshold    *float64 `json:"railway_cpu_downscale_threshold,omitempty"`
-	UpscaleCooldown                 *string  `json:"upscale_cooldown,omitempty"`
-	DownscaleCooldown               *string  `json:"downscale_cooldown,omitempty"`
-	MinReplicaCount                 *int     `json:"min_replica_count,omitempty"`
-	MaxReplicaCount                 *int     `json:"max_replica_count,omitempty"`
+	ServiceId                       *string  `json:"service_id,omitempty" yaml:"service_id,omitempty"`
+	JobName                         *string  `json:"job_name,omitempty" yaml:"job_name,omitempty"`
+	Enabled                         *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	RailwayMemoryUpscaleThreshold   *float64 `json:"railway_memory_upscale_threshold,omitempty" yaml:"railway_memory_upscale_threshold,omitempty"`
+	RailwayCPUUpscaleThreshold      *float64 `json:"railway_cpu_upscale_threshold,omitempty" yaml:"railway_cpu_upscale_threshold,omitempty"`
+	RailwayMemoryDownscaleThreshold *float64 `json:"railway_memory_downscale_threshold,omitempty" yaml:"railway_memory_downscale_threshold,omitempty"`
+	RailwayCPUDownscaleThreshold    *float64 `json:"railway_cpu_downscale_threshold,omitempty" yaml:"railway_cpu_downscale_threshold,omitempty"`
+	UpscaleCooldown                 *string  `json:"upscale_cooldown,omitempty" yaml:"upscale_cooldown,omitempty"`
+	DownscaleCooldown               *string  `json:"downscale_cooldown,omitempty" yaml:"downscale_cooldown,omitempty"`
+	MinReplicaCount                 *int     `json:"min_replica_count,omitempty" yaml:"min_replica_count,omitempty"`
+	MaxReplicaCount                 *int     `json:"max_replica_count,omitempty" yaml:"max_replica_count,omitempty"`
+	Mode                            *string  `json:"mode,omitempty" yaml:"mode,omitempty"`
+	ProjectId                       *string  `json:"project_id,omitempty" yaml:"project_id,omitempty"`
+	EnvironmentId                   *string  `json:"environment_id,omitempty" yaml:"environment_id,omitempty"`
+	ReplicaGroup                    *string  `json:"replica_group,omitempty" yaml:"replica_group,omitempty"`
+	Priority                        *int     `json:"priority,omitempty" yaml:"priority,omitempty"`
+	ReplicaHourlyCost               *float64 `json:"replica_hourly_cost,omitempty" yaml:"replica_hourly_cost,omitempty"`
+	ScalingPolicyRequest            `yaml:",inline"`
+}
+
+// ScalingPolicyRequest holds the target tracking settings shared by the upsert and register requests
+type ScalingPolicyRequest struct {
+	Policy                  *string  `json:"policy,omitempty" yaml:"policy,omitempty"`
+	TargetCpuUtilization    *float64 `json:"target_cpu_utilization,omitempty" yaml:"target_cpu_utilization,omitempty"`
+	TargetMemoryUtilization *float64 `json:"target_memory_utilization,omitempty" yaml:"target_memory_utilization,omitempty"`
+	MaxScaleUpStep          *int     `json:"max_scale_up_step,omitempty" yaml:"max_scale_up_step,omitempty"`
+	MaxScaleDownStep        *int     `json:"max_scale_down_step,omitempty" yaml:"max_scale_down_step,omitempty"`
+	ScaleDownStabilization  *string  `json:"scale_down_stabilization,omitempty" yaml:"scale_down_stabilization,omitempty"`
+	SeasonalPeriod          *string  `json:"seasonal_period,omitempty" yaml:"seasonal_period,omitempty"`
+	PredictiveLeadTime      *string  `json:"predictive_lead_time,omitempty" yaml:"predictive_lead_time,omitempty"`
 }
 
 type RegisterServiceRequest struct {
-	ServiceId                       *string  `json:"service_id,omitempty"`
-	JobName                         *string  `json:"job_name,omitempty"`
-	Enabled                         *bool    `json:"enabled,omitempty"`
-	RailwayMemoryUpscaleThreshold   *float64 `json:"railway_memory_upscale_threshold,omitempty"`
-	RailwayCPUUpscaleThreshold      *float64 `json:"railway_cpu_upscale_threshold,omitempty"`
-	RailwayMemoryDownscaleThreshold *float64 `json:"railway_memory_downscale_threshold,omitempty"`
-	RailwayCPUDownscaleThreshold    *float64 `json:"railway_cpu_downscale_threshold,omitempty"`
-	UpscaleCooldown                 *string  `json:"upscale_cooldown,omitempty"`
-	DownscaleCooldown               *string  `json:"downscale_cooldown,omitempty"`
-	MinReplicaCount                 *int     `json:"min_replica_count,omitempty"`
-	MaxReplicaCount                 *int     `json:"max_replica_count,omitempty"`
+	ServiceId                       *string  `json:"service_id,omitempty" yaml:"service_id,omitempty"`
+	JobName                         *string  `json:"job_name,omitempty" yaml:"job_name,omitempty"`
+	Enabled                         *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	RailwayMemoryUpscaleThreshold   *float64 `json:"railway_memory_upscale_threshold,omitempty" yaml:"railway_memory_upscale_threshold,omitempty"`
+	RailwayCPUUpscaleThreshold      *float64 `json:"railway_cpu_upscale_threshold,omitempty" yaml:"railway_cpu_upscale_threshold,omitempty"`
+	RailwayMemoryDownscaleThreshold *float64 `json:"railway_memory_downscale_threshold,omitempty" yaml:"railway_memory_downscale_threshold,omitempty"`
+	RailwayCPUDownscaleThreshold    *float64 `json:"railway_cpu_downscale_threshold,omitempty" yaml:"railway_cpu_downscale_threshold,omitempty"`
+	UpscaleCooldown                 *string  `json:"upscale_cooldown,omitempty" yaml:"upscale_cooldown,omitempty"`
+	DownscaleCooldown               *string  `json:"downscale_cooldown,omitempty" yaml:"downscale_cooldown,omitempty"`
+	MinReplicaCount                 *int     `json:"min_replica_count,omitempty" yaml:"min_replica_count,omitempty"`
+	MaxReplicaCount                 *int     `json:"max_replica_count,omitempty" yaml:"max_replica_count,omitempty"`
+	Mode                            *string  `json:"mode,omitempty" yaml:"mode,omitempty"`
+	ProjectId                       *string  `json:"project_id,omitempty" yaml:"project_id,omitempty"`
+	EnvironmentId                   *string  `json:"environment_id,omitempty" yaml:"environment_id,omitempty"`
+	ReplicaGroup                    *string  `json:"replica_group,omitempty" yaml:"replica_group,omitempty"`
+	Priority                        *int     `json:"priority,omitempty" yaml:"priority,omitempty"`
+	ReplicaHourlyCost               *float64 `json:"replica_hourly_cost,omitempty" yaml:"replica_hourly_cost,omitempty"`
+	ScalingPolicyRequest            `yaml:",inline"`
+}
+
+// ServicesFile is every row of the services table, in the same shape as the upsert request
+type ServicesFile struct {
+	Services []UpsertServiceRequest `json:"services" yaml:"services"`
+}
+
+type ApplyServicesResponse struct {
+	DryRun    bool            `json:"dry_run"`
+	Prune     bool            `json:"prune"`
+	Creates   []ServiceChange `json:"creates"`
+	Updates   []ServiceChange `json:"updates"`
+	Deletes   []ServiceChange `json:"deletes"`
+	Unchanged int             `json:"unchanged"`
+}
+
+// ServiceChange lists the fields that differ from the service's current config. A created
+// service lists every field, and a deleted one none but the rows deleted along with it
+type ServiceChange struct {
+	ServiceId string                                  `json:"service_id"`
+	Fields    []FieldChange                           `json:"fields,omitempty"`
+	Cascades  *repositories.CountServiceDependentsRow `json:"cascades,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
 }
 
 type ValidService struct {
-	ServiceId string               `json:"service_id"`
-	Service   repositories.Service `json:"service"`
+	ServiceId     string               `json:"service_id"`
+	EnvironmentId string               `json:"environment_id"`
+	Service       repositories.Service `json:"service"`
 }
 
 type ListServicesResponse struct {
 	Services []ServiceContext `json:"services"`
+	// EstimatedHourlyCost is the sum over the listed services
+	EstimatedHourlyCost float64 `json:"estimated_hourly_cost"`
 }
 
 type ServiceContext struct {
-	ServiceId                string  `json:"service_id"`
-	ProjectId                string  `json:"project_id"`
-	JobName                  string  `json:"job_name"`
-	ServiceName              string  `json:"service_name"`
-	EnvironmentName          string  `json:"environment_name"`
-	EnvironmentId            string  `json:"environment_id"`
-	Replicas                 int     `json:"replicas"`
-	MinReplicas              int     `json:"min_replicas"`
-	MaxReplicas              int     `json:"max_replicas"`
-	CpuUpscaleThreshold      float64 `json:"cpu_upscale_threshold"`
-	MemoryUpscaleThreshold   float64 `json:"memory_upscale_threshold"`
-	CpuDownscaleThreshold    float64 `json:"cpu_downscale_threshold"`
-	MemoryDownscaleThreshold float64 `json:"memory_downscale_threshold"`
-	UpscaleCooldown          string  `json:"upscale_cooldown"`
-	DownscaleCooldown        string  `json:"downscale_cooldown"`
-	LastScaledAt             string  `json:"last_scaled_at"`
-	Enabled                  bool    `json:"enabled"`
+	ServiceId                string          `json:"service_id"`
+	ProjectId                string          `json:"project_id"`
+	JobName                  string          `json:"job_name"`
+	ServiceName              string          `json:"service_name"`
+	EnvironmentName          string          `json:"environment_name"`
+	EnvironmentId            string          `json:"environment_id"`
+	Replicas                 int             `json:"replicas"`
+	MinReplicas              int             `json:"min_replicas"`
+	MaxReplicas              int             `json:"max_replicas"`
+	CpuUpscaleThreshold      float64         `json:"cpu_upscale_threshold"`
+	MemoryUpscaleThreshold   float64         `json:"memory_upscale_threshold"`
+	CpuDownscaleThreshold    float64         `json:"cpu_downscale_threshold"`
+	MemoryDownscaleThreshold float64         `json:"memory_downscale_threshold"`
+	UpscaleCooldown          string          `json:"upscale_cooldown"`
+	DownscaleCooldown        string          `json:"downscale_cooldown"`
+	LastScaledAt             string          `json:"last_scaled_at"`
+	Enabled                  bool            `json:"enabled"`
+	Mode                     string          `json:"mode"`
+	Policy                   string          `json:"policy"`
+	TargetCpuUtilization     float64         `json:"target_cpu_utilization"`
+	TargetMemoryUtilization  float64         `json:"target_memory_utilization"`
+	MaxScaleUpStep           int             `json:"max_scale_up_step"`
+	MaxScaleDownStep         int             `json:"max_scale_down_step"`
+	ScaleDownStabilization   string          `json:"scale_down_stabilization"`
+	SeasonalPeriod           string          `json:"seasonal_period"`
+	PredictiveLeadTime       string          `json:"predictive_lead_time"`
+	ReplicaGroup             string          `json:"replica_group"`
+	Priority                 int             `json:"priority"`
+	ReplicaHourlyCost        float64         `json:"replica_hourly_cost"`
+	EstimatedHourlyCost      float64         `json:"estimated_hourly_cost"`
+	Regions                  []RegionContext `json:"regions"`
+	// Override is set while the service's replicas are pinned by hand
+	Override *repositories.ServiceOverride `json:"override"`
+}
+
+// RegionContext is a region the service runs in. Regions that aren't managed are left
+// as they are by the autoscaler
+type RegionContext struct {
+	Region      string `json:"region"`
+	Replicas    int    `json:"replicas"`
+	Managed     bool   `json:"managed"`
+	MinReplicas int    `json:"min_replicas,omitempty"`
+	MaxReplicas int    `json:"max_replicas,omitempty"`
+}
+
+type ReplicaBudgetRequest struct {
+	MaxReplicas *int `json:"max_replicas,omitempty"`
+}
+
+// ReplicaBudgetContext is a budget and its usage. The global budget has no replica_group,
+// and a max_replicas of 0 while MAX_TOTAL_REPLICAS isn't set
+type ReplicaBudgetContext struct {
+	ReplicaGroup        string  `json:"replica_group,omitempty"`
+	MaxReplicas         int     `json:"max_replicas"`
+	Replicas            int     `json:"replicas"`
+	EstimatedHourlyCost float64 `json:"estimated_hourly_cost"`
+}
+
+type ListReplicaBudgetsResponse struct {
+	Global ReplicaBudgetContext   `json:"global"`
+	Groups []ReplicaBudgetContext `json:"groups"`
+}
+
+type ServiceRegionRequest struct {
+	MinReplicaCount *int `json:"min_replica_count,omitempty"`
+	MaxReplicaCount *int `json:"max_replica_count,omitempty"`
+}
+
+// ServiceOverrideRequest takes either a duration or an expires_at unix timestamp (s)
+type ServiceOverrideRequest struct {
+	ReplicaCount *int   `json:"replica_count,omitempty"`
+	Duration     string `json:"duration,omitempty"`
+	ExpiresAt    *int64 `json:"expires_at,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+type ListServiceRegionsResponse struct {
+	Regions []repositories.ServiceRegion `json:"regions"`
+}
+
+type ListServiceEventsResponse struct {
+	Events []repositories.ScalingEvent `json:"events"`
+}
+
+type ShadowReportResponse struct {
+	ServiceId string              `json:"service_id"`
+	Mode      string              `json:"mode"`
+	Since     int64               `json:"since"`
+	Summary   ShadowReportSummary `json:"summary"`
+	Points    []ShadowReportPoint `json:"points"`
+}
+
+type ShadowReportSummary struct {
+	Evaluations            int     `json:"evaluations"`
+	Upscales               int     `json:"upscales"`
+	Downscales             int     `json:"downscales"`
+	DivergedEvaluations    int     `json:"diverged_evaluations"`
+	MaxReplicaDifference   int     `json:"max_replica_difference"`
+	MeanAbsoluteDifference float64 `json:"mean_absolute_difference"`
+}
+
+type ShadowReportPoint struct {
+	Timestamp      int64  `json:"timestamp"`
+	Region         string `json:"region"`
+	ActualReplicas int    `json:"actual_replicas"`
+	ShadowReplicas int    `json:"shadow_replicas"`
+	Reason         string `json:"reason"`
+}
+
+type ForecastResponse struct {
+	ServiceId          string           `json:"service_id"`
+	Policy             string           `json:"policy"`
+	SeasonalPeriod     string           `json:"seasonal_period"`
+	PredictiveLeadTime string           `json:"predictive_lead_time"`
+	Regions            []RegionForecast `json:"regions"`
+}
+
+type RegionForecast struct {
+	Region string          `json:"region"`
+	Points []ForecastPoint `json:"points"`
+}
+
+// ForecastPoint is the forecast average demand of the bucket starting at Timestamp, from
+// Seasons previous days or weeks, and the replicas that meet it at the service's targets
+type ForecastPoint struct {
+	Timestamp    int64   `json:"timestamp"`
+	CpuDemand    float64 `json:"cpu_demand"`
+	MemoryDemand float64 `json:"memory_demand"`
+	Replicas     int     `json:"replicas"`
+	Seasons      int     `json:"seasons"`
+}
+
+type ServiceScheduleRequest struct {
+	Name               string `json:"name"`
+	CronExpression     string `json:"cron_expression"`
+	Duration           string `json:"duration"`
+	Timezone           string `json:"timezone,omitempty"`
+	MinReplicaCount    *int   `json:"min_replica_count,omitempty"`
+	MaxReplicaCount    *int   `json:"max_replica_count,omitempty"`
+	PinnedReplicaCount *int   `json:"pinned_replica_count,omitempty"`
+	Enabled            *bool  `json:"enabled,omitempty"`
+}
+
+type ServiceScheduleResponse struct {
+	ID                 int64  `json:"id"`
+	ServiceId          string `json:"service_id"`
+	Name               string `json:"name"`
+	CronExpression     string `json:"cron_expression"`
+	Duration           string `json:"duration"`
+	Timezone           string `json:"timezone"`
+	MinReplicaCount    *int   `json:"min_replica_count"`
+	MaxReplicaCount    *int   `json:"max_replica_count"`
+	PinnedReplicaCount *int   `json:"pinned_replica_count"`
+	Enabled            bool   `json:"enabled"`
+	Active             bool   `json:"active"`
+	NextStart          int64  `json:"next_start"`
+	CreatedAt          int64  `json:"created_at"`
+	UpdatedAt          int64  `json:"updated_at"`
+}
+
+type ListServiceSchedulesResponse struct {
+	Schedules []ServiceScheduleResponse `json:"schedules"`
+}
+
+type ServiceMetricRequest struct {
+	Provider    string  `json:"provider"`
+	Query       string  `json:"query"`
+	TargetValue float64 `json:"target_value"`
+}
+
+type ListServiceMetricsResponse struct {
+	Metrics []repositories.ServiceMetric `json:"metrics"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
 }
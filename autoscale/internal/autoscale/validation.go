@@ -2,16 +2,21 @@ package autoscale
 
 import (
 	"database/sql"
-	"log/slog"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
 )
 
-func populateUpsertServiceParams(upsertServiceRequest *UpsertServiceRequest) repositories.UpdateServiceParams {
-	slog.Info("populating upsert service params", "request", upsertServiceRequest)
-
-	params := repositories.UpdateServiceParams{
-		ServiceID:                       *upsertServiceRequest.ServiceId,
+// defaultServiceConfig is the config a service starts from when it's first registered
+func defaultServiceConfig(serviceId string) repositories.Service {
+	return repositories.Service{
+		ServiceID:                       serviceId,
+		JobName:                         sql.NullString{Valid: false},
 		Enabled:                         defaultEnabled,
 		RailwayMemoryUpscaleThreshold:   defaultMemoryUpscaleThreshold,
 		RailwayCpuUpscaleThreshold:      defaultCpuUpscaleThreshold,
@@ -21,139 +26,371 @@ func populateUpsertServiceParams(upsertServiceRequest *UpsertServiceRequest) rep
 		DownscaleCooldown:               defaultDownscaleCooldown,
 		MinReplicaCount:                 int32(defaultMinReplicaCount),
 		MaxReplicaCount:                 int32(defaultMaxReplicaCount),
+		Mode:                            defaultMode,
+		Policy:                          defaultPolicy,
+		TargetCpuUtilization:            defaultTargetCpuUtilization,
+		TargetMemoryUtilization:         defaultTargetMemoryUtilization,
+		MaxScaleUpStep:                  int32(defaultMaxScaleUpStep),
+		MaxScaleDownStep:                int32(defaultMaxScaleDownStep),
+		ScaleDownStabilization:          defaultScaleDownStabilization,
+		SeasonalPeriod:                  defaultSeasonalPeriod,
+		PredictiveLeadTime:              defaultPredictiveLeadTime,
+	}
+}
+
+// mergeServiceConfig applies the fields the request sets on top of base, which is either
+// the existing service or the defaults, so a partial upsert leaves every other field alone
+func mergeServiceConfig(base repositories.Service, req *UpsertServiceRequest) repositories.Service {
+	service := base
+
+	// an empty job name clears it
+	if req.JobName != nil {
+		service.JobName = sql.NullString{String: *req.JobName, Valid: *req.JobName != ""}
+	}
+
+	if req.Enabled != nil {
+		service.Enabled = *req.Enabled
+	}
+
+	if req.RailwayMemoryUpscaleThreshold != nil {
+		service.RailwayMemoryUpscaleThreshold = *req.RailwayMemoryUpscaleThreshold
+	}
+
+	if req.RailwayCPUUpscaleThreshold != nil {
+		service.RailwayCpuUpscaleThreshold = *req.RailwayCPUUpscaleThreshold
+	}
+
+	if req.RailwayMemoryDownscaleThreshold != nil {
+		service.RailwayMemoryDownscaleThreshold = *req.RailwayMemoryDownscaleThreshold
+	}
+
+	if req.RailwayCPUDownscaleThreshold != nil {
+		service.RailwayCpuDownscaleThreshold = *req.RailwayCPUDownscaleThreshold
+	}
+
+	if req.UpscaleCooldown != nil {
+		service.UpscaleCooldown = *req.UpscaleCooldown
+	}
+
+	if req.DownscaleCooldown != nil {
+		service.DownscaleCooldown = *req.DownscaleCooldown
+	}
+
+	if req.MinReplicaCount != nil {
+		service.MinReplicaCount = int32(*req.MinReplicaCount)
+	}
+
+	if req.MaxReplicaCount != nil {
+		service.MaxReplicaCount = int32(*req.MaxReplicaCount)
+	}
+
+	if req.Mode != nil {
+		service.Mode = *req.Mode
 	}
 
-	if upsertServiceRequest.JobName != nil {
-		params.JobName = sql.NullString{String: *upsertServiceRequest.JobName, Valid: true}
-	} else {
-		params.JobName = sql.NullString{Valid: false}
+	if req.Policy != nil {
+		service.Policy = *req.Policy
 	}
 
-	if upsertServiceRequest.Enabled != nil {
-		params.Enabled = *upsertServiceRequest.Enabled
+	if req.TargetCpuUtilization != nil {
+		service.TargetCpuUtilization = *req.TargetCpuUtilization
 	}
 
-	if upsertServiceRequest.RailwayMemoryUpscaleThreshold != nil {
-		params.RailwayMemoryUpscaleThreshold = *upsertServiceRequest.RailwayMemoryUpscaleThreshold
+	if req.TargetMemoryUtilization != nil {
+		service.TargetMemoryUtilization = *req.TargetMemoryUtilization
 	}
 
-	if upsertServiceRequest.RailwayCPUUpscaleThreshold != nil {
-		params.RailwayCpuUpscaleThreshold = *upsertServiceRequest.RailwayCPUUpscaleThreshold
+	if req.MaxScaleUpStep != nil {
+		service.MaxScaleUpStep = int32(*req.MaxScaleUpStep)
 	}
 
-	if upsertServiceRequest.RailwayMemoryDownscaleThreshold != nil {
-		params.RailwayMemoryDownscaleThreshold = *upsertServiceRequest.RailwayMemoryDownscaleThreshold
+	if req.MaxScaleDownStep != nil {
+		service.MaxScaleDownStep = int32(*req.MaxScaleDownStep)
 	}
 
-	if upsertServiceRequest.RailwayCPUDownscaleThreshold != nil {
-		params.RailwayCpuDownscaleThreshold = *upsertServiceRequest.RailwayCPUDownscaleThreshold
+	if req.ScaleDownStabilization != nil {
+		service.ScaleDownStabilization = *req.ScaleDownStabilization
 	}
 
-	if upsertServiceRequest.UpscaleCooldown != nil {
-		params.UpscaleCooldown = *upsertServiceRequest.UpscaleCooldown
+	if req.SeasonalPeriod != nil {
+		service.SeasonalPeriod = *req.SeasonalPeriod
 	}
 
-	if upsertServiceRequest.DownscaleCooldown != nil {
-		params.DownscaleCooldown = *upsertServiceRequest.DownscaleCooldown
+	if req.PredictiveLeadTime != nil {
+		service.PredictiveLeadTime = *req.PredictiveLeadTime
 	}
 
-	if upsertServiceRequest.MinReplicaCount != nil {
-		params.MinReplicaCount = int32(*upsertServiceRequest.MinReplicaCount)
+	if req.ProjectId != nil {
+		service.ProjectID = *req.ProjectId
 	}
 
-	if upsertServiceRequest.MaxReplicaCount != nil {
-		params.MaxReplicaCount = int32(*upsertServiceRequest.MaxReplicaCount)
+	if req.EnvironmentId != nil {
+		service.EnvironmentID = *req.EnvironmentId
 	}
 
-	return params
+	if req.ReplicaGroup != nil {
+		service.ReplicaGroup = *req.ReplicaGroup
+	}
+
+	if req.Priority != nil {
+		service.Priority = int32(*req.Priority)
+	}
+
+	if req.ReplicaHourlyCost != nil {
+		service.ReplicaHourlyCost = *req.ReplicaHourlyCost
+	}
+
+	return service
 }
 
-func populateRegisterServiceParams(req *RegisterServiceRequest) repositories.CreateServiceParams {
-	params := repositories.CreateServiceParams{
-		ServiceID:                       *req.ServiceId,
-		Enabled:                         defaultEnabled,
-		RailwayMemoryUpscaleThreshold:   defaultMemoryUpscaleThreshold,
-		RailwayCpuUpscaleThreshold:      defaultCpuUpscaleThreshold,
-		RailwayMemoryDownscaleThreshold: defaultMemoryDownscaleThreshold,
-		RailwayCpuDownscaleThreshold:    defaultCpuDownscaleThreshold,
-		UpscaleCooldown:                 defaultUpscaleCooldown,
-		DownscaleCooldown:               defaultDownscaleCooldown,
-		MinReplicaCount:                 int32(defaultMinReplicaCount),
-		MaxReplicaCount:                 int32(defaultMaxReplicaCount),
+// upsertServiceParams is the only place a service is turned into the params it's saved
+// with. Both are generated from the same column list
+func upsertServiceParams(service repositories.Service) repositories.UpsertServiceParams {
+	return repositories.UpsertServiceParams(service)
+}
+
+// validateServiceConfig checks the merged config rather than the request, so a partial
+// upsert can't leave the service in a state the decision code can't use
+func validateServiceConfig(config repositories.Service) []FieldError {
+	fieldErrors := []FieldError{}
+
+	if config.Mode != serviceModeActive && config.Mode != serviceModeShadow {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/mode", Message: "must be one of active or shadow"})
 	}
 
-	if req.JobName != nil {
-		params.JobName = sql.NullString{String: *req.JobName, Valid: true}
-	} else {
-		params.JobName = sql.NullString{Valid: false}
+	if config.Policy != policyHeuristic && config.Policy != policyTargetTracking && config.Policy != policyPredictive {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/policy", Message: "must be one of heuristic, target-tracking or predictive"})
 	}
 
-	if req.Enabled != nil {
-		params.Enabled = *req.Enabled
+	if config.SeasonalPeriod != seasonalPeriodDaily && config.SeasonalPeriod != seasonalPeriodWeekly {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/seasonal_period", Message: "must be one of daily or weekly"})
 	}
 
-	if req.RailwayMemoryUpscaleThreshold != nil {
-		params.RailwayMemoryUpscaleThreshold = *req.RailwayMemoryUpscaleThreshold
+	if config.MinReplicaCount < 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/min_replica_count", Message: "can't be negative"})
+	}
+	if config.MaxReplicaCount < 1 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/max_replica_count", Message: "must be at least 1"})
+	}
+	if config.MinReplicaCount > config.MaxReplicaCount {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   "/min_replica_count",
+			Message: fmt.Sprintf("%d can't be greater than max_replica_count %d", config.MinReplicaCount, config.MaxReplicaCount),
+		})
 	}
 
-	if req.RailwayCPUUpscaleThreshold != nil {
-		params.RailwayCpuUpscaleThreshold = *req.RailwayCPUUpscaleThreshold
+	thresholds := []struct {
+		field string
+		value float64
+	}{
+		{"railway_cpu_upscale_threshold", config.RailwayCpuUpscaleThreshold},
+		{"railway_memory_upscale_threshold", config.RailwayMemoryUpscaleThreshold},
+		{"railway_cpu_downscale_threshold", config.RailwayCpuDownscaleThreshold},
+		{"railway_memory_downscale_threshold", config.RailwayMemoryDownscaleThreshold},
+	}
+	for _, threshold := range thresholds {
+		if threshold.value < 0 || threshold.value > 1 {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/" + threshold.field, Message: "must be between 0 and 1"})
+		}
 	}
 
-	if req.RailwayMemoryDownscaleThreshold != nil {
-		params.RailwayMemoryDownscaleThreshold = *req.RailwayMemoryDownscaleThreshold
+	if config.RailwayCpuDownscaleThreshold > config.RailwayCpuUpscaleThreshold {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   "/railway_cpu_downscale_threshold",
+			Message: fmt.Sprintf("%g can't be above railway_cpu_upscale_threshold %g", config.RailwayCpuDownscaleThreshold, config.RailwayCpuUpscaleThreshold),
+		})
+	}
+	if config.RailwayMemoryDownscaleThreshold > config.RailwayMemoryUpscaleThreshold {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   "/railway_memory_downscale_threshold",
+			Message: fmt.Sprintf("%g can't be above railway_memory_upscale_threshold %g", config.RailwayMemoryDownscaleThreshold, config.RailwayMemoryUpscaleThreshold),
+		})
 	}
 
-	if req.RailwayCPUDownscaleThreshold != nil {
-		params.RailwayCpuDownscaleThreshold = *req.RailwayCPUDownscaleThreshold
+	durations := []struct {
+		field string
+		value string
+	}{
+		{"upscale_cooldown", config.UpscaleCooldown},
+		{"downscale_cooldown", config.DownscaleCooldown},
+		{"scale_down_stabilization", config.ScaleDownStabilization},
+		{"predictive_lead_time", config.PredictiveLeadTime},
+	}
+	for _, duration := range durations {
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil || parsed < 0 {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/" + duration.field, Message: "must be a duration, e.g. 5m"})
+		}
 	}
 
-	if req.UpscaleCooldown != nil {
-		params.UpscaleCooldown = *req.UpscaleCooldown
+	if config.TargetCpuUtilization <= 0 || config.TargetCpuUtilization > 1 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/target_cpu_utilization", Message: "must be between 0 and 1"})
+	}
+	if config.TargetMemoryUtilization <= 0 || config.TargetMemoryUtilization > 1 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/target_memory_utilization", Message: "must be between 0 and 1"})
+	}
+	if config.MaxScaleUpStep < 1 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/max_scale_up_step", Message: "must be at least 1"})
+	}
+	if config.MaxScaleDownStep < 1 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/max_scale_down_step", Message: "must be at least 1"})
 	}
 
-	if req.DownscaleCooldown != nil {
-		params.DownscaleCooldown = *req.DownscaleCooldown
+	if config.ReplicaHourlyCost < 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/replica_hourly_cost", Message: "can't be negative"})
 	}
 
-	if req.MinReplicaCount != nil {
-		params.MinReplicaCount = int32(*req.MinReplicaCount)
+	return fieldErrors
+}
+
+// validateEnvironmentChange stops an enabled service from being moved to another environment.
+// Railway shares a service's id across every environment in its project, and services are
+// keyed by that id alone, so staging and production can only both be autoscaled from
+// separate projects
+func (a *AutoscaleService) validateEnvironmentChange(existing repositories.Service, desired repositories.Service) *FieldError {
+	if !existing.Enabled {
+		return nil
 	}
 
-	if req.MaxReplicaCount != nil {
-		params.MaxReplicaCount = int32(*req.MaxReplicaCount)
+	existingEnvironmentId := a.serviceEnvironmentId(existing)
+	if a.serviceEnvironmentId(desired) == existingEnvironmentId {
+		return nil
+	}
+
+	return &FieldError{
+		Field:   "/environment_id",
+		Message: fmt.Sprintf("service is already scaled in environment %s, unregister it before moving it to another environment", existingEnvironmentId),
+	}
+}
+
+// requestFieldError turns a json decoding error into a field error where it can
+func requestFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return FieldError{
+			Field:   "/" + strings.ReplaceAll(typeErr.Field, ".", "/"),
+			Message: fmt.Sprintf("can't be a %s", typeErr.Value),
+		}
+	}
+
+	return FieldError{Field: "", Message: err.Error()}
+}
+
+func writeValidationErrors(w http.ResponseWriter, message string, fieldErrors []FieldError) error {
+	responseBytes, err := json.Marshal(ValidationErrorResponse{
+		Error:  message,
+		Fields: fieldErrors,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding validation errors: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+// validateServiceScheduleRequest returns a message describing the first problem with the
+// request, or an empty string if it's valid
+func validateServiceScheduleRequest(req *ServiceScheduleRequest) string {
+	if req.Name == "" {
+		return "Name is required"
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	if _, err := parseServiceSchedule(req.CronExpression, req.Duration, req.Timezone); err != nil {
+		return err.Error()
+	}
+
+	if req.PinnedReplicaCount != nil {
+		if req.MinReplicaCount != nil || req.MaxReplicaCount != nil {
+			return "pinned_replica_count can't be combined with min_replica_count or max_replica_count"
+		}
+		if *req.PinnedReplicaCount < 0 {
+			return "pinned_replica_count can't be negative"
+		}
+		return ""
+	}
+
+	if req.MinReplicaCount == nil && req.MaxReplicaCount == nil {
+		return "One of min_replica_count, max_replica_count or pinned_replica_count is required"
+	}
+	if req.MinReplicaCount != nil && *req.MinReplicaCount < 0 {
+		return "min_replica_count can't be negative"
+	}
+	if req.MaxReplicaCount != nil && *req.MaxReplicaCount < 1 {
+		return "max_replica_count must be at least 1"
+	}
+	if req.MinReplicaCount != nil && req.MaxReplicaCount != nil && *req.MinReplicaCount > *req.MaxReplicaCount {
+		return "min_replica_count can't be greater than max_replica_count"
 	}
 
-	return params
+	return ""
 }
 
-func applyDefaultsForCreate(params repositories.CreateServiceParams) repositories.CreateServiceParams {
-	if !params.Enabled {
-		params.Enabled = true
+// validateServiceOverrideRequest resolves a duration into ExpiresAt
+func validateServiceOverrideRequest(req *ServiceOverrideRequest, now time.Time) string {
+	if req.ReplicaCount == nil {
+		return "replica_count is required"
 	}
-	if params.RailwayMemoryUpscaleThreshold == 0 {
-		params.RailwayMemoryUpscaleThreshold = defaultMemoryUpscaleThreshold
+	if *req.ReplicaCount < 0 {
+		return "replica_count can't be negative"
 	}
-	if params.RailwayCpuUpscaleThreshold == 0 {
-		params.RailwayCpuUpscaleThreshold = defaultCpuUpscaleThreshold
+
+	if (req.Duration == "") == (req.ExpiresAt == nil) {
+		return "One of duration or expires_at is required"
+	}
+
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			return "duration must be a positive duration, e.g. 2h"
+		}
+
+		expiresAt := now.Add(duration).Unix()
+		req.ExpiresAt = &expiresAt
 	}
-	if params.RailwayMemoryDownscaleThreshold == 0 {
-		params.RailwayMemoryDownscaleThreshold = defaultMemoryDownscaleThreshold
+
+	if *req.ExpiresAt <= now.Unix() {
+		return "expires_at must be in the future"
+	}
+
+	return ""
+}
+
+func validateServiceRegionRequest(req *ServiceRegionRequest) string {
+	if req.MinReplicaCount == nil || req.MaxReplicaCount == nil {
+		return "min_replica_count and max_replica_count are required"
 	}
-	if params.RailwayCpuDownscaleThreshold == 0 {
-		params.RailwayCpuDownscaleThreshold = defaultCpuDownscaleThreshold
+	if *req.MinReplicaCount < 0 {
+		return "min_replica_count can't be negative"
 	}
-	if params.UpscaleCooldown == "" {
-		params.UpscaleCooldown = defaultUpscaleCooldown
+	if *req.MaxReplicaCount < 1 {
+		return "max_replica_count must be at least 1"
 	}
-	if params.DownscaleCooldown == "" {
-		params.DownscaleCooldown = defaultDownscaleCooldown
+	if *req.MinReplicaCount > *req.MaxReplicaCount {
+		return "min_replica_count can't be greater than max_replica_count"
 	}
-	if params.MinReplicaCount == 0 {
-		params.MinReplicaCount = defaultMinReplicaCount
+
+	return ""
+}
+
+func toNullInt32(value *int) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{Valid: false}
 	}
-	if params.MaxReplicaCount == 0 {
-		params.MaxReplicaCount = defaultMaxReplicaCount
+	return sql.NullInt32{Int32: int32(*value), Valid: true}
+}
+
+func fromNullInt32(value sql.NullInt32) *int {
+	if !value.Valid {
+		return nil
 	}
-	return params
+	v := int(value.Int32)
+	return &v
 }
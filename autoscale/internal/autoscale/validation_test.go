@@ -0,0 +1,136 @@
+package autoscale
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+func TestValidateServiceConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(config *repositories.Service)
+		fields []string
+	}{
+		{name: "defaults", change: func(config *repositories.Service) {}},
+		{
+			name: "unknown mode, policy and seasonal period",
+			change: func(config *repositories.Service) {
+				config.Mode, config.Policy, config.SeasonalPeriod = "dry-run", "magic", "yearly"
+			},
+			fields: []string{"/mode", "/policy", "/seasonal_period"},
+		},
+		{
+			name:   "min above max",
+			change: func(config *repositories.Service) { config.MinReplicaCount, config.MaxReplicaCount = 5, 2 },
+			fields: []string{"/min_replica_count"},
+		},
+		{
+			name:   "no replicas allowed",
+			change: func(config *repositories.Service) { config.MinReplicaCount, config.MaxReplicaCount = -1, 0 },
+			fields: []string{"/max_replica_count", "/min_replica_count"},
+		},
+		{
+			name:   "threshold out of range",
+			change: func(config *repositories.Service) { config.RailwayCpuUpscaleThreshold = 80 },
+			fields: []string{"/railway_cpu_upscale_threshold"},
+		},
+		{
+			name:   "downscale threshold above upscale threshold",
+			change: func(config *repositories.Service) { config.RailwayMemoryDownscaleThreshold = 0.9 },
+			fields: []string{"/railway_memory_downscale_threshold"},
+		},
+		{
+			name: "invalid and negative durations",
+			change: func(config *repositories.Service) {
+				config.UpscaleCooldown, config.PredictiveLeadTime = "a minute", "-5m"
+			},
+			fields: []string{"/predictive_lead_time", "/upscale_cooldown"},
+		},
+		{
+			name: "target tracking settings",
+			change: func(config *repositories.Service) {
+				config.TargetCpuUtilization, config.TargetMemoryUtilization = 0, 1.5
+				config.MaxScaleUpStep, config.MaxScaleDownStep = 0, 0
+			},
+			fields: []string{"/max_scale_down_step", "/max_scale_up_step", "/target_cpu_utilization", "/target_memory_utilization"},
+		},
+		{
+			name:   "negative replica cost",
+			change: func(config *repositories.Service) { config.ReplicaHourlyCost = -0.01 },
+			fields: []string{"/replica_hourly_cost"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := defaultServiceConfig("svc")
+			test.change(&config)
+
+			var fields []string
+			for _, fieldError := range validateServiceConfig(config) {
+				fields = append(fields, fieldError.Field)
+			}
+			slices.Sort(fields)
+
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("got field errors %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestMergeServiceConfigKeepsUnsetFields(t *testing.T) {
+	existing := defaultServiceConfig("svc")
+	existing.MinReplicaCount = 3
+	existing.MaxReplicaCount = 8
+	existing.Mode = serviceModeShadow
+	existing.Policy = policyTargetTracking
+
+	maxReplicas := 6
+
+	merged := mergeServiceConfig(existing, &UpsertServiceRequest{MaxReplicaCount: &maxReplicas})
+
+	want := existing
+	want.MaxReplicaCount = 6
+
+	if merged != want {
+		t.Errorf("got %+v, want %+v", merged, want)
+	}
+}
+
+func TestValidateEnvironmentChange(t *testing.T) {
+	a := &AutoscaleService{Config: &types.Config{RailwayEnvironmentId: "production"}}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		existing string
+		desired  string
+		wantErr  bool
+	}{
+		{name: "unchanged", enabled: true, existing: "production", desired: "production"},
+		{name: "default is the configured environment", enabled: true, existing: "", desired: "production"},
+		{name: "moved while enabled", enabled: true, existing: "production", desired: "staging", wantErr: true},
+		{name: "moved off the default", enabled: true, existing: "", desired: "staging", wantErr: true},
+		{name: "moved after unregistering", enabled: false, existing: "production", desired: "staging"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			existing := defaultServiceConfig("svc")
+			existing.Enabled = test.enabled
+			existing.EnvironmentID = test.existing
+
+			desired := existing
+			desired.EnvironmentID = test.desired
+
+			fieldError := a.validateEnvironmentChange(existing, desired)
+			if (fieldError != nil) != test.wantErr {
+				t.Errorf("got field error %v, want error: %v", fieldError, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// leaderLockKey is the postgres advisory lock held by the instance that runs the autoscaler
+const leaderLockKey = 1937205620
+
+// applicationNamePrefix marks the leader's connection in pg_stat_activity, so other
+// instances can tell who holds the lock
+const applicationNamePrefix = "switchyard-autoscale:"
+
+// LeaderService elects one autoscale instance to scale services. The leader holds a
+// session level advisory lock on a dedicated connection, so postgres hands the lock to
+// another instance as soon as the leader's connection goes away
+type LeaderService struct {
+	Logger *slog.Logger
+	Config *types.Config
+	DB     *sqlx.DB
+	ctx    context.Context
+
+	leader      bool
+	leaderSince time.Time
+	mutex       sync.Mutex
+}
+
+type StatusResponse struct {
+	InstanceId  string `json:"instance_id"`
+	Leader      bool   `json:"leader"`
+	LeaderId    string `json:"leader_id"`
+	LeaderSince int64  `json:"leader_since,omitempty"`
+}
+
+func NewLeaderService(logger *slog.Logger, config *types.Config, db *sqlx.DB, ctx context.Context) LeaderService {
+	return LeaderService{
+		Logger: logger,
+		Config: config,
+		DB:     db,
+		ctx:    ctx,
+	}
+}
+
+// Run calls lead whenever this instance becomes the leader. lead's context is cancelled
+// when leadership is lost, and leadership is given up if lead returns early
+func (l *LeaderService) Run(lead func(ctx context.Context)) {
+	ticker := time.NewTicker(l.Config.LeaderElectionInterval)
+	defer ticker.Stop()
+
+	for {
+		conn, err := l.acquire()
+		if err != nil {
+			l.Logger.Error("error acquiring autoscaler leadership", "err", err)
+		}
+
+		if conn != nil {
+			l.holdLeadership(conn, lead)
+		}
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquire returns the connection holding the leader lock, or nil if another instance has it
+func (l *LeaderService) acquire() (*sql.Conn, error) {
+	conn, err := l.DB.Conn(l.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error opening leader election connection: %w", err)
+	}
+
+	queries := repositories.New(conn)
+
+	if err := queries.SetApplicationName(l.ctx, applicationNamePrefix+l.Config.InstanceId); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error naming leader election connection: %w", err)
+	}
+
+	acquired, err := queries.TryLeaderLock(l.ctx, leaderLockKey)
+	if err != nil || !acquired {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (l *LeaderService) holdLeadership(conn *sql.Conn, lead func(ctx context.Context)) {
+	l.setLeader(true)
+	l.Logger.Info("became autoscaler leader", "instance-id", l.Config.InstanceId)
+
+	ctx, cancel := context.WithCancel(l.ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		lead(ctx)
+	}()
+
+	ticker := time.NewTicker(l.Config.LeaderElectionInterval)
+	defer ticker.Stop()
+
+	for held := true; held; {
+		select {
+		case <-done:
+			l.Logger.Warn("autoscaler stopped, giving up leadership")
+			held = false
+		case <-l.ctx.Done():
+			held = false
+		case <-ticker.C:
+			if err := l.ping(conn); err != nil {
+				l.Logger.Error("lost the leader election connection", "err", err)
+				held = false
+			}
+		}
+	}
+
+	cancel()
+	<-done
+
+	// discarding the connection rather than returning it to the pool ends the session,
+	// which releases the lock
+	conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+
+	l.setLeader(false)
+	l.Logger.Info("gave up autoscaler leadership", "instance-id", l.Config.InstanceId)
+}
+
+func (l *LeaderService) ping(conn *sql.Conn) error {
+	ctx, cancel := context.WithTimeout(l.ctx, l.Config.LeaderElectionInterval)
+	defer cancel()
+
+	return conn.PingContext(ctx)
+}
+
+func (l *LeaderService) setLeader(leader bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.leader = leader
+	l.leaderSince = time.Time{}
+
+	if leader {
+		l.leaderSince = time.Now()
+	}
+}
+
+func (l *LeaderService) GetStatus(w http.ResponseWriter, r *http.Request) error {
+	l.mutex.Lock()
+	status := StatusResponse{
+		InstanceId: l.Config.InstanceId,
+		Leader:     l.leader,
+	}
+
+	if l.leader {
+		status.LeaderId = l.Config.InstanceId
+		status.LeaderSince = l.leaderSince.Unix()
+	}
+	l.mutex.Unlock()
+
+	if !status.Leader {
+		holder, err := repositories.New(l.DB).GetLeaderLockHolder(r.Context(), leaderLockKey)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error fetching autoscaler leader: %w", err)
+		}
+
+		// empty while no instance holds the lock, e.g. during a failover
+		status.LeaderId = strings.TrimPrefix(holder.String, applicationNamePrefix)
+	}
+
+	responseBytes, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type MessageBusService struct {
+	Logger  *slog.Logger
+	Config  *types.Config
+	Conn    *amqp.Connection
+	Context context.Context
+}
+
+func NewMessageBusService(logger *slog.Logger, conn *amqp.Connection, config *types.Config, context context.Context) MessageBusService {
+	return MessageBusService{
+		Logger:  logger,
+		Conn:    conn,
+		Config:  config,
+		Context: context,
+	}
+}
+
+func (m *MessageBusService) SendScalingNotificationMessage(notification types.ScalingNotification) error {
+	channel, queue, err := m.declareQueueAndChannel()
+	if err != nil {
+		return err
+	}
+	defer channel.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bodyBytes, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	m.Logger.Info("publishing scaling notification to message queue", "scaling-notification", notification)
+
+	err = channel.PublishWithContext(ctx,
+		"",
+		queue.Name,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        bodyBytes,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MessageBusService) declareQueueAndChannel() (*amqp.Channel, amqp.Queue, error) {
+	channel, err := m.Conn.Channel()
+	if err != nil {
+		return nil, amqp.Queue{}, err
+	}
+
+	queue, err := channel.QueueDeclare(
+		"scaling-events",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, amqp.Queue{}, err
+	}
+
+	return channel, queue, nil
+}
@@ -0,0 +1,14 @@
+package metrics
+
+import "context"
+
+const (
+	ProviderRailway    = "railway"
+	ProviderPrometheus = "prometheus"
+)
+
+// Provider is a source of metric values for a service in an environment. Queries are
+// interpreted by the provider, e.g. a Railway measurement or a PromQL expression
+type Provider interface {
+	Query(ctx context.Context, serviceId string, environmentId string, query string) (float64, error)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const prometheusQueryTimeout = 10 * time.Second
+
+// PrometheusProvider evaluates PromQL expressions against the Prometheus HTTP API.
+// $service_id and $environment_id in a query are replaced with the Railway service and
+// environment IDs
+type PrometheusProvider struct {
+	Url    string
+	Client *http.Client
+}
+
+type prometheusResponse struct {
+	Status    string         `json:"status"`
+	Error     string         `json:"error"`
+	ErrorType string         `json:"errorType"`
+	Data      prometheusData `json:"data"`
+}
+
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type prometheusVectorSample struct {
+	Value [2]any `json:"value"`
+}
+
+func NewPrometheusProvider(prometheusUrl string) PrometheusProvider {
+	return PrometheusProvider{
+		Url:    strings.TrimSuffix(prometheusUrl, "/"),
+		Client: &http.Client{Timeout: prometheusQueryTimeout},
+	}
+}
+
+func (p *PrometheusProvider) Query(ctx context.Context, serviceId string, environmentId string, query string) (float64, error) {
+	query = strings.ReplaceAll(query, "$service_id", serviceId)
+	query = strings.ReplaceAll(query, "$environment_id", environmentId)
+
+	requestUrl := p.Url + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error creating prometheus request: %w", err)
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error querying prometheus: %w", err)
+	}
+	defer res.Body.Close()
+
+	var response prometheusResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("error parsing prometheus response: %w", err)
+	}
+
+	if response.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed: %s: %s", response.ErrorType, response.Error)
+	}
+
+	return parsePrometheusResult(response.Data)
+}
+
+// parsePrometheusResult accepts a scalar or a vector with exactly one sample, so queries
+// should aggregate down to a single series
+func parsePrometheusResult(data prometheusData) (float64, error) {
+	var value [2]any
+
+	switch data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(data.Result, &value); err != nil {
+			return 0, fmt.Errorf("error parsing prometheus scalar: %w", err)
+		}
+	case "vector":
+		var samples []prometheusVectorSample
+		if err := json.Unmarshal(data.Result, &samples); err != nil {
+			return 0, fmt.Errorf("error parsing prometheus vector: %w", err)
+		}
+
+		if len(samples) != 1 {
+			return 0, fmt.Errorf("prometheus query returned %d series, expected 1", len(samples))
+		}
+
+		value = samples[0].Value
+	default:
+		return 0, fmt.Errorf("unsupported prometheus result type %s", data.ResultType)
+	}
+
+	rawValue, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected prometheus sample value %v", value[1])
+	}
+
+	return strconv.ParseFloat(rawValue, 64)
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/railway"
+	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
+)
+
+const (
+	RailwayQueryCpu    = "cpu"
+	RailwayQueryMemory = "memory"
+)
+
+// RailwayProvider reads metrics from the Railway API. Besides "cpu" and "memory", which
+// are usage as a fraction of the limit, any Railway measurement (e.g. NETWORK_TX_GB) can
+// be queried by name
+type RailwayProvider struct {
+	GqlQueries *railway.QueryService
+}
+
+func NewRailwayProvider(gqlQueries *railway.QueryService) RailwayProvider {
+	return RailwayProvider{
+		GqlQueries: gqlQueries,
+	}
+}
+
+func (r *RailwayProvider) Query(ctx context.Context, serviceId string, environmentId string, query string) (float64, error) {
+	switch query {
+	case RailwayQueryCpu, RailwayQueryMemory:
+		cpuPercent, memPercent, err := r.Utilization(ctx, serviceId, environmentId)
+		if err != nil {
+			return 0, err
+		}
+
+		if query == RailwayQueryCpu {
+			return cpuPercent, nil
+		}
+		return memPercent, nil
+	}
+
+	metrics, err := r.GqlQueries.QueryServiceMetrics(ctx, serviceId, environmentId, time.Now().Format(time.RFC3339), []string{query})
+	if err != nil {
+		return 0, fmt.Errorf("error fetching railway metrics: %w", err)
+	}
+
+	value, ok := latestValue(metrics, query)
+	if !ok {
+		return 0, fmt.Errorf("railway returned no values for %s", query)
+	}
+
+	return value, nil
+}
+
+var utilizationMeasurements = []string{"CPU_USAGE", "MEMORY_USAGE_GB", "CPU_LIMIT", "MEMORY_LIMIT_GB"}
+
+// Utilization is a service's cpu and memory usage as fractions of their limits
+type Utilization struct {
+	Cpu    float64
+	Memory float64
+}
+
+// Utilization returns the service's cpu and memory usage as fractions of their limits
+func (r *RailwayProvider) Utilization(ctx context.Context, serviceId string, environmentId string) (float64, float64, error) {
+	metrics, err := r.GqlQueries.QueryServiceMetrics(
+		ctx,
+		serviceId,
+		environmentId,
+		time.Now().Format(time.RFC3339),
+		utilizationMeasurements,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error fetching railway metrics: %w", err)
+	}
+
+	utilization := utilizationOf(metrics)
+
+	return utilization.Cpu, utilization.Memory, nil
+}
+
+// RegionUtilization returns the service's utilization in each region Railway has metrics for
+func (r *RailwayProvider) RegionUtilization(ctx context.Context, serviceId string, environmentId string) (map[string]Utilization, error) {
+	metrics, err := r.GqlQueries.QueryServiceRegionMetrics(
+		ctx,
+		serviceId,
+		environmentId,
+		time.Now().Format(time.RFC3339),
+		utilizationMeasurements,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching railway region metrics: %w", err)
+	}
+
+	regionMetrics := map[string]*gql.MetricsData{}
+
+	for _, metric := range metrics.Metrics {
+		if metric.Tags.Region == "" {
+			continue
+		}
+
+		if _, ok := regionMetrics[metric.Tags.Region]; !ok {
+			regionMetrics[metric.Tags.Region] = &gql.MetricsData{}
+		}
+
+		regionMetrics[metric.Tags.Region].Metrics = append(regionMetrics[metric.Tags.Region].Metrics, metric)
+	}
+
+	utilization := make(map[string]Utilization, len(regionMetrics))
+	for region, metrics := range regionMetrics {
+		utilization[region] = utilizationOf(metrics)
+	}
+
+	return utilization, nil
+}
+
+func utilizationOf(metrics *gql.MetricsData) Utilization {
+	cpuUsage, _ := latestValue(metrics, "CPU_USAGE")
+	memUsage, _ := latestValue(metrics, "MEMORY_USAGE_GB")
+	cpuLimit, _ := latestValue(metrics, "CPU_LIMIT")
+	memLimit, _ := latestValue(metrics, "MEMORY_LIMIT_GB")
+
+	utilization := Utilization{}
+
+	if cpuLimit > 0 {
+		utilization.Cpu = cpuUsage / cpuLimit
+	}
+	if memLimit > 0 {
+		utilization.Memory = memUsage / memLimit
+	}
+
+	return utilization
+}
+
+func latestValue(metrics *gql.MetricsData, measurement string) (float64, bool) {
+	if metrics == nil {
+		return 0, false
+	}
+
+	for _, metric := range metrics.Metrics {
+		if metric.Measurement == measurement && len(metric.Values) > 0 {
+			return metric.Values[0].Value, true
+		}
+	}
+
+	return 0, false
+}
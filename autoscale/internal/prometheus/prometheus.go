@@ -0,0 +1,136 @@
+package prometheus
+
+import (
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	serviceLabels = []string{"service_id", "region"}
+
+	replicasGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_replicas",
+			Help: "Current replica count of a service region",
+		},
+		serviceLabels,
+	)
+
+	cpuUtilizationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_cpu_utilization",
+			Help: "Latest CPU utilization of a service region, from 0 to 1",
+		},
+		serviceLabels,
+	)
+
+	memoryUtilizationGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_memory_utilization",
+			Help: "Latest memory utilization of a service region, from 0 to 1",
+		},
+		serviceLabels,
+	)
+
+	cpuWeightedAverageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_cpu_weighted_average",
+			Help: "Weighted average of the CPU utilization history of a service region",
+		},
+		serviceLabels,
+	)
+
+	memoryWeightedAverageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_memory_weighted_average",
+			Help: "Weighted average of the memory utilization history of a service region",
+		},
+		serviceLabels,
+	)
+
+	cpuTrendGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_cpu_trend",
+			Help: "Slope of the CPU utilization history of a service region",
+		},
+		serviceLabels,
+	)
+
+	memoryTrendGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "autoscale_service_memory_trend",
+			Help: "Slope of the memory utilization history of a service region",
+		},
+		serviceLabels,
+	)
+
+	scalingActionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "autoscale_scaling_actions_total",
+			Help: "Total number of replica changes the autoscaler decided on, by reason and outcome",
+		},
+		[]string{"service_id", "region", "reason", "status"},
+	)
+
+	railwayRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "autoscale_railway_request_duration_seconds",
+			Help:    "Latency of Railway API requests, including rate limit backoff",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"operation"},
+	)
+
+	railwayRequestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "autoscale_railway_request_errors_total",
+			Help: "Total number of failed Railway API requests",
+		},
+		[]string{"operation"},
+	)
+
+	tickDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "autoscale_tick_duration_seconds",
+			Help:    "Time taken to evaluate every registered service",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	tickOverruns = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "autoscale_tick_overruns_total",
+			Help: "Total number of evaluations that took longer than the monitoring interval",
+		},
+	)
+)
+
+func Init() types.PrometheusMetrics {
+	prometheus.MustRegister(replicasGauge)
+	prometheus.MustRegister(cpuUtilizationGauge)
+	prometheus.MustRegister(memoryUtilizationGauge)
+	prometheus.MustRegister(cpuWeightedAverageGauge)
+	prometheus.MustRegister(memoryWeightedAverageGauge)
+	prometheus.MustRegister(cpuTrendGauge)
+	prometheus.MustRegister(memoryTrendGauge)
+	prometheus.MustRegister(scalingActionsCounter)
+	prometheus.MustRegister(railwayRequestDuration)
+	prometheus.MustRegister(railwayRequestErrors)
+	prometheus.MustRegister(tickDuration)
+	prometheus.MustRegister(tickOverruns)
+
+	return types.PrometheusMetrics{
+		Replicas:               replicasGauge,
+		CpuUtilization:         cpuUtilizationGauge,
+		MemoryUtilization:      memoryUtilizationGauge,
+		CpuWeightedAverage:     cpuWeightedAverageGauge,
+		MemoryWeightedAverage:  memoryWeightedAverageGauge,
+		CpuTrend:               cpuTrendGauge,
+		MemoryTrend:            memoryTrendGauge,
+		ScalingActions:         scalingActionsCounter,
+		RailwayRequestDuration: railwayRequestDuration,
+		RailwayRequestErrors:   railwayRequestErrors,
+		TickDuration:           tickDuration,
+		TickOverruns:           tickOverruns,
+	}
+}
@@ -2,6 +2,7 @@ package railway
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/hasura/go-graphql-client"
@@ -10,6 +11,7 @@ import (
 type GraphQLClient struct {
 	AuthToken string
 	BaseURL   string
+	Logger    *slog.Logger
 	Client    *graphql.Client
 }
 
@@ -22,10 +24,14 @@ func NewClient(gqlConfig *GraphQLClient) (*GraphQLClient, error) {
 		return nil, errors.New("auth token must not be empty")
 	}
 
+	if gqlConfig.Logger == nil {
+		return nil, errors.New("logger must not be nil")
+	}
+
 	httpClient := &http.Client{
 		Transport: &authedTransport{
 			token:   gqlConfig.AuthToken,
-			wrapped: http.DefaultTransport,
+			wrapped: newRateLimitedTransport(http.DefaultTransport, gqlConfig.Logger),
 		},
 	}
 
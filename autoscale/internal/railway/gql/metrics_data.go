@@ -6,9 +6,15 @@ type MetricsData struct {
 
 type Metric struct {
 	Measurement string        `json:"measurement"`
+	Tags        MetricTags    `json:"tags"`
 	Values      []MetricValue `json:"values"`
 }
 
+// MetricTags is only filled in for queries that group by a tag
+type MetricTags struct {
+	Region string `json:"region"`
+}
+
 type MetricValue struct {
 	Timestamp int     `json:"ts"`
 	Value     float64 `json:"value"`
@@ -1,5 +1,7 @@
 package gql
 
+import "slices"
+
 type ProjectData struct {
 	Project Project `env:"project"`
 }
@@ -53,9 +55,21 @@ type Deployment struct {
 	CanRedeploy bool           `json:"canRedeploy"`
 	CreatedAt   string         `json:"createdAt"`
 	Id          string         `json:"id"`
+	Status      string         `json:"status"`
 	Meta        DeploymentMeta `json:"meta"`
 }
 
+// deployments in these states haven't replaced the previous deployment yet
+var inProgressDeploymentStatuses = []string{"QUEUED", "WAITING", "INITIALIZING", "BUILDING", "DEPLOYING"}
+
+func (d Deployment) IsInProgress() bool {
+	return slices.Contains(inProgressDeploymentStatuses, d.Status)
+}
+
+func (d Deployment) HasFailed() bool {
+	return d.Status == "FAILED" || d.Status == "CRASHED"
+}
+
 type DeploymentMeta struct {
 	ServiceManifest ServiceManifest `json:"serviceManifest"`
 }
@@ -71,3 +85,7 @@ type DeployConfig struct {
 type RegionConfig struct {
 	NumReplicas int `json:"numReplicas"`
 }
+
+type ServiceInstanceData struct {
+	ServiceInstance ServiceInstance `json:"serviceInstance"`
+}
@@ -11,5 +11,11 @@ var UpdateRegionsQuery string
 //go:embed metrics.graphql
 var MetricsQuery string
 
+//go:embed region_metrics.graphql
+var RegionMetricsQuery string
+
+//go:embed service_instance.graphql
+var ServiceInstanceQuery string
+
 //go:embed service_instance_deploy.graphql
 var ServiceInstanceDeployQuery string
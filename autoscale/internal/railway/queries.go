@@ -4,32 +4,49 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"time"
 
 	"github.com/ferretcode/switchyard/autoscale/internal/railway/gql"
 	"github.com/ferretcode/switchyard/autoscale/pkg/types"
 )
 
 type QueryService struct {
-	gqlClient *GraphQLClient
-	ctx       context.Context
-	config    types.Config
-	logger    *slog.Logger
+	gqlClient         *GraphQLClient
+	ctx               context.Context
+	config            types.Config
+	logger            *slog.Logger
+	prometheusMetrics *types.PrometheusMetrics
 }
 
-func NewQueryService(gqlClient *GraphQLClient, ctx context.Context, config types.Config, logger *slog.Logger) QueryService {
+func NewQueryService(gqlClient *GraphQLClient, ctx context.Context, config types.Config, logger *slog.Logger, prometheusMetrics *types.PrometheusMetrics) QueryService {
 	return QueryService{
-		gqlClient: gqlClient,
-		ctx:       ctx,
-		config:    config,
-		logger:    logger,
+		gqlClient:         gqlClient,
+		ctx:               ctx,
+		config:            config,
+		logger:            logger,
+		prometheusMetrics: prometheusMetrics,
 	}
 }
 
-func (q *QueryService) QueryProjectData() (*gql.ProjectData, error) {
+// exec records the latency of every request, and whether it failed, under operation
+func (q *QueryService) exec(ctx context.Context, operation string, query string, variables map[string]any) ([]byte, error) {
+	start := time.Now()
+
+	response, err := q.gqlClient.Client.ExecRaw(ctx, query, variables)
+
+	q.prometheusMetrics.RailwayRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		q.prometheusMetrics.RailwayRequestErrors.WithLabelValues(operation).Inc()
+	}
+
+	return response, err
+}
+
+func (q *QueryService) QueryProjectData(projectId string) (*gql.ProjectData, error) {
 	project := gql.ProjectData{}
 
-	response, err := q.gqlClient.Client.ExecRaw(q.ctx, gql.ProjectQuery, map[string]any{
-		"id": q.config.RailwayProjectId,
+	response, err := q.exec(q.ctx, "project", gql.ProjectQuery, map[string]any{
+		"id": projectId,
 	})
 	if err != nil {
 		q.logger.Error("error executing graphql request", "err", err)
@@ -44,11 +61,32 @@ func (q *QueryService) QueryProjectData() (*gql.ProjectData, error) {
 	return &project, nil
 }
 
-func (q *QueryService) QueryServiceMetrics(serviceId string, startDate string) (*gql.MetricsData, error) {
-	response, err := q.gqlClient.Client.ExecRaw(q.ctx, gql.MetricsQuery, map[string]any{
-		"serviceId":    serviceId,
-		"measurements": []string{"CPU_USAGE", "MEMORY_USAGE_GB", "CPU_LIMIT", "MEMORY_LIMIT_GB"},
-		"startDate":    startDate,
+func (q *QueryService) QueryServiceInstance(serviceId string, environmentId string) (*gql.ServiceInstance, error) {
+	response, err := q.exec(q.ctx, "service_instance", gql.ServiceInstanceQuery, map[string]any{
+		"serviceId":     serviceId,
+		"environmentId": environmentId,
+	})
+	if err != nil {
+		q.logger.Error("error executing graphql request", "err", err)
+		return nil, err
+	}
+
+	serviceInstance := gql.ServiceInstanceData{}
+
+	if err := json.Unmarshal(response, &serviceInstance); err != nil {
+		q.logger.Error("error unmarshalling response bytes into service instance struct", "err", err)
+		return nil, err
+	}
+
+	return &serviceInstance.ServiceInstance, nil
+}
+
+func (q *QueryService) QueryServiceMetrics(ctx context.Context, serviceId string, environmentId string, startDate string, measurements []string) (*gql.MetricsData, error) {
+	response, err := q.exec(ctx, "metrics", gql.MetricsQuery, map[string]any{
+		"serviceId":     serviceId,
+		"environmentId": environmentId,
+		"measurements":  measurements,
+		"startDate":     startDate,
 	})
 	if err != nil {
 		q.logger.Error("error executing graphql request", "err", err)
@@ -65,25 +103,59 @@ func (q *QueryService) QueryServiceMetrics(serviceId string, startDate string) (
 	return &metrics, nil
 }
 
-func (q *QueryService) MutationUpdateReplicas(environmentId string, serviceId string, regionName string, newReplicas int) error {
-	vars := map[string]interface{}{
-		"environmentId": environmentId,
+// QueryServiceRegionMetrics returns the service's metrics split up by the region they were measured in
+func (q *QueryService) QueryServiceRegionMetrics(ctx context.Context, serviceId string, environmentId string, startDate string, measurements []string) (*gql.MetricsData, error) {
+	response, err := q.exec(ctx, "region_metrics", gql.RegionMetricsQuery, map[string]any{
 		"serviceId":     serviceId,
-		"multiRegionConfig": map[string]interface{}{
-			regionName: map[string]interface{}{
-				"numReplicas": newReplicas,
-			},
-		},
+		"environmentId": environmentId,
+		"measurements":  measurements,
+		"startDate":     startDate,
+	})
+	if err != nil {
+		q.logger.Error("error executing graphql request", "err", err)
+		return nil, err
+	}
+
+	metrics := gql.MetricsData{}
+
+	if err := json.Unmarshal(response, &metrics); err != nil {
+		q.logger.Error("error unmarshalling repsonse bytes into metrics struct", "err", err)
+		return nil, err
+	}
+
+	return &metrics, nil
+}
+
+// MutationUpdateReplicas sets the replica count of one region. Railway replaces the whole
+// multiRegionConfig on update, so the service's current config is sent back with only
+// that region changed to leave the other regions alone
+func (q *QueryService) MutationUpdateReplicas(environmentId string, serviceId string, currentConfig map[string]gql.RegionConfig, regionName string, newReplicas int) error {
+	multiRegionConfig := make(map[string]interface{}, len(currentConfig)+1)
+
+	for region, regionConfig := range currentConfig {
+		multiRegionConfig[region] = map[string]interface{}{
+			"numReplicas": regionConfig.NumReplicas,
+		}
+	}
+
+	multiRegionConfig[regionName] = map[string]interface{}{
+		"numReplicas": newReplicas,
+	}
+
+	vars := map[string]interface{}{
+		"environmentId":     environmentId,
+		"serviceId":         serviceId,
+		"multiRegionConfig": multiRegionConfig,
 	}
 
 	q.logger.Info("making autoscale mutation request", "variables", vars)
 
-	_, err := q.gqlClient.Client.ExecRaw(q.ctx, gql.UpdateRegionsQuery, vars)
+	_, err := q.exec(q.ctx, "update_replicas", gql.UpdateRegionsQuery, vars)
 	return err
 }
 
 func (q *QueryService) MutationServiceInstanceRedeploy(environmentId string, serviceId string) error {
-	_, err := q.gqlClient.Client.ExecRaw(q.ctx, gql.ServiceInstanceDeployQuery, map[string]interface{}{
+	_, err := q.exec(q.ctx, "redeploy", gql.ServiceInstanceDeployQuery, map[string]any{
 		"environmentId": environmentId,
 		"serviceId":     serviceId,
 	})
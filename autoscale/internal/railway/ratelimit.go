@@ -0,0 +1,110 @@
+package railway
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitRetries        = 3
+	rateLimitInitialBackoff = time.Second
+	rateLimitMaxBackoff     = time.Minute
+)
+
+// rateLimitedTransport retries requests Railway answers with 429. Every request waits
+// out the backoff, not just the one that was limited, so parallel service evaluations
+// don't keep hitting the limit
+type rateLimitedTransport struct {
+	wrapped        http.RoundTripper
+	logger         *slog.Logger
+	initialBackoff time.Duration
+	blockedUntil   time.Time
+	mutex          sync.Mutex
+}
+
+func newRateLimitedTransport(wrapped http.RoundTripper, logger *slog.Logger) *rateLimitedTransport {
+	return &rateLimitedTransport{
+		wrapped:        wrapped,
+		logger:         logger,
+		initialBackoff: rateLimitInitialBackoff,
+	}
+}
+
+func (t *rateLimitedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	backoff := t.initialBackoff
+
+	for retry := 0; ; retry++ {
+		if err := t.wait(r); err != nil {
+			return nil, err
+		}
+
+		res, err := t.wrapped.RoundTrip(r)
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || retry >= rateLimitRetries || r.GetBody == nil {
+			return res, err
+		}
+
+		res.Body.Close()
+
+		wait := retryAfter(res, backoff)
+		t.block(wait)
+
+		t.logger.Warn("railway rate limit hit, backing off", "retry", retry+1, "backoff", wait)
+
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		r = r.Clone(r.Context())
+		r.Body = body
+
+		backoff = min(backoff*2, rateLimitMaxBackoff)
+	}
+}
+
+func (t *rateLimitedTransport) wait(r *http.Request) error {
+	t.mutex.Lock()
+	wait := time.Until(t.blockedUntil)
+	t.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.Context().Done():
+		return r.Context().Err()
+	}
+}
+
+func (t *rateLimitedTransport) block(wait time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if until := time.Now().Add(wait); until.After(t.blockedUntil) {
+		t.blockedUntil = until
+	}
+}
+
+// retryAfter prefers Railway's Retry-After header, in seconds or as a date, over backoff
+func retryAfter(res *http.Response, backoff time.Duration) time.Duration {
+	header := res.Header.Get("Retry-After")
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return min(time.Duration(seconds)*time.Second, rateLimitMaxBackoff)
+	}
+
+	if date, err := http.ParseTime(header); err == nil && time.Until(date) > 0 {
+		return min(time.Until(date), rateLimitMaxBackoff)
+	}
+
+	return backoff
+}
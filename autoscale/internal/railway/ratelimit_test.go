@@ -0,0 +1,114 @@
+package railway
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers with the next status code, and 200 once they run out
+type scriptedTransport struct {
+	statusCodes []int
+	calls       int
+}
+
+func (t *scriptedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	statusCode := http.StatusOK
+	if t.calls < len(t.statusCodes) {
+		statusCode = t.statusCodes[t.calls]
+	}
+	t.calls++
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	limited := http.StatusTooManyRequests
+
+	tests := []struct {
+		name        string
+		statusCodes []int
+		// rewindable is whether the request body can be sent again
+		rewindable bool
+		statusCode int
+		calls      int
+		warnings   int
+	}{
+		{name: "not limited", rewindable: true, statusCode: http.StatusOK, calls: 1},
+		{name: "limited once", statusCodes: []int{limited}, rewindable: true, statusCode: http.StatusOK, calls: 2, warnings: 1},
+		{name: "limited past the retries", statusCodes: []int{limited, limited, limited, limited}, rewindable: true, statusCode: limited, calls: 4, warnings: 3},
+		{name: "body can't be resent", statusCodes: []int{limited}, statusCode: limited, calls: 1},
+		{name: "other errors aren't retried", statusCodes: []int{http.StatusInternalServerError}, rewindable: true, statusCode: http.StatusInternalServerError, calls: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+
+			wrapped := &scriptedTransport{statusCodes: test.statusCodes}
+			transport := newRateLimitedTransport(wrapped, slog.New(slog.NewTextHandler(logs, nil)))
+			transport.initialBackoff = time.Millisecond
+
+			var body io.Reader = strings.NewReader(`{"query": "{ me { id } }"}`)
+			if !test.rewindable {
+				body = io.NopCloser(body)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "http://railway.test/graphql/v2", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("error making request: %v", err)
+			}
+
+			if res.StatusCode != test.statusCode || wrapped.calls != test.calls {
+				t.Errorf("got %d after %d calls, want %d after %d calls", res.StatusCode, wrapped.calls, test.statusCode, test.calls)
+			}
+
+			if warnings := strings.Count(logs.String(), "railway rate limit hit"); warnings != test.warnings {
+				t.Errorf("got %d warnings on the transport's logger, want %d", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	backoff := 4 * time.Second
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "no header", want: backoff},
+		{name: "seconds", retryAfter: "10", want: 10 * time.Second},
+		{name: "capped", retryAfter: "3600", want: rateLimitMaxBackoff},
+		{name: "zero", retryAfter: "0", want: backoff},
+		{name: "date in the past", retryAfter: "Mon, 02 Jun 2025 12:00:00 GMT", want: backoff},
+		{name: "garbage", retryAfter: "soon", want: backoff},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if test.retryAfter != "" {
+				res.Header.Set("Retry-After", test.retryAfter)
+			}
+
+			if got := retryAfter(res, backoff); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -6,8 +6,29 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 )
 
+type ReplicaBudget struct {
+	ReplicaGroup string `json:"replica_group"`
+	MaxReplicas  int32  `json:"max_replicas"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+type ScalingEvent struct {
+	ID             int64           `json:"id"`
+	ServiceID      string          `json:"service_id"`
+	OldReplicas    int32           `json:"old_replicas"`
+	NewReplicas    int32           `json:"new_replicas"`
+	Reason         string          `json:"reason"`
+	Status         string          `json:"status"`
+	Message        string          `json:"message"`
+	ScalingContext json.RawMessage `json:"scaling_context"`
+	CreatedAt      int64           `json:"created_at"`
+	Region         string          `json:"region"`
+}
+
 type Service struct {
 	ServiceID                       string         `json:"service_id"`
 	JobName                         sql.NullString `json:"job_name"`
@@ -20,4 +41,89 @@ type Service struct {
 	DownscaleCooldown               string         `json:"downscale_cooldown"`
 	MinReplicaCount                 int32          `json:"min_replica_count"`
 	MaxReplicaCount                 int32          `json:"max_replica_count"`
+	Mode                            string         `json:"mode"`
+	Policy                          string         `json:"policy"`
+	TargetCpuUtilization            float64        `json:"target_cpu_utilization"`
+	TargetMemoryUtilization         float64        `json:"target_memory_utilization"`
+	MaxScaleUpStep                  int32          `json:"max_scale_up_step"`
+	MaxScaleDownStep                int32          `json:"max_scale_down_step"`
+	ScaleDownStabilization          string         `json:"scale_down_stabilization"`
+	ProjectID                       string         `json:"project_id"`
+	EnvironmentID                   string         `json:"environment_id"`
+	SeasonalPeriod                  string         `json:"seasonal_period"`
+	PredictiveLeadTime              string         `json:"predictive_lead_time"`
+	ReplicaGroup                    string         `json:"replica_group"`
+	Priority                        int32          `json:"priority"`
+	ReplicaHourlyCost               float64        `json:"replica_hourly_cost"`
+}
+
+type ServiceMetric struct {
+	ServiceID   string  `json:"service_id"`
+	Name        string  `json:"name"`
+	Provider    string  `json:"provider"`
+	Query       string  `json:"query"`
+	TargetValue float64 `json:"target_value"`
+	CreatedAt   int64   `json:"created_at"`
+	UpdatedAt   int64   `json:"updated_at"`
+}
+
+type ServiceMetricRollup struct {
+	ServiceID       string  `json:"service_id"`
+	Region          string  `json:"region"`
+	BucketStart     int64   `json:"bucket_start"`
+	CpuDemandSum    float64 `json:"cpu_demand_sum"`
+	MemoryDemandSum float64 `json:"memory_demand_sum"`
+	Samples         int32   `json:"samples"`
+}
+
+type ServiceMetricSample struct {
+	ID            int64   `json:"id"`
+	ServiceID     string  `json:"service_id"`
+	CpuPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	SampledAt     int64   `json:"sampled_at"`
+	Region        string  `json:"region"`
+}
+
+type ServiceOverride struct {
+	ServiceID    string `json:"service_id"`
+	ReplicaCount int32  `json:"replica_count"`
+	Reason       string `json:"reason"`
+	ExpiresAt    int64  `json:"expires_at"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type ServiceRegion struct {
+	ServiceID       string `json:"service_id"`
+	Region          string `json:"region"`
+	MinReplicaCount int32  `json:"min_replica_count"`
+	MaxReplicaCount int32  `json:"max_replica_count"`
+	CreatedAt       int64  `json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+type ServiceScalingState struct {
+	ServiceID           string          `json:"service_id"`
+	LastUpscaleAt       int64           `json:"last_upscale_at"`
+	LastDownscaleAt     int64           `json:"last_downscale_at"`
+	ConsecutiveHighLoad int32           `json:"consecutive_high_load"`
+	ConsecutiveLowLoad  int32           `json:"consecutive_low_load"`
+	UpdatedAt           int64           `json:"updated_at"`
+	Recommendations     json.RawMessage `json:"recommendations"`
+	Region              string          `json:"region"`
+}
+
+type ServiceSchedule struct {
+	ID                 int64         `json:"id"`
+	ServiceID          string        `json:"service_id"`
+	Name               string        `json:"name"`
+	CronExpression     string        `json:"cron_expression"`
+	Duration           string        `json:"duration"`
+	Timezone           string        `json:"timezone"`
+	MinReplicaCount    sql.NullInt32 `json:"min_replica_count"`
+	MaxReplicaCount    sql.NullInt32 `json:"max_replica_count"`
+	PinnedReplicaCount sql.NullInt32 `json:"pinned_replica_count"`
+	Enabled            bool          `json:"enabled"`
+	CreatedAt          int64         `json:"created_at"`
+	UpdatedAt          int64         `json:"updated_at"`
 }
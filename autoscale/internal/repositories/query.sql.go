@@ -8,21 +8,173 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
 )
 
-const createService = `-- name: CreateService :one
-INSERT INTO services (
+const countServiceDependents = `-- name: CountServiceDependents :one
+SELECT
+    (SELECT COUNT(*) FROM service_schedules WHERE service_schedules.service_id = $1) AS schedules,
+    (SELECT COUNT(*) FROM service_regions WHERE service_regions.service_id = $1) AS regions,
+    (SELECT COUNT(*) FROM service_metrics WHERE service_metrics.service_id = $1) AS metrics,
+    (SELECT COUNT(*) FROM service_overrides WHERE service_overrides.service_id = $1) AS overrides,
+    (SELECT COUNT(*) FROM service_scaling_states WHERE service_scaling_states.service_id = $1) AS scaling_states,
+    (SELECT COUNT(*) FROM service_metric_samples WHERE service_metric_samples.service_id = $1) AS metric_samples,
+    (SELECT COUNT(*) FROM service_metric_rollups WHERE service_metric_rollups.service_id = $1) AS metric_rollups,
+    (SELECT COUNT(*) FROM scaling_events WHERE scaling_events.service_id = $1) AS scaling_events
+`
+
+type CountServiceDependentsRow struct {
+	Schedules     int64 `json:"schedules"`
+	Regions       int64 `json:"regions"`
+	Metrics       int64 `json:"metrics"`
+	Overrides     int64 `json:"overrides"`
+	ScalingStates int64 `json:"scaling_states"`
+	MetricSamples int64 `json:"metric_samples"`
+	MetricRollups int64 `json:"metric_rollups"`
+	ScalingEvents int64 `json:"scaling_events"`
+}
+
+func (q *Queries) CountServiceDependents(ctx context.Context, serviceID string) (CountServiceDependentsRow, error) {
+	row := q.db.QueryRowContext(ctx, countServiceDependents, serviceID)
+	var i CountServiceDependentsRow
+	err := row.Scan(
+		&i.Schedules,
+		&i.Regions,
+		&i.Metrics,
+		&i.Overrides,
+		&i.ScalingStates,
+		&i.MetricSamples,
+		&i.MetricRollups,
+		&i.ScalingEvents,
+	)
+	return i, err
+}
+
+const createScalingEvent = `-- name: CreateScalingEvent :one
+INSERT INTO scaling_events (
     service_id,
-    job_name,
-    enabled,
-    railway_memory_upscale_threshold,
-    railway_cpu_upscale_threshold,
-    railway_memory_downscale_threshold,
-    railway_cpu_downscale_threshold,
-    upscale_cooldown,
-    downscale_cooldown,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+`
+
+type CreateScalingEventParams struct {
+	ServiceID      string          `json:"service_id"`
+	OldReplicas    int32           `json:"old_replicas"`
+	NewReplicas    int32           `json:"new_replicas"`
+	Reason         string          `json:"reason"`
+	Status         string          `json:"status"`
+	Message        string          `json:"message"`
+	ScalingContext json.RawMessage `json:"scaling_context"`
+	CreatedAt      int64           `json:"created_at"`
+	Region         string          `json:"region"`
+}
+
+func (q *Queries) CreateScalingEvent(ctx context.Context, arg CreateScalingEventParams) (ScalingEvent, error) {
+	row := q.db.QueryRowContext(ctx, createScalingEvent,
+		arg.ServiceID,
+		arg.OldReplicas,
+		arg.NewReplicas,
+		arg.Reason,
+		arg.Status,
+		arg.Message,
+		arg.ScalingContext,
+		arg.CreatedAt,
+		arg.Region,
+	)
+	var i ScalingEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceID,
+		&i.OldReplicas,
+		&i.NewReplicas,
+		&i.Reason,
+		&i.Status,
+		&i.Message,
+		&i.ScalingContext,
+		&i.CreatedAt,
+		&i.Region,
+	)
+	return i, err
+}
+
+const createServiceMetricSample = `-- name: CreateServiceMetricSample :exec
+INSERT INTO service_metric_samples (
+    service_id,
+    cpu_percent,
+    memory_percent,
+    sampled_at,
+    region
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+`
+
+type CreateServiceMetricSampleParams struct {
+	ServiceID     string  `json:"service_id"`
+	CpuPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	SampledAt     int64   `json:"sampled_at"`
+	Region        string  `json:"region"`
+}
+
+func (q *Queries) CreateServiceMetricSample(ctx context.Context, arg CreateServiceMetricSampleParams) error {
+	_, err := q.db.ExecContext(ctx, createServiceMetricSample,
+		arg.ServiceID,
+		arg.CpuPercent,
+		arg.MemoryPercent,
+		arg.SampledAt,
+		arg.Region,
+	)
+	return err
+}
+
+const createServiceSchedule = `-- name: CreateServiceSchedule :one
+INSERT INTO service_schedules (
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at
 ) VALUES (
     $1,
     $2,
@@ -37,6 +189,266 @@ INSERT INTO services (
     $11
 )
 RETURNING
+    id,
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at
+`
+
+type CreateServiceScheduleParams struct {
+	ServiceID          string        `json:"service_id"`
+	Name               string        `json:"name"`
+	CronExpression     string        `json:"cron_expression"`
+	Duration           string        `json:"duration"`
+	Timezone           string        `json:"timezone"`
+	MinReplicaCount    sql.NullInt32 `json:"min_replica_count"`
+	MaxReplicaCount    sql.NullInt32 `json:"max_replica_count"`
+	PinnedReplicaCount sql.NullInt32 `json:"pinned_replica_count"`
+	Enabled            bool          `json:"enabled"`
+	CreatedAt          int64         `json:"created_at"`
+	UpdatedAt          int64         `json:"updated_at"`
+}
+
+func (q *Queries) CreateServiceSchedule(ctx context.Context, arg CreateServiceScheduleParams) (ServiceSchedule, error) {
+	row := q.db.QueryRowContext(ctx, createServiceSchedule,
+		arg.ServiceID,
+		arg.Name,
+		arg.CronExpression,
+		arg.Duration,
+		arg.Timezone,
+		arg.MinReplicaCount,
+		arg.MaxReplicaCount,
+		arg.PinnedReplicaCount,
+		arg.Enabled,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i ServiceSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceID,
+		&i.Name,
+		&i.CronExpression,
+		&i.Duration,
+		&i.Timezone,
+		&i.MinReplicaCount,
+		&i.MaxReplicaCount,
+		&i.PinnedReplicaCount,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteExpiredServiceOverrides = `-- name: DeleteExpiredServiceOverrides :execrows
+DELETE FROM service_overrides
+WHERE expires_at <= $1
+`
+
+func (q *Queries) DeleteExpiredServiceOverrides(ctx context.Context, expiresAt int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredServiceOverrides, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOldServiceMetricRollups = `-- name: DeleteOldServiceMetricRollups :execrows
+DELETE FROM service_metric_rollups
+WHERE bucket_start < $1
+`
+
+func (q *Queries) DeleteOldServiceMetricRollups(ctx context.Context, bucketStart int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldServiceMetricRollups, bucketStart)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOldServiceMetricSamples = `-- name: DeleteOldServiceMetricSamples :exec
+DELETE FROM service_metric_samples
+WHERE service_id = $1 AND region = $2
+AND id NOT IN (
+    SELECT id FROM service_metric_samples
+    WHERE service_id = $1 AND region = $2
+    ORDER BY sampled_at DESC
+    LIMIT $3
+)
+`
+
+type DeleteOldServiceMetricSamplesParams struct {
+	ServiceID string `json:"service_id"`
+	Region    string `json:"region"`
+	Limit     int32  `json:"limit"`
+}
+
+func (q *Queries) DeleteOldServiceMetricSamples(ctx context.Context, arg DeleteOldServiceMetricSamplesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOldServiceMetricSamples, arg.ServiceID, arg.Region, arg.Limit)
+	return err
+}
+
+const deleteReplicaBudget = `-- name: DeleteReplicaBudget :execrows
+DELETE FROM replica_budgets
+WHERE replica_group = $1
+`
+
+func (q *Queries) DeleteReplicaBudget(ctx context.Context, replicaGroup string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteReplicaBudget, replicaGroup)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteService = `-- name: DeleteService :exec
+DELETE FROM services
+WHERE service_id = $1
+`
+
+func (q *Queries) DeleteService(ctx context.Context, serviceID string) error {
+	_, err := q.db.ExecContext(ctx, deleteService, serviceID)
+	return err
+}
+
+const deleteServiceMetric = `-- name: DeleteServiceMetric :execrows
+DELETE FROM service_metrics
+WHERE service_id = $1 AND name = $2
+`
+
+type DeleteServiceMetricParams struct {
+	ServiceID string `json:"service_id"`
+	Name      string `json:"name"`
+}
+
+func (q *Queries) DeleteServiceMetric(ctx context.Context, arg DeleteServiceMetricParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteServiceMetric, arg.ServiceID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteServiceOverride = `-- name: DeleteServiceOverride :execrows
+DELETE FROM service_overrides
+WHERE service_id = $1
+`
+
+func (q *Queries) DeleteServiceOverride(ctx context.Context, serviceID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteServiceOverride, serviceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteServiceRegion = `-- name: DeleteServiceRegion :execrows
+DELETE FROM service_regions
+WHERE service_id = $1 AND region = $2
+`
+
+type DeleteServiceRegionParams struct {
+	ServiceID string `json:"service_id"`
+	Region    string `json:"region"`
+}
+
+func (q *Queries) DeleteServiceRegion(ctx context.Context, arg DeleteServiceRegionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteServiceRegion, arg.ServiceID, arg.Region)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteServiceSchedule = `-- name: DeleteServiceSchedule :execrows
+DELETE FROM service_schedules
+WHERE id = $1 AND service_id = $2
+`
+
+type DeleteServiceScheduleParams struct {
+	ID        int64  `json:"id"`
+	ServiceID string `json:"service_id"`
+}
+
+func (q *Queries) DeleteServiceSchedule(ctx context.Context, arg DeleteServiceScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteServiceSchedule, arg.ID, arg.ServiceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failPendingScalingEvents = `-- name: FailPendingScalingEvents :exec
+UPDATE scaling_events
+SET
+    status = 'failed',
+    message = $1
+WHERE status = 'pending'
+`
+
+func (q *Queries) FailPendingScalingEvents(ctx context.Context, message string) error {
+	_, err := q.db.ExecContext(ctx, failPendingScalingEvents, message)
+	return err
+}
+
+const getActiveServiceOverride = `-- name: GetActiveServiceOverride :one
+SELECT
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+FROM service_overrides
+WHERE service_id = $1 AND expires_at > $2
+`
+
+type GetActiveServiceOverrideParams struct {
+	ServiceID string `json:"service_id"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+func (q *Queries) GetActiveServiceOverride(ctx context.Context, arg GetActiveServiceOverrideParams) (ServiceOverride, error) {
+	row := q.db.QueryRowContext(ctx, getActiveServiceOverride, arg.ServiceID, arg.ExpiresAt)
+	var i ServiceOverride
+	err := row.Scan(
+		&i.ServiceID,
+		&i.ReplicaCount,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLeaderLockHolder = `-- name: GetLeaderLockHolder :one
+SELECT a.application_name
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory'
+    AND l.granted
+    AND l.classid = 0
+    AND l.objid = $1::bigint::oid
+    AND l.objsubid = 1
+`
+
+func (q *Queries) GetLeaderLockHolder(ctx context.Context, lockKey int64) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderLockHolder, lockKey)
+	var application_name sql.NullString
+	err := row.Scan(&application_name)
+	return application_name, err
+}
+
+const getService = `-- name: GetService :one
+SELECT
     service_id,
     job_name,
     enabled,
@@ -47,37 +459,28 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
+FROM services
+WHERE service_id = $1
+LIMIT 1
 `
 
-type CreateServiceParams struct {
-	ServiceID                       string         `json:"service_id"`
-	JobName                         sql.NullString `json:"job_name"`
-	Enabled                         bool           `json:"enabled"`
-	RailwayMemoryUpscaleThreshold   float64        `json:"railway_memory_upscale_threshold"`
-	RailwayCpuUpscaleThreshold      float64        `json:"railway_cpu_upscale_threshold"`
-	RailwayMemoryDownscaleThreshold float64        `json:"railway_memory_downscale_threshold"`
-	RailwayCpuDownscaleThreshold    float64        `json:"railway_cpu_downscale_threshold"`
-	UpscaleCooldown                 string         `json:"upscale_cooldown"`
-	DownscaleCooldown               string         `json:"downscale_cooldown"`
-	MinReplicaCount                 int32          `json:"min_replica_count"`
-	MaxReplicaCount                 int32          `json:"max_replica_count"`
-}
-
-func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (Service, error) {
-	row := q.db.QueryRowContext(ctx, createService,
-		arg.ServiceID,
-		arg.JobName,
-		arg.Enabled,
-		arg.RailwayMemoryUpscaleThreshold,
-		arg.RailwayCpuUpscaleThreshold,
-		arg.RailwayMemoryDownscaleThreshold,
-		arg.RailwayCpuDownscaleThreshold,
-		arg.UpscaleCooldown,
-		arg.DownscaleCooldown,
-		arg.MinReplicaCount,
-		arg.MaxReplicaCount,
-	)
+func (q *Queries) GetService(ctx context.Context, serviceID string) (Service, error) {
+	row := q.db.QueryRowContext(ctx, getService, serviceID)
 	var i Service
 	err := row.Scan(
 		&i.ServiceID,
@@ -91,96 +494,563 @@ func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (S
 		&i.DownscaleCooldown,
 		&i.MinReplicaCount,
 		&i.MaxReplicaCount,
+		&i.Mode,
+		&i.Policy,
+		&i.TargetCpuUtilization,
+		&i.TargetMemoryUtilization,
+		&i.MaxScaleUpStep,
+		&i.MaxScaleDownStep,
+		&i.ScaleDownStabilization,
+		&i.ProjectID,
+		&i.EnvironmentID,
+		&i.SeasonalPeriod,
+		&i.PredictiveLeadTime,
+		&i.ReplicaGroup,
+		&i.Priority,
+		&i.ReplicaHourlyCost,
 	)
 	return i, err
 }
 
-const deleteService = `-- name: DeleteService :exec
-DELETE FROM services
+const getServicesByJobName = `-- name: GetServicesByJobName :many
+SELECT
+    service_id,
+    job_name,
+    enabled,
+    railway_memory_upscale_threshold,
+    railway_cpu_upscale_threshold,
+    railway_memory_downscale_threshold,
+    railway_cpu_downscale_threshold,
+    upscale_cooldown,
+    downscale_cooldown,
+    min_replica_count,
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
+FROM services
+WHERE job_name = $1
+ORDER BY service_id
+`
+
+func (q *Queries) GetServicesByJobName(ctx context.Context, jobName sql.NullString) ([]Service, error) {
+	rows, err := q.db.QueryContext(ctx, getServicesByJobName, jobName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Service
+	for rows.Next() {
+		var i Service
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.JobName,
+			&i.Enabled,
+			&i.RailwayMemoryUpscaleThreshold,
+			&i.RailwayCpuUpscaleThreshold,
+			&i.RailwayMemoryDownscaleThreshold,
+			&i.RailwayCpuDownscaleThreshold,
+			&i.UpscaleCooldown,
+			&i.DownscaleCooldown,
+			&i.MinReplicaCount,
+			&i.MaxReplicaCount,
+			&i.Mode,
+			&i.Policy,
+			&i.TargetCpuUtilization,
+			&i.TargetMemoryUtilization,
+			&i.MaxScaleUpStep,
+			&i.MaxScaleDownStep,
+			&i.ScaleDownStabilization,
+			&i.ProjectID,
+			&i.EnvironmentID,
+			&i.SeasonalPeriod,
+			&i.PredictiveLeadTime,
+			&i.ReplicaGroup,
+			&i.Priority,
+			&i.ReplicaHourlyCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveServiceOverrides = `-- name: ListActiveServiceOverrides :many
+SELECT
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+FROM service_overrides
+WHERE expires_at > $1
+`
+
+func (q *Queries) ListActiveServiceOverrides(ctx context.Context, expiresAt int64) ([]ServiceOverride, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveServiceOverrides, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceOverride
+	for rows.Next() {
+		var i ServiceOverride
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.ReplicaCount,
+			&i.Reason,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReplicaBudgets = `-- name: ListReplicaBudgets :many
+SELECT
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at
+FROM replica_budgets
+ORDER BY replica_group
+`
+
+func (q *Queries) ListReplicaBudgets(ctx context.Context) ([]ReplicaBudget, error) {
+	rows, err := q.db.QueryContext(ctx, listReplicaBudgets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReplicaBudget
+	for rows.Next() {
+		var i ReplicaBudget
+		if err := rows.Scan(
+			&i.ReplicaGroup,
+			&i.MaxReplicas,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScalingEventsByServiceID = `-- name: ListScalingEventsByServiceID :many
+SELECT
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+FROM scaling_events
 WHERE service_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
 `
 
-func (q *Queries) DeleteService(ctx context.Context, serviceID string) error {
-	_, err := q.db.ExecContext(ctx, deleteService, serviceID)
-	return err
+type ListScalingEventsByServiceIDParams struct {
+	ServiceID string `json:"service_id"`
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+}
+
+func (q *Queries) ListScalingEventsByServiceID(ctx context.Context, arg ListScalingEventsByServiceIDParams) ([]ScalingEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listScalingEventsByServiceID, arg.ServiceID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScalingEvent
+	for rows.Next() {
+		var i ScalingEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceID,
+			&i.OldReplicas,
+			&i.NewReplicas,
+			&i.Reason,
+			&i.Status,
+			&i.Message,
+			&i.ScalingContext,
+			&i.CreatedAt,
+			&i.Region,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScalingEventsByServiceIDSince = `-- name: ListScalingEventsByServiceIDSince :many
+SELECT
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+FROM scaling_events
+WHERE service_id = $1 AND created_at >= $2
+ORDER BY created_at, id
+`
+
+type ListScalingEventsByServiceIDSinceParams struct {
+	ServiceID string `json:"service_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func (q *Queries) ListScalingEventsByServiceIDSince(ctx context.Context, arg ListScalingEventsByServiceIDSinceParams) ([]ScalingEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listScalingEventsByServiceIDSince, arg.ServiceID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScalingEvent
+	for rows.Next() {
+		var i ScalingEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceID,
+			&i.OldReplicas,
+			&i.NewReplicas,
+			&i.Reason,
+			&i.Status,
+			&i.Message,
+			&i.ScalingContext,
+			&i.CreatedAt,
+			&i.Region,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceMetricRollups = `-- name: ListServiceMetricRollups :many
+SELECT
+    service_id,
+    region,
+    bucket_start,
+    cpu_demand_sum,
+    memory_demand_sum,
+    samples
+FROM service_metric_rollups
+WHERE service_id = $1 AND region = $2 AND bucket_start = ANY($3::BIGINT[])
+ORDER BY bucket_start
+`
+
+type ListServiceMetricRollupsParams struct {
+	ServiceID string  `json:"service_id"`
+	Region    string  `json:"region"`
+	Column3   []int64 `json:"column_3"`
+}
+
+func (q *Queries) ListServiceMetricRollups(ctx context.Context, arg ListServiceMetricRollupsParams) ([]ServiceMetricRollup, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceMetricRollups, arg.ServiceID, arg.Region, pq.Array(arg.Column3))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceMetricRollup
+	for rows.Next() {
+		var i ServiceMetricRollup
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.Region,
+			&i.BucketStart,
+			&i.CpuDemandSum,
+			&i.MemoryDemandSum,
+			&i.Samples,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceMetricSamples = `-- name: ListServiceMetricSamples :many
+SELECT
+    id,
+    service_id,
+    cpu_percent,
+    memory_percent,
+    sampled_at,
+    region
+FROM service_metric_samples
+ORDER BY service_id, region, sampled_at
+`
+
+func (q *Queries) ListServiceMetricSamples(ctx context.Context) ([]ServiceMetricSample, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceMetricSamples)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceMetricSample
+	for rows.Next() {
+		var i ServiceMetricSample
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceID,
+			&i.CpuPercent,
+			&i.MemoryPercent,
+			&i.SampledAt,
+			&i.Region,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceMetricsByServiceID = `-- name: ListServiceMetricsByServiceID :many
+SELECT
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at
+FROM service_metrics
+WHERE service_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListServiceMetricsByServiceID(ctx context.Context, serviceID string) ([]ServiceMetric, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceMetricsByServiceID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceMetric
+	for rows.Next() {
+		var i ServiceMetric
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.Name,
+			&i.Provider,
+			&i.Query,
+			&i.TargetValue,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceRegionsByServiceID = `-- name: ListServiceRegionsByServiceID :many
+SELECT
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at
+FROM service_regions
+WHERE service_id = $1
+ORDER BY region
+`
+
+func (q *Queries) ListServiceRegionsByServiceID(ctx context.Context, serviceID string) ([]ServiceRegion, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceRegionsByServiceID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceRegion
+	for rows.Next() {
+		var i ServiceRegion
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.Region,
+			&i.MinReplicaCount,
+			&i.MaxReplicaCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceScalingStates = `-- name: ListServiceScalingStates :many
+SELECT
+    service_id,
+    last_upscale_at,
+    last_downscale_at,
+    consecutive_high_load,
+    consecutive_low_load,
+    updated_at,
+    region,
+    recommendations
+FROM service_scaling_states
+ORDER BY service_id, region
+`
+
+func (q *Queries) ListServiceScalingStates(ctx context.Context) ([]ServiceScalingState, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceScalingStates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceScalingState
+	for rows.Next() {
+		var i ServiceScalingState
+		if err := rows.Scan(
+			&i.ServiceID,
+			&i.LastUpscaleAt,
+			&i.LastDownscaleAt,
+			&i.ConsecutiveHighLoad,
+			&i.ConsecutiveLowLoad,
+			&i.UpdatedAt,
+			&i.Region,
+			&i.Recommendations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getService = `-- name: GetService :one
+const listServiceSchedulesByServiceID = `-- name: ListServiceSchedulesByServiceID :many
 SELECT
+    id,
     service_id,
-    job_name,
-    enabled,
-    railway_memory_upscale_threshold,
-    railway_cpu_upscale_threshold,
-    railway_memory_downscale_threshold,
-    railway_cpu_downscale_threshold,
-    upscale_cooldown,
-    downscale_cooldown,
+    name,
+    cron_expression,
+    duration,
+    timezone,
     min_replica_count,
-    max_replica_count
-FROM services
-WHERE service_id = $1
-LIMIT 1
-`
-
-func (q *Queries) GetService(ctx context.Context, serviceID string) (Service, error) {
-	row := q.db.QueryRowContext(ctx, getService, serviceID)
-	var i Service
-	err := row.Scan(
-		&i.ServiceID,
-		&i.JobName,
-		&i.Enabled,
-		&i.RailwayMemoryUpscaleThreshold,
-		&i.RailwayCpuUpscaleThreshold,
-		&i.RailwayMemoryDownscaleThreshold,
-		&i.RailwayCpuDownscaleThreshold,
-		&i.UpscaleCooldown,
-		&i.DownscaleCooldown,
-		&i.MinReplicaCount,
-		&i.MaxReplicaCount,
-	)
-	return i, err
-}
-
-const getServicesByJobName = `-- name: GetServicesByJobName :many
-SELECT
-    service_id,
-    job_name,
+    max_replica_count,
+    pinned_replica_count,
     enabled,
-    railway_memory_upscale_threshold,
-    railway_cpu_upscale_threshold,
-    railway_memory_downscale_threshold,
-    railway_cpu_downscale_threshold,
-    upscale_cooldown,
-    downscale_cooldown,
-    min_replica_count,
-    max_replica_count
-FROM services
-WHERE job_name = $1
-ORDER BY service_id
+    created_at,
+    updated_at
+FROM service_schedules
+WHERE service_id = $1
+ORDER BY id
 `
 
-func (q *Queries) GetServicesByJobName(ctx context.Context, jobName sql.NullString) ([]Service, error) {
-	rows, err := q.db.QueryContext(ctx, getServicesByJobName, jobName)
+func (q *Queries) ListServiceSchedulesByServiceID(ctx context.Context, serviceID string) ([]ServiceSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listServiceSchedulesByServiceID, serviceID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Service
+	var items []ServiceSchedule
 	for rows.Next() {
-		var i Service
+		var i ServiceSchedule
 		if err := rows.Scan(
+			&i.ID,
 			&i.ServiceID,
-			&i.JobName,
-			&i.Enabled,
-			&i.RailwayMemoryUpscaleThreshold,
-			&i.RailwayCpuUpscaleThreshold,
-			&i.RailwayMemoryDownscaleThreshold,
-			&i.RailwayCpuDownscaleThreshold,
-			&i.UpscaleCooldown,
-			&i.DownscaleCooldown,
+			&i.Name,
+			&i.CronExpression,
+			&i.Duration,
+			&i.Timezone,
 			&i.MinReplicaCount,
 			&i.MaxReplicaCount,
+			&i.PinnedReplicaCount,
+			&i.Enabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -207,7 +1077,21 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 ORDER BY service_id
 `
@@ -233,6 +1117,20 @@ func (q *Queries) ListServices(ctx context.Context) ([]Service, error) {
 			&i.DownscaleCooldown,
 			&i.MinReplicaCount,
 			&i.MaxReplicaCount,
+			&i.Mode,
+			&i.Policy,
+			&i.TargetCpuUtilization,
+			&i.TargetMemoryUtilization,
+			&i.MaxScaleUpStep,
+			&i.MaxScaleDownStep,
+			&i.ScaleDownStabilization,
+			&i.ProjectID,
+			&i.EnvironmentID,
+			&i.SeasonalPeriod,
+			&i.PredictiveLeadTime,
+			&i.ReplicaGroup,
+			&i.Priority,
+			&i.ReplicaHourlyCost,
 		); err != nil {
 			return nil, err
 		}
@@ -259,7 +1157,21 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 WHERE job_name IS NOT NULL
 ORDER BY service_id
@@ -286,6 +1198,20 @@ func (q *Queries) ListServicesWithJobs(ctx context.Context) ([]Service, error) {
 			&i.DownscaleCooldown,
 			&i.MinReplicaCount,
 			&i.MaxReplicaCount,
+			&i.Mode,
+			&i.Policy,
+			&i.TargetCpuUtilization,
+			&i.TargetMemoryUtilization,
+			&i.MaxScaleUpStep,
+			&i.MaxScaleDownStep,
+			&i.ScaleDownStabilization,
+			&i.ProjectID,
+			&i.EnvironmentID,
+			&i.SeasonalPeriod,
+			&i.PredictiveLeadTime,
+			&i.ReplicaGroup,
+			&i.Priority,
+			&i.ReplicaHourlyCost,
 		); err != nil {
 			return nil, err
 		}
@@ -300,6 +1226,15 @@ func (q *Queries) ListServicesWithJobs(ctx context.Context) ([]Service, error) {
 	return items, nil
 }
 
+const setApplicationName = `-- name: SetApplicationName :exec
+SELECT set_config('application_name', $1::text, false)
+`
+
+func (q *Queries) SetApplicationName(ctx context.Context, applicationName string) error {
+	_, err := q.db.ExecContext(ctx, setApplicationName, applicationName)
+	return err
+}
+
 const setServiceEnabled = `-- name: SetServiceEnabled :one
 UPDATE services
 SET enabled = $1
@@ -314,7 +1249,21 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 `
 
 type SetServiceEnabledParams struct {
@@ -333,6 +1282,20 @@ type SetServiceEnabledRow struct {
 	DownscaleCooldown               string         `json:"downscale_cooldown"`
 	MinReplicaCount                 int32          `json:"min_replica_count"`
 	MaxReplicaCount                 int32          `json:"max_replica_count"`
+	Mode                            string         `json:"mode"`
+	Policy                          string         `json:"policy"`
+	TargetCpuUtilization            float64        `json:"target_cpu_utilization"`
+	TargetMemoryUtilization         float64        `json:"target_memory_utilization"`
+	MaxScaleUpStep                  int32          `json:"max_scale_up_step"`
+	MaxScaleDownStep                int32          `json:"max_scale_down_step"`
+	ScaleDownStabilization          string         `json:"scale_down_stabilization"`
+	ProjectID                       string         `json:"project_id"`
+	EnvironmentID                   string         `json:"environment_id"`
+	SeasonalPeriod                  string         `json:"seasonal_period"`
+	PredictiveLeadTime              string         `json:"predictive_lead_time"`
+	ReplicaGroup                    string         `json:"replica_group"`
+	Priority                        int32          `json:"priority"`
+	ReplicaHourlyCost               float64        `json:"replica_hourly_cost"`
 }
 
 func (q *Queries) SetServiceEnabled(ctx context.Context, arg SetServiceEnabledParams) (SetServiceEnabledRow, error) {
@@ -349,24 +1312,255 @@ func (q *Queries) SetServiceEnabled(ctx context.Context, arg SetServiceEnabledPa
 		&i.DownscaleCooldown,
 		&i.MinReplicaCount,
 		&i.MaxReplicaCount,
+		&i.Mode,
+		&i.Policy,
+		&i.TargetCpuUtilization,
+		&i.TargetMemoryUtilization,
+		&i.MaxScaleUpStep,
+		&i.MaxScaleDownStep,
+		&i.ScaleDownStabilization,
+		&i.ProjectID,
+		&i.EnvironmentID,
+		&i.SeasonalPeriod,
+		&i.PredictiveLeadTime,
+		&i.ReplicaGroup,
+		&i.Priority,
+		&i.ReplicaHourlyCost,
 	)
 	return i, err
 }
 
-const updateService = `-- name: UpdateService :one
-UPDATE services
+const tryLeaderLock = `-- name: TryLeaderLock :one
+SELECT pg_try_advisory_lock($1::bigint)
+`
+
+func (q *Queries) TryLeaderLock(ctx context.Context, lockKey int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryLeaderLock, lockKey)
+	var pg_try_advisory_lock bool
+	err := row.Scan(&pg_try_advisory_lock)
+	return pg_try_advisory_lock, err
+}
+
+const updateScalingEventStatus = `-- name: UpdateScalingEventStatus :exec
+UPDATE scaling_events
 SET
-    job_name = $2,
-    enabled = $3,
-    railway_memory_upscale_threshold = $4,
-    railway_cpu_upscale_threshold = $5,
-    railway_memory_downscale_threshold = $6,
-    railway_cpu_downscale_threshold = $7,
-    upscale_cooldown = $8,
-    downscale_cooldown = $9,
-    min_replica_count = $10,
-    max_replica_count = $11
-WHERE service_id = $1
+    status = $2,
+    message = $3
+WHERE id = $1
+`
+
+type UpdateScalingEventStatusParams struct {
+	ID      int64  `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func (q *Queries) UpdateScalingEventStatus(ctx context.Context, arg UpdateScalingEventStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateScalingEventStatus, arg.ID, arg.Status, arg.Message)
+	return err
+}
+
+const updateServiceSchedule = `-- name: UpdateServiceSchedule :one
+UPDATE service_schedules
+SET
+    name = $3,
+    cron_expression = $4,
+    duration = $5,
+    timezone = $6,
+    min_replica_count = $7,
+    max_replica_count = $8,
+    pinned_replica_count = $9,
+    enabled = $10,
+    updated_at = $11
+WHERE id = $1 AND service_id = $2
+RETURNING
+    id,
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at
+`
+
+type UpdateServiceScheduleParams struct {
+	ID                 int64         `json:"id"`
+	ServiceID          string        `json:"service_id"`
+	Name               string        `json:"name"`
+	CronExpression     string        `json:"cron_expression"`
+	Duration           string        `json:"duration"`
+	Timezone           string        `json:"timezone"`
+	MinReplicaCount    sql.NullInt32 `json:"min_replica_count"`
+	MaxReplicaCount    sql.NullInt32 `json:"max_replica_count"`
+	PinnedReplicaCount sql.NullInt32 `json:"pinned_replica_count"`
+	Enabled            bool          `json:"enabled"`
+	UpdatedAt          int64         `json:"updated_at"`
+}
+
+func (q *Queries) UpdateServiceSchedule(ctx context.Context, arg UpdateServiceScheduleParams) (ServiceSchedule, error) {
+	row := q.db.QueryRowContext(ctx, updateServiceSchedule,
+		arg.ID,
+		arg.ServiceID,
+		arg.Name,
+		arg.CronExpression,
+		arg.Duration,
+		arg.Timezone,
+		arg.MinReplicaCount,
+		arg.MaxReplicaCount,
+		arg.PinnedReplicaCount,
+		arg.Enabled,
+		arg.UpdatedAt,
+	)
+	var i ServiceSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.ServiceID,
+		&i.Name,
+		&i.CronExpression,
+		&i.Duration,
+		&i.Timezone,
+		&i.MinReplicaCount,
+		&i.MaxReplicaCount,
+		&i.PinnedReplicaCount,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertReplicaBudget = `-- name: UpsertReplicaBudget :one
+INSERT INTO replica_budgets (
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (replica_group) DO UPDATE
+SET
+    max_replicas = EXCLUDED.max_replicas,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at
+`
+
+type UpsertReplicaBudgetParams struct {
+	ReplicaGroup string `json:"replica_group"`
+	MaxReplicas  int32  `json:"max_replicas"`
+	CreatedAt    int64  `json:"created_at"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
+func (q *Queries) UpsertReplicaBudget(ctx context.Context, arg UpsertReplicaBudgetParams) (ReplicaBudget, error) {
+	row := q.db.QueryRowContext(ctx, upsertReplicaBudget,
+		arg.ReplicaGroup,
+		arg.MaxReplicas,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i ReplicaBudget
+	err := row.Scan(
+		&i.ReplicaGroup,
+		&i.MaxReplicas,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertService = `-- name: UpsertService :one
+INSERT INTO services (
+    service_id,
+    job_name,
+    enabled,
+    railway_memory_upscale_threshold,
+    railway_cpu_upscale_threshold,
+    railway_memory_downscale_threshold,
+    railway_cpu_downscale_threshold,
+    upscale_cooldown,
+    downscale_cooldown,
+    min_replica_count,
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14,
+    $15,
+    $16,
+    $17,
+    $18,
+    $19,
+    $20,
+    $21,
+    $22,
+    $23,
+    $24,
+    $25
+)
+ON CONFLICT (service_id) DO UPDATE
+SET
+    job_name = EXCLUDED.job_name,
+    enabled = EXCLUDED.enabled,
+    railway_memory_upscale_threshold = EXCLUDED.railway_memory_upscale_threshold,
+    railway_cpu_upscale_threshold = EXCLUDED.railway_cpu_upscale_threshold,
+    railway_memory_downscale_threshold = EXCLUDED.railway_memory_downscale_threshold,
+    railway_cpu_downscale_threshold = EXCLUDED.railway_cpu_downscale_threshold,
+    upscale_cooldown = EXCLUDED.upscale_cooldown,
+    downscale_cooldown = EXCLUDED.downscale_cooldown,
+    min_replica_count = EXCLUDED.min_replica_count,
+    max_replica_count = EXCLUDED.max_replica_count,
+    mode = EXCLUDED.mode,
+    policy = EXCLUDED.policy,
+    target_cpu_utilization = EXCLUDED.target_cpu_utilization,
+    target_memory_utilization = EXCLUDED.target_memory_utilization,
+    max_scale_up_step = EXCLUDED.max_scale_up_step,
+    max_scale_down_step = EXCLUDED.max_scale_down_step,
+    scale_down_stabilization = EXCLUDED.scale_down_stabilization,
+    project_id = EXCLUDED.project_id,
+    environment_id = EXCLUDED.environment_id,
+    seasonal_period = EXCLUDED.seasonal_period,
+    predictive_lead_time = EXCLUDED.predictive_lead_time,
+    replica_group = EXCLUDED.replica_group,
+    priority = EXCLUDED.priority,
+    replica_hourly_cost = EXCLUDED.replica_hourly_cost
 RETURNING
     service_id,
     job_name,
@@ -378,10 +1572,24 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 `
 
-type UpdateServiceParams struct {
+type UpsertServiceParams struct {
 	ServiceID                       string         `json:"service_id"`
 	JobName                         sql.NullString `json:"job_name"`
 	Enabled                         bool           `json:"enabled"`
@@ -393,10 +1601,24 @@ type UpdateServiceParams struct {
 	DownscaleCooldown               string         `json:"downscale_cooldown"`
 	MinReplicaCount                 int32          `json:"min_replica_count"`
 	MaxReplicaCount                 int32          `json:"max_replica_count"`
+	Mode                            string         `json:"mode"`
+	Policy                          string         `json:"policy"`
+	TargetCpuUtilization            float64        `json:"target_cpu_utilization"`
+	TargetMemoryUtilization         float64        `json:"target_memory_utilization"`
+	MaxScaleUpStep                  int32          `json:"max_scale_up_step"`
+	MaxScaleDownStep                int32          `json:"max_scale_down_step"`
+	ScaleDownStabilization          string         `json:"scale_down_stabilization"`
+	ProjectID                       string         `json:"project_id"`
+	EnvironmentID                   string         `json:"environment_id"`
+	SeasonalPeriod                  string         `json:"seasonal_period"`
+	PredictiveLeadTime              string         `json:"predictive_lead_time"`
+	ReplicaGroup                    string         `json:"replica_group"`
+	Priority                        int32          `json:"priority"`
+	ReplicaHourlyCost               float64        `json:"replica_hourly_cost"`
 }
 
-func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (Service, error) {
-	row := q.db.QueryRowContext(ctx, updateService,
+func (q *Queries) UpsertService(ctx context.Context, arg UpsertServiceParams) (Service, error) {
+	row := q.db.QueryRowContext(ctx, upsertService,
 		arg.ServiceID,
 		arg.JobName,
 		arg.Enabled,
@@ -408,6 +1630,20 @@ func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (S
 		arg.DownscaleCooldown,
 		arg.MinReplicaCount,
 		arg.MaxReplicaCount,
+		arg.Mode,
+		arg.Policy,
+		arg.TargetCpuUtilization,
+		arg.TargetMemoryUtilization,
+		arg.MaxScaleUpStep,
+		arg.MaxScaleDownStep,
+		arg.ScaleDownStabilization,
+		arg.ProjectID,
+		arg.EnvironmentID,
+		arg.SeasonalPeriod,
+		arg.PredictiveLeadTime,
+		arg.ReplicaGroup,
+		arg.Priority,
+		arg.ReplicaHourlyCost,
 	)
 	var i Service
 	err := row.Scan(
@@ -422,6 +1658,299 @@ func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (S
 		&i.DownscaleCooldown,
 		&i.MinReplicaCount,
 		&i.MaxReplicaCount,
+		&i.Mode,
+		&i.Policy,
+		&i.TargetCpuUtilization,
+		&i.TargetMemoryUtilization,
+		&i.MaxScaleUpStep,
+		&i.MaxScaleDownStep,
+		&i.ScaleDownStabilization,
+		&i.ProjectID,
+		&i.EnvironmentID,
+		&i.SeasonalPeriod,
+		&i.PredictiveLeadTime,
+		&i.ReplicaGroup,
+		&i.Priority,
+		&i.ReplicaHourlyCost,
+	)
+	return i, err
+}
+
+const upsertServiceMetric = `-- name: UpsertServiceMetric :one
+INSERT INTO service_metrics (
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+ON CONFLICT (service_id, name) DO UPDATE
+SET
+    provider = EXCLUDED.provider,
+    query = EXCLUDED.query,
+    target_value = EXCLUDED.target_value,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at
+`
+
+type UpsertServiceMetricParams struct {
+	ServiceID   string  `json:"service_id"`
+	Name        string  `json:"name"`
+	Provider    string  `json:"provider"`
+	Query       string  `json:"query"`
+	TargetValue float64 `json:"target_value"`
+	CreatedAt   int64   `json:"created_at"`
+	UpdatedAt   int64   `json:"updated_at"`
+}
+
+func (q *Queries) UpsertServiceMetric(ctx context.Context, arg UpsertServiceMetricParams) (ServiceMetric, error) {
+	row := q.db.QueryRowContext(ctx, upsertServiceMetric,
+		arg.ServiceID,
+		arg.Name,
+		arg.Provider,
+		arg.Query,
+		arg.TargetValue,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i ServiceMetric
+	err := row.Scan(
+		&i.ServiceID,
+		&i.Name,
+		&i.Provider,
+		&i.Query,
+		&i.TargetValue,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertServiceMetricRollup = `-- name: UpsertServiceMetricRollup :exec
+INSERT INTO service_metric_rollups (
+    service_id,
+    region,
+    bucket_start,
+    cpu_demand_sum,
+    memory_demand_sum,
+    samples
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    1
+)
+ON CONFLICT (service_id, region, bucket_start) DO UPDATE
+SET
+    cpu_demand_sum = service_metric_rollups.cpu_demand_sum + EXCLUDED.cpu_demand_sum,
+    memory_demand_sum = service_metric_rollups.memory_demand_sum + EXCLUDED.memory_demand_sum,
+    samples = service_metric_rollups.samples + 1
+`
+
+type UpsertServiceMetricRollupParams struct {
+	ServiceID       string  `json:"service_id"`
+	Region          string  `json:"region"`
+	BucketStart     int64   `json:"bucket_start"`
+	CpuDemandSum    float64 `json:"cpu_demand_sum"`
+	MemoryDemandSum float64 `json:"memory_demand_sum"`
+}
+
+func (q *Queries) UpsertServiceMetricRollup(ctx context.Context, arg UpsertServiceMetricRollupParams) error {
+	_, err := q.db.ExecContext(ctx, upsertServiceMetricRollup,
+		arg.ServiceID,
+		arg.Region,
+		arg.BucketStart,
+		arg.CpuDemandSum,
+		arg.MemoryDemandSum,
+	)
+	return err
+}
+
+const upsertServiceOverride = `-- name: UpsertServiceOverride :one
+INSERT INTO service_overrides (
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (service_id) DO UPDATE
+SET
+    replica_count = EXCLUDED.replica_count,
+    reason = EXCLUDED.reason,
+    expires_at = EXCLUDED.expires_at,
+    created_at = EXCLUDED.created_at
+RETURNING
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+`
+
+type UpsertServiceOverrideParams struct {
+	ServiceID    string `json:"service_id"`
+	ReplicaCount int32  `json:"replica_count"`
+	Reason       string `json:"reason"`
+	ExpiresAt    int64  `json:"expires_at"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+func (q *Queries) UpsertServiceOverride(ctx context.Context, arg UpsertServiceOverrideParams) (ServiceOverride, error) {
+	row := q.db.QueryRowContext(ctx, upsertServiceOverride,
+		arg.ServiceID,
+		arg.ReplicaCount,
+		arg.Reason,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+	)
+	var i ServiceOverride
+	err := row.Scan(
+		&i.ServiceID,
+		&i.ReplicaCount,
+		&i.Reason,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertServiceRegion = `-- name: UpsertServiceRegion :one
+INSERT INTO service_regions (
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (service_id, region) DO UPDATE
+SET
+    min_replica_count = EXCLUDED.min_replica_count,
+    max_replica_count = EXCLUDED.max_replica_count,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at
+`
+
+type UpsertServiceRegionParams struct {
+	ServiceID       string `json:"service_id"`
+	Region          string `json:"region"`
+	MinReplicaCount int32  `json:"min_replica_count"`
+	MaxReplicaCount int32  `json:"max_replica_count"`
+	CreatedAt       int64  `json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+func (q *Queries) UpsertServiceRegion(ctx context.Context, arg UpsertServiceRegionParams) (ServiceRegion, error) {
+	row := q.db.QueryRowContext(ctx, upsertServiceRegion,
+		arg.ServiceID,
+		arg.Region,
+		arg.MinReplicaCount,
+		arg.MaxReplicaCount,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i ServiceRegion
+	err := row.Scan(
+		&i.ServiceID,
+		&i.Region,
+		&i.MinReplicaCount,
+		&i.MaxReplicaCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const upsertServiceScalingState = `-- name: UpsertServiceScalingState :exec
+INSERT INTO service_scaling_states (
+    service_id,
+    last_upscale_at,
+    last_downscale_at,
+    consecutive_high_load,
+    consecutive_low_load,
+    updated_at,
+    region,
+    recommendations
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+ON CONFLICT (service_id, region) DO UPDATE
+SET
+    last_upscale_at = EXCLUDED.last_upscale_at,
+    last_downscale_at = EXCLUDED.last_downscale_at,
+    consecutive_high_load = EXCLUDED.consecutive_high_load,
+    consecutive_low_load = EXCLUDED.consecutive_low_load,
+    updated_at = EXCLUDED.updated_at,
+    recommendations = EXCLUDED.recommendations
+`
+
+type UpsertServiceScalingStateParams struct {
+	ServiceID           string          `json:"service_id"`
+	LastUpscaleAt       int64           `json:"last_upscale_at"`
+	LastDownscaleAt     int64           `json:"last_downscale_at"`
+	ConsecutiveHighLoad int32           `json:"consecutive_high_load"`
+	ConsecutiveLowLoad  int32           `json:"consecutive_low_load"`
+	UpdatedAt           int64           `json:"updated_at"`
+	Region              string          `json:"region"`
+	Recommendations     json.RawMessage `json:"recommendations"`
+}
+
+func (q *Queries) UpsertServiceScalingState(ctx context.Context, arg UpsertServiceScalingStateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertServiceScalingState,
+		arg.ServiceID,
+		arg.LastUpscaleAt,
+		arg.LastDownscaleAt,
+		arg.ConsecutiveHighLoad,
+		arg.ConsecutiveLowLoad,
+		arg.UpdatedAt,
+		arg.Region,
+		arg.Recommendations,
+	)
+	return err
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	messagebus "github.com/ferretcode/switchyard/autoscale/internal/message_bus"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type WebhookService struct {
+	Logger            *slog.Logger
+	Config            *types.Config
+	Context           context.Context
+	MessageBusService *messagebus.MessageBusService
+}
+
+// NewWebhookService takes a nil messageBusService when MESSAGE_BUS_URL isn't set
+func NewWebhookService(logger *slog.Logger, config *types.Config, context context.Context, messageBusService *messagebus.MessageBusService) WebhookService {
+	return WebhookService{
+		Logger:            logger,
+		Config:            config,
+		Context:           context,
+		MessageBusService: messageBusService,
+	}
+}
+
+// SendScalingNotification posts the notification to SCALING_EVENT_WEBHOOK_URL if it's set,
+// and publishes it to the scaling-events queue if there's a message bus. Each is tried
+// whether or not the other failed
+func (w *WebhookService) SendScalingNotification(notification types.ScalingNotification) error {
+	var webhookErr, messageBusErr error
+
+	if w.Config.ScalingEventWebhookUrl != "" {
+		webhookErr = w.postScalingNotification(notification)
+	}
+
+	if w.MessageBusService != nil {
+		if err := w.MessageBusService.SendScalingNotificationMessage(notification); err != nil {
+			messageBusErr = fmt.Errorf("error publishing scaling notification: %w", err)
+		}
+	}
+
+	return errors.Join(webhookErr, messageBusErr)
+}
+
+func (w *WebhookService) postScalingNotification(notification types.ScalingNotification) error {
+	notificationBytes, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	w.Logger.Info("sending scaling notification to url", "url", w.Config.ScalingEventWebhookUrl)
+
+	ctx, cancel := context.WithTimeout(w.Context, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		w.Config.ScalingEventWebhookUrl,
+		bytes.NewReader(notificationBytes),
+	)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	headers := strings.SplitSeq(w.Config.ScalingEventAdditionalHeaders, ";")
+	for header := range headers {
+		pair := strings.Split(header, "=")
+
+		if len(pair) == 2 {
+			req.Header.Set(pair[0], pair[1])
+		}
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending scaling notification: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("scaling notification webhook returned %s", res.Status)
+	}
+
+	return nil
+}
@@ -1,14 +1,17 @@
 package types
 
 import (
+	"sync"
 	"time"
 
 	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Config struct {
 	Port                            string        `env:"PORT" json:"port,omitempty"`
 	RailwayApiKey                   string        `env:"RAILWAY_API_KEY" json:"railway_api_key,omitempty"`
+	RailwayApiUrl                   string        `env:"RAILWAY_API_URL" envDefault:"https://backboard.railway.app/graphql/v2" json:"railway_api_url,omitempty"`
 	RailwayProjectId                string        `env:"RAILWAY_PROJECT_ID" json:"railway_project_id,omitempty"`
 	RailwayEnvironmentId            string        `env:"RAILWAY_ENVIRONMENT_ID" json:"railway_environment_id,omitempty"`
 	RailwayMemoryUpscaleThreshold   float64       `env:"RAILWAY_MEMORY_UPSCALE_THRESHOLD" json:"railway_memory_upscale_threshold,omitempty"`
@@ -17,12 +20,27 @@ type Config struct {
 	RailwayCpuDownscaleThreshold    float64       `env:"RAILWAY_CPU_DOWNSCALE_THRESHOLD" json:"railway_cpu_downscale_threshold,omitempty"`
 	RailwaySelectedRegion           string        `env:"RAILWAY_SELECTED_REGION" json:"railway_selected_region,omitempty"`
 	MonitoringInterval              time.Duration `env:"MONITORING_INTERVAL" json:"monitoring_interval,omitempty"`
+	AutoscaleWorkers                int           `env:"AUTOSCALE_WORKERS" envDefault:"8" json:"autoscale_workers,omitempty"`
+	ServiceEvaluationTimeout        time.Duration `env:"SERVICE_EVALUATION_TIMEOUT" envDefault:"30s" json:"service_evaluation_timeout,omitempty"`
+	InstanceId                      string        `env:"INSTANCE_ID" json:"instance_id,omitempty"`
+	LeaderElectionInterval          time.Duration `env:"LEADER_ELECTION_INTERVAL" envDefault:"5s" json:"leader_election_interval,omitempty"`
 	MetricHistorySize               int           `env:"METRIC_HISTORY_SIZE" json:"metric_history_size,omitempty"`
 	UpscaleCooldown                 time.Duration `env:"UPSCALE_COOLDOWN" json:"upscale_cooldown,omitempty"`
 	DownscaleCooldown               time.Duration `env:"DOWNSCALE_COOLDOWN" json:"downscale_cooldown,omitempty"`
 	MinReplicaCount                 int           `env:"MIN_REPLICA_COUNT" json:"min_replica_count,omitempty"`
 	MaxReplicaCount                 int           `env:"MAX_REPLICA_COUNT" json:"max_replica_count,omitempty"`
 	DatabaseUrl                     string        `env:"DATABASE_URL" json:"database_url,omitempty"`
+	PrometheusUrl                   string        `env:"PROMETHEUS_URL" json:"prometheus_url,omitempty"`
+	RecordNoOpScalingEvents         bool          `env:"RECORD_NO_OP_SCALING_EVENTS" json:"record_no_op_scaling_events,omitempty"`
+	ScalingMutationRetries          int           `env:"SCALING_MUTATION_RETRIES" envDefault:"3" json:"scaling_mutation_retries,omitempty"`
+	ScalingVerificationInterval     time.Duration `env:"SCALING_VERIFICATION_INTERVAL" envDefault:"15s" json:"scaling_verification_interval,omitempty"`
+	ScalingVerificationTimeout      time.Duration `env:"SCALING_VERIFICATION_TIMEOUT" envDefault:"10m" json:"scaling_verification_timeout,omitempty"`
+	SeasonalHistoryRetention        time.Duration `env:"SEASONAL_HISTORY_RETENTION" envDefault:"672h" json:"seasonal_history_retention,omitempty"`
+	MaxTotalReplicas                int           `env:"MAX_TOTAL_REPLICAS" json:"max_total_replicas,omitempty"`
+	ReplicaHourlyCost               float64       `env:"REPLICA_HOURLY_COST" json:"replica_hourly_cost,omitempty"`
+	MessageBusUrl                   string        `env:"MESSAGE_BUS_URL" json:"message_bus_url,omitempty"`
+	ScalingEventWebhookUrl          string        `env:"SCALING_EVENT_WEBHOOK_URL" json:"scaling_event_webhook_url,omitempty"`
+	ScalingEventAdditionalHeaders   string        `env:"SCALING_EVENT_ADDITIONAL_HEADERS" json:"scaling_event_additional_headers,omitempty"`
 }
 
 type MetricHistory struct {
@@ -31,20 +49,97 @@ type MetricHistory struct {
 	Times  []time.Time
 }
 
-type ServiceHistoryCache struct {
-	ServiceHistories map[string]MetricHistory
+// ServiceState is everything the autoscaler remembers about a service between ticks
+type ServiceState struct {
+	LastUpscaleTime     time.Time
+	LastDownscaleTime   time.Time
+	ConsecutiveHighLoad int
+	ConsecutiveLowLoad  int
+	History             MetricHistory
+	Recommendations     []ReplicaRecommendation
+	// PendingScalingEventId is the scaling event whose replicas aren't live yet, 0 if there is none
+	PendingScalingEventId int64
+	// CapacityBound is set once on-call has been told the service is overloaded at its max
+	// replicas, so they're told again only after it has recovered
+	CapacityBound bool
+	Mutex         sync.Mutex
+}
+
+// ReplicaRecommendation is the replica count target tracking wanted at a point in time,
+// kept for the scale-down stabilization window
+type ReplicaRecommendation struct {
+	Replicas int       `json:"replicas"`
+	Time     time.Time `json:"time"`
+}
+
+type ServiceStateCache struct {
+	ServiceStates map[string]*ServiceState
+	Mutex         sync.Mutex
+}
+
+type PrometheusMetrics struct {
+	Replicas               *prometheus.GaugeVec
+	CpuUtilization         *prometheus.GaugeVec
+	MemoryUtilization      *prometheus.GaugeVec
+	CpuWeightedAverage     *prometheus.GaugeVec
+	MemoryWeightedAverage  *prometheus.GaugeVec
+	CpuTrend               *prometheus.GaugeVec
+	MemoryTrend            *prometheus.GaugeVec
+	ScalingActions         *prometheus.CounterVec
+	RailwayRequestDuration *prometheus.HistogramVec
+	RailwayRequestErrors   *prometheus.CounterVec
+	TickDuration           prometheus.Histogram
+	TickOverruns           prometheus.Counter
 }
 
 type ScalingContext struct {
-	CpuPercent      float64
-	MemPercent      float64
-	AvgCpu          float64
-	AvgMem          float64
-	HasCpuSpike     bool
-	HasMemSpike     bool
-	CpuTrend        float64
-	MemTrend        float64
-	CurrentReplicas int
-	Now             time.Time
-	Service         repositories.Service
+	CpuPercent      float64              `json:"cpu_percent"`
+	MemPercent      float64              `json:"mem_percent"`
+	AvgCpu          float64              `json:"avg_cpu"`
+	AvgMem          float64              `json:"avg_mem"`
+	HasCpuSpike     bool                 `json:"has_cpu_spike"`
+	HasMemSpike     bool                 `json:"has_mem_spike"`
+	CpuTrend        float64              `json:"cpu_trend"`
+	MemTrend        float64              `json:"mem_trend"`
+	CurrentReplicas int                  `json:"current_replicas"`
+	Region          string               `json:"region,omitempty"`
+	Now             time.Time            `json:"now"`
+	Service         repositories.Service `json:"service"`
+	Metrics         []MetricSample       `json:"metrics"`
+	// Forecast is only set for the predictive policy, once there's enough history for one
+	Forecast *Forecast `json:"forecast,omitempty"`
+}
+
+// Forecast is the highest demand expected between now and the service's lead time, from the
+// seasonal averages of previous days or weeks. Demand is utilization summed over replicas,
+// e.g. 1.5 is three replicas at 50%
+type Forecast struct {
+	CpuDemand    float64   `json:"cpu_demand"`
+	MemoryDemand float64   `json:"memory_demand"`
+	Until        time.Time `json:"until"`
+}
+
+// MetricSample is the value of one of a service's custom metrics at evaluation time
+type MetricSample struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Target float64 `json:"target"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// ScalingNotification tells on-call that the autoscaler scaled a service, couldn't scale it,
+// or wanted to scale it past its max replicas
+type ScalingNotification struct {
+	Type          string  `json:"type"`
+	ServiceId     string  `json:"service_id"`
+	EnvironmentId string  `json:"environment_id"`
+	Region        string  `json:"region"`
+	OldReplicas   int     `json:"old_replicas"`
+	NewReplicas   int     `json:"new_replicas"`
+	MaxReplicas   int     `json:"max_replicas"`
+	Reason        string  `json:"reason"`
+	Message       string  `json:"message,omitempty"`
+	CpuPercent    float64 `json:"cpu_percent,omitempty"`
+	MemPercent    float64 `json:"mem_percent,omitempty"`
+	Timestamp     int64   `json:"timestamp"`
 }
@@ -10,7 +10,21 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 WHERE service_id = $1
 LIMIT 1;
@@ -29,7 +43,21 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count;
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost;
 
 -- name: ListServices :many
 SELECT
@@ -43,11 +71,25 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 ORDER BY service_id;
 
--- name: CreateService :one
+-- name: UpsertService :one
 INSERT INTO services (
     service_id,
     job_name,
@@ -59,7 +101,21 @@ INSERT INTO services (
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 ) VALUES (
     $1,
     $2,
@@ -71,35 +127,48 @@ INSERT INTO services (
     $8,
     $9,
     $10,
-    $11
+    $11,
+    $12,
+    $13,
+    $14,
+    $15,
+    $16,
+    $17,
+    $18,
+    $19,
+    $20,
+    $21,
+    $22,
+    $23,
+    $24,
+    $25
 )
-RETURNING
-    service_id,
-    job_name,
-    enabled,
-    railway_memory_upscale_threshold,
-    railway_cpu_upscale_threshold,
-    railway_memory_downscale_threshold,
-    railway_cpu_downscale_threshold,
-    upscale_cooldown,
-    downscale_cooldown,
-    min_replica_count,
-    max_replica_count;
-
--- name: UpdateService :one
-UPDATE services
+ON CONFLICT (service_id) DO UPDATE
 SET
-    job_name = COALESCE($2, job_name),
-    enabled = COALESCE($3, enabled),
-    railway_memory_upscale_threshold = COALESCE($4, railway_memory_upscale_threshold),
-    railway_cpu_upscale_threshold = COALESCE($5, railway_cpu_upscale_threshold),
-    railway_memory_downscale_threshold = COALESCE($6, railway_memory_downscale_threshold),
-    railway_cpu_downscale_threshold = COALESCE($7, railway_cpu_downscale_threshold),
-    upscale_cooldown = COALESCE($8, upscale_cooldown),
-    downscale_cooldown = COALESCE($9, downscale_cooldown),
-    min_replica_count = COALESCE($10, min_replica_count),
-    max_replica_count = COALESCE($11, max_replica_count)
-WHERE service_id = $1
+    job_name = EXCLUDED.job_name,
+    enabled = EXCLUDED.enabled,
+    railway_memory_upscale_threshold = EXCLUDED.railway_memory_upscale_threshold,
+    railway_cpu_upscale_threshold = EXCLUDED.railway_cpu_upscale_threshold,
+    railway_memory_downscale_threshold = EXCLUDED.railway_memory_downscale_threshold,
+    railway_cpu_downscale_threshold = EXCLUDED.railway_cpu_downscale_threshold,
+    upscale_cooldown = EXCLUDED.upscale_cooldown,
+    downscale_cooldown = EXCLUDED.downscale_cooldown,
+    min_replica_count = EXCLUDED.min_replica_count,
+    max_replica_count = EXCLUDED.max_replica_count,
+    mode = EXCLUDED.mode,
+    policy = EXCLUDED.policy,
+    target_cpu_utilization = EXCLUDED.target_cpu_utilization,
+    target_memory_utilization = EXCLUDED.target_memory_utilization,
+    max_scale_up_step = EXCLUDED.max_scale_up_step,
+    max_scale_down_step = EXCLUDED.max_scale_down_step,
+    scale_down_stabilization = EXCLUDED.scale_down_stabilization,
+    project_id = EXCLUDED.project_id,
+    environment_id = EXCLUDED.environment_id,
+    seasonal_period = EXCLUDED.seasonal_period,
+    predictive_lead_time = EXCLUDED.predictive_lead_time,
+    replica_group = EXCLUDED.replica_group,
+    priority = EXCLUDED.priority,
+    replica_hourly_cost = EXCLUDED.replica_hourly_cost
 RETURNING
     service_id,
     job_name,
@@ -111,12 +180,37 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count;
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost;
 
 -- name: DeleteService :exec
 DELETE FROM services
 WHERE service_id = $1;
 
+-- name: CountServiceDependents :one
+SELECT
+    (SELECT COUNT(*) FROM service_schedules WHERE service_schedules.service_id = $1) AS schedules,
+    (SELECT COUNT(*) FROM service_regions WHERE service_regions.service_id = $1) AS regions,
+    (SELECT COUNT(*) FROM service_metrics WHERE service_metrics.service_id = $1) AS metrics,
+    (SELECT COUNT(*) FROM service_overrides WHERE service_overrides.service_id = $1) AS overrides,
+    (SELECT COUNT(*) FROM service_scaling_states WHERE service_scaling_states.service_id = $1) AS scaling_states,
+    (SELECT COUNT(*) FROM service_metric_samples WHERE service_metric_samples.service_id = $1) AS metric_samples,
+    (SELECT COUNT(*) FROM service_metric_rollups WHERE service_metric_rollups.service_id = $1) AS metric_rollups,
+    (SELECT COUNT(*) FROM scaling_events WHERE scaling_events.service_id = $1) AS scaling_events;
+
 -- name: GetServicesByJobName :many
 SELECT
     service_id,
@@ -129,7 +223,21 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 WHERE job_name = $1
 ORDER BY service_id;
@@ -146,7 +254,508 @@ SELECT
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count
+    max_replica_count,
+    mode,
+    policy,
+    target_cpu_utilization,
+    target_memory_utilization,
+    max_scale_up_step,
+    max_scale_down_step,
+    scale_down_stabilization,
+    project_id,
+    environment_id,
+    seasonal_period,
+    predictive_lead_time,
+    replica_group,
+    priority,
+    replica_hourly_cost
 FROM services
 WHERE job_name IS NOT NULL
 ORDER BY service_id;
+
+-- name: ListServiceScalingStates :many
+SELECT
+    service_id,
+    last_upscale_at,
+    last_downscale_at,
+    consecutive_high_load,
+    consecutive_low_load,
+    updated_at,
+    region,
+    recommendations
+FROM service_scaling_states
+ORDER BY service_id, region;
+
+-- name: UpsertServiceScalingState :exec
+INSERT INTO service_scaling_states (
+    service_id,
+    last_upscale_at,
+    last_downscale_at,
+    consecutive_high_load,
+    consecutive_low_load,
+    updated_at,
+    region,
+    recommendations
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+ON CONFLICT (service_id, region) DO UPDATE
+SET
+    last_upscale_at = EXCLUDED.last_upscale_at,
+    last_downscale_at = EXCLUDED.last_downscale_at,
+    consecutive_high_load = EXCLUDED.consecutive_high_load,
+    consecutive_low_load = EXCLUDED.consecutive_low_load,
+    updated_at = EXCLUDED.updated_at,
+    recommendations = EXCLUDED.recommendations;
+
+-- name: ListServiceMetricSamples :many
+SELECT
+    id,
+    service_id,
+    cpu_percent,
+    memory_percent,
+    sampled_at,
+    region
+FROM service_metric_samples
+ORDER BY service_id, region, sampled_at;
+
+-- name: CreateServiceMetricSample :exec
+INSERT INTO service_metric_samples (
+    service_id,
+    cpu_percent,
+    memory_percent,
+    sampled_at,
+    region
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: DeleteOldServiceMetricSamples :exec
+DELETE FROM service_metric_samples
+WHERE service_id = $1 AND region = $2
+AND id NOT IN (
+    SELECT id FROM service_metric_samples
+    WHERE service_id = $1 AND region = $2
+    ORDER BY sampled_at DESC
+    LIMIT $3
+);
+
+-- name: UpsertServiceMetricRollup :exec
+INSERT INTO service_metric_rollups (
+    service_id,
+    region,
+    bucket_start,
+    cpu_demand_sum,
+    memory_demand_sum,
+    samples
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    1
+)
+ON CONFLICT (service_id, region, bucket_start) DO UPDATE
+SET
+    cpu_demand_sum = service_metric_rollups.cpu_demand_sum + EXCLUDED.cpu_demand_sum,
+    memory_demand_sum = service_metric_rollups.memory_demand_sum + EXCLUDED.memory_demand_sum,
+    samples = service_metric_rollups.samples + 1;
+
+-- name: ListServiceMetricRollups :many
+SELECT
+    service_id,
+    region,
+    bucket_start,
+    cpu_demand_sum,
+    memory_demand_sum,
+    samples
+FROM service_metric_rollups
+WHERE service_id = $1 AND region = $2 AND bucket_start = ANY($3::BIGINT[])
+ORDER BY bucket_start;
+
+-- name: DeleteOldServiceMetricRollups :execrows
+DELETE FROM service_metric_rollups
+WHERE bucket_start < $1;
+
+-- name: CreateScalingEvent :one
+INSERT INTO scaling_events (
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region;
+
+-- name: ListScalingEventsByServiceID :many
+SELECT
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+FROM scaling_events
+WHERE service_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListScalingEventsByServiceIDSince :many
+SELECT
+    id,
+    service_id,
+    old_replicas,
+    new_replicas,
+    reason,
+    status,
+    message,
+    scaling_context,
+    created_at,
+    region
+FROM scaling_events
+WHERE service_id = $1 AND created_at >= $2
+ORDER BY created_at, id;
+
+-- name: UpdateScalingEventStatus :exec
+UPDATE scaling_events
+SET
+    status = $2,
+    message = $3
+WHERE id = $1;
+
+-- name: FailPendingScalingEvents :exec
+UPDATE scaling_events
+SET
+    status = 'failed',
+    message = $1
+WHERE status = 'pending';
+
+-- name: ListServiceSchedulesByServiceID :many
+SELECT
+    id,
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at
+FROM service_schedules
+WHERE service_id = $1
+ORDER BY id;
+
+-- name: CreateServiceSchedule :one
+INSERT INTO service_schedules (
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+)
+RETURNING
+    id,
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at;
+
+-- name: UpdateServiceSchedule :one
+UPDATE service_schedules
+SET
+    name = $3,
+    cron_expression = $4,
+    duration = $5,
+    timezone = $6,
+    min_replica_count = $7,
+    max_replica_count = $8,
+    pinned_replica_count = $9,
+    enabled = $10,
+    updated_at = $11
+WHERE id = $1 AND service_id = $2
+RETURNING
+    id,
+    service_id,
+    name,
+    cron_expression,
+    duration,
+    timezone,
+    min_replica_count,
+    max_replica_count,
+    pinned_replica_count,
+    enabled,
+    created_at,
+    updated_at;
+
+-- name: DeleteServiceSchedule :execrows
+DELETE FROM service_schedules
+WHERE id = $1 AND service_id = $2;
+
+-- name: ListServiceMetricsByServiceID :many
+SELECT
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at
+FROM service_metrics
+WHERE service_id = $1
+ORDER BY name;
+
+-- name: UpsertServiceMetric :one
+INSERT INTO service_metrics (
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+ON CONFLICT (service_id, name) DO UPDATE
+SET
+    provider = EXCLUDED.provider,
+    query = EXCLUDED.query,
+    target_value = EXCLUDED.target_value,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    service_id,
+    name,
+    provider,
+    query,
+    target_value,
+    created_at,
+    updated_at;
+
+-- name: DeleteServiceMetric :execrows
+DELETE FROM service_metrics
+WHERE service_id = $1 AND name = $2;
+
+-- name: ListServiceRegionsByServiceID :many
+SELECT
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at
+FROM service_regions
+WHERE service_id = $1
+ORDER BY region;
+
+-- name: UpsertServiceRegion :one
+INSERT INTO service_regions (
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (service_id, region) DO UPDATE
+SET
+    min_replica_count = EXCLUDED.min_replica_count,
+    max_replica_count = EXCLUDED.max_replica_count,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    service_id,
+    region,
+    min_replica_count,
+    max_replica_count,
+    created_at,
+    updated_at;
+
+-- name: DeleteServiceRegion :execrows
+DELETE FROM service_regions
+WHERE service_id = $1 AND region = $2;
+
+-- name: TryLeaderLock :one
+SELECT pg_try_advisory_lock(@lock_key::bigint);
+
+-- name: SetApplicationName :exec
+SELECT set_config('application_name', @application_name::text, false);
+
+-- name: GetLeaderLockHolder :one
+SELECT a.application_name
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory'
+    AND l.granted
+    AND l.classid = 0
+    AND l.objid = @lock_key::bigint::oid
+    AND l.objsubid = 1;
+
+-- name: UpsertServiceOverride :one
+INSERT INTO service_overrides (
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (service_id) DO UPDATE
+SET
+    replica_count = EXCLUDED.replica_count,
+    reason = EXCLUDED.reason,
+    expires_at = EXCLUDED.expires_at,
+    created_at = EXCLUDED.created_at
+RETURNING
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at;
+
+-- name: GetActiveServiceOverride :one
+SELECT
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+FROM service_overrides
+WHERE service_id = $1 AND expires_at > $2;
+
+-- name: ListActiveServiceOverrides :many
+SELECT
+    service_id,
+    replica_count,
+    reason,
+    expires_at,
+    created_at
+FROM service_overrides
+WHERE expires_at > $1;
+
+-- name: DeleteServiceOverride :execrows
+DELETE FROM service_overrides
+WHERE service_id = $1;
+
+-- name: DeleteExpiredServiceOverrides :execrows
+DELETE FROM service_overrides
+WHERE expires_at <= $1;
+
+-- name: UpsertReplicaBudget :one
+INSERT INTO replica_budgets (
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (replica_group) DO UPDATE
+SET
+    max_replicas = EXCLUDED.max_replicas,
+    updated_at = EXCLUDED.updated_at
+RETURNING
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at;
+
+-- name: ListReplicaBudgets :many
+SELECT
+    replica_group,
+    max_replicas,
+    created_at,
+    updated_at
+FROM replica_budgets
+ORDER BY replica_group;
+
+-- name: DeleteReplicaBudget :execrows
+DELETE FROM replica_budgets
+WHERE replica_group = $1;
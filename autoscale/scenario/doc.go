@@ -0,0 +1,3 @@
+// Package scenario runs the autoscaler's evaluation loop against railway-fake. It's a
+// module of its own so railway-fake stays out of the autoscaler's dependencies
+package scenario
@@ -0,0 +1,345 @@
+package scenario
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/autoscale/internal/autoscale"
+	"github.com/ferretcode/switchyard/autoscale/internal/metrics"
+	"github.com/ferretcode/switchyard/autoscale/internal/prometheus"
+	"github.com/ferretcode/switchyard/autoscale/internal/railway"
+	"github.com/ferretcode/switchyard/autoscale/internal/repositories"
+	"github.com/ferretcode/switchyard/autoscale/pkg/types"
+	railwayfake "github.com/ferretcode/switchyard/railway-fake"
+)
+
+const (
+	scenarioProjectId     = "project"
+	scenarioEnvironmentId = "production"
+	scenarioServiceId     = "api"
+	scenarioRegion        = "us-west2"
+)
+
+// the collectors are registered globally, so every scenario shares them
+var prometheusMetrics = sync.OnceValue(prometheus.Init)
+
+// fakeQueries keeps the tables an evaluation touches in memory. Anything else panics,
+// since the embedded Querier is nil
+type fakeQueries struct {
+	repositories.Querier
+
+	mutex         sync.Mutex
+	service       repositories.Service
+	scalingEvents []repositories.ScalingEvent
+	evaluations   int
+}
+
+func (f *fakeQueries) ListServices(ctx context.Context) ([]repositories.Service, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.evaluations++
+
+	return []repositories.Service{f.service}, nil
+}
+
+func (f *fakeQueries) ListReplicaBudgets(ctx context.Context) ([]repositories.ReplicaBudget, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) DeleteExpiredServiceOverrides(ctx context.Context, expiresAt int64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeQueries) DeleteOldServiceMetricRollups(ctx context.Context, bucketStart int64) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeQueries) ListServiceRegionsByServiceID(ctx context.Context, serviceID string) ([]repositories.ServiceRegion, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) ListServiceMetricsByServiceID(ctx context.Context, serviceID string) ([]repositories.ServiceMetric, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) GetActiveServiceOverride(ctx context.Context, arg repositories.GetActiveServiceOverrideParams) (repositories.ServiceOverride, error) {
+	return repositories.ServiceOverride{}, sql.ErrNoRows
+}
+
+func (f *fakeQueries) ListServiceSchedulesByServiceID(ctx context.Context, serviceID string) ([]repositories.ServiceSchedule, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) CreateServiceMetricSample(ctx context.Context, arg repositories.CreateServiceMetricSampleParams) error {
+	return nil
+}
+
+func (f *fakeQueries) DeleteOldServiceMetricSamples(ctx context.Context, arg repositories.DeleteOldServiceMetricSamplesParams) error {
+	return nil
+}
+
+func (f *fakeQueries) UpsertServiceMetricRollup(ctx context.Context, arg repositories.UpsertServiceMetricRollupParams) error {
+	return nil
+}
+
+func (f *fakeQueries) UpsertServiceScalingState(ctx context.Context, arg repositories.UpsertServiceScalingStateParams) error {
+	return nil
+}
+
+func (f *fakeQueries) ListServiceScalingStates(ctx context.Context) ([]repositories.ServiceScalingState, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) ListServiceMetricSamples(ctx context.Context) ([]repositories.ServiceMetricSample, error) {
+	return nil, nil
+}
+
+func (f *fakeQueries) FailPendingScalingEvents(ctx context.Context, message string) error {
+	return nil
+}
+
+func (f *fakeQueries) CreateScalingEvent(ctx context.Context, arg repositories.CreateScalingEventParams) (repositories.ScalingEvent, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	scalingEvent := repositories.ScalingEvent{
+		ID:             int64(len(f.scalingEvents) + 1),
+		ServiceID:      arg.ServiceID,
+		OldReplicas:    arg.OldReplicas,
+		NewReplicas:    arg.NewReplicas,
+		Reason:         arg.Reason,
+		Status:         arg.Status,
+		Message:        arg.Message,
+		ScalingContext: arg.ScalingContext,
+		CreatedAt:      arg.CreatedAt,
+		Region:         arg.Region,
+	}
+	f.scalingEvents = append(f.scalingEvents, scalingEvent)
+
+	return scalingEvent, nil
+}
+
+func (f *fakeQueries) UpdateScalingEventStatus(ctx context.Context, arg repositories.UpdateScalingEventStatusParams) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.scalingEvents[arg.ID-1].Status = arg.Status
+	f.scalingEvents[arg.ID-1].Message = arg.Message
+
+	return nil
+}
+
+// settled reports whether the service has been evaluated at least evaluations times,
+// with no scaling left waiting on Railway
+func (f *fakeQueries) settled(evaluations int) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, scalingEvent := range f.scalingEvents {
+		if scalingEvent.Status == "pending" {
+			return false
+		}
+	}
+
+	return f.evaluations >= evaluations
+}
+
+func (f *fakeQueries) hasScalingEvent(status string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, scalingEvent := range f.scalingEvents {
+		if scalingEvent.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newScenario serves a fake Railway project with one service, and an autoscaler pointed at it
+// that manages the service with the default config and the given policy
+func newScenario(t *testing.T, replicas int, policy string) (*railwayfake.Server, *fakeQueries, *autoscale.AutoscaleService) {
+	t.Helper()
+
+	fake := railwayfake.NewServer()
+	fake.AddProject(scenarioProjectId, "switchyard")
+
+	if err := fake.AddEnvironment(scenarioProjectId, scenarioEnvironmentId, "production"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AddService(scenarioProjectId, scenarioServiceId, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AddServiceInstance(scenarioServiceId, scenarioEnvironmentId, map[string]int{scenarioRegion: replicas}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	gqlClient, err := railway.NewClient(&railway.GraphQLClient{AuthToken: "token", BaseURL: server.URL, Logger: logger})
+	if err != nil {
+		t.Fatalf("error creating railway client: %v", err)
+	}
+
+	config := &types.Config{
+		RailwayProjectId:            scenarioProjectId,
+		RailwayEnvironmentId:        scenarioEnvironmentId,
+		RailwaySelectedRegion:       scenarioRegion,
+		MonitoringInterval:          20 * time.Millisecond,
+		AutoscaleWorkers:            1,
+		ServiceEvaluationTimeout:    time.Second,
+		MetricHistorySize:           30,
+		ScalingMutationRetries:      1,
+		ScalingVerificationInterval: 10 * time.Millisecond,
+		ScalingVerificationTimeout:  2 * time.Second,
+	}
+
+	queries := &fakeQueries{
+		service: repositories.Service{
+			ServiceID:                       scenarioServiceId,
+			Enabled:                         true,
+			RailwayMemoryUpscaleThreshold:   0.8,
+			RailwayCpuUpscaleThreshold:      0.8,
+			RailwayMemoryDownscaleThreshold: 0.2,
+			RailwayCpuDownscaleThreshold:    0.2,
+			UpscaleCooldown:                 "0s",
+			DownscaleCooldown:               "0s",
+			MinReplicaCount:                 1,
+			MaxReplicaCount:                 10,
+			Mode:                            "active",
+			Policy:                          policy,
+			TargetCpuUtilization:            0.6,
+			TargetMemoryUtilization:         0.7,
+			MaxScaleUpStep:                  4,
+			MaxScaleDownStep:                1,
+			ScaleDownStabilization:          "0s",
+			ProjectID:                       scenarioProjectId,
+			EnvironmentID:                   scenarioEnvironmentId,
+			SeasonalPeriod:                  "daily",
+			PredictiveLeadTime:              "15m",
+		},
+	}
+
+	prometheusMetrics := prometheusMetrics()
+
+	gqlQueries := railway.NewQueryService(gqlClient, t.Context(), *config, logger, &prometheusMetrics)
+	railwayMetrics := metrics.NewRailwayProvider(&gqlQueries)
+
+	a := autoscale.NewAutoscaleService(
+		logger,
+		config,
+		&gqlQueries,
+		queries,
+		nil,
+		t.Context(),
+		&types.ServiceStateCache{ServiceStates: map[string]*types.ServiceState{}},
+		&railwayMetrics,
+		nil,
+		&prometheusMetrics,
+		nil,
+	)
+
+	if err := a.LoadServiceStates(); err != nil {
+		t.Fatalf("error loading service states: %v", err)
+	}
+
+	return fake, queries, &a
+}
+
+// runUntil runs the autoscaler until done reports true, or fails the test after a while
+func runUntil(t *testing.T, a *autoscale.AutoscaleService, done func() bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		a.StartAutoscaling(ctx)
+	}()
+
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	deadline := time.After(10 * time.Second)
+	for !done() {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the autoscaler")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestScenarioTargetTrackingFollowsLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int
+		// cpu is in vCPUs, spread over the replicas at 8 vCPUs each
+		cpu  float64
+		want int
+	}{
+		{name: "steady load", replicas: 2, cpu: 8, want: 2},
+		// 6 replicas at 58% is the only count within the tolerance of the 60% target
+		{name: "load spike", replicas: 1, cpu: 28, want: 6},
+		{name: "load spike beyond max", replicas: 2, cpu: 200, want: 10},
+		{name: "load drop", replicas: 6, cpu: 4, want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake, queries, a := newScenario(t, test.replicas, "target-tracking")
+
+			if err := fake.SetLoad(scenarioServiceId, scenarioEnvironmentId, scenarioRegion, test.cpu, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			// enough evaluations to fill the metric history with the new load
+			runUntil(t, a, func() bool { return queries.settled(60) })
+
+			if replicas := fake.Replicas(scenarioServiceId, scenarioEnvironmentId, scenarioRegion); replicas != test.want {
+				t.Errorf("got %d replicas, want %d", replicas, test.want)
+			}
+		})
+	}
+}
+
+func TestScenarioFailedDeployment(t *testing.T) {
+	fake, queries, a := newScenario(t, 1, "target-tracking")
+	fake.SetDeployDuration(time.Hour)
+
+	if err := fake.SetLoad(scenarioServiceId, scenarioEnvironmentId, scenarioRegion, 20, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	crashed := false
+	runUntil(t, a, func() bool {
+		// crashes the redeploy the autoscaler started to scale up
+		if !crashed && fake.Calls(railwayfake.OperationDeploy) > 0 {
+			if err := fake.SetDeploymentStatus(scenarioServiceId, scenarioEnvironmentId, railwayfake.StatusCrashed); err != nil {
+				t.Fatal(err)
+			}
+			crashed = true
+		}
+
+		return queries.hasScalingEvent("failed")
+	})
+
+	// the crashed deployment never went live, so the old replicas are still running
+	if replicas := fake.Replicas(scenarioServiceId, scenarioEnvironmentId, scenarioRegion); replicas != 1 {
+		t.Errorf("got %d live replicas, want 1", replicas)
+	}
+}
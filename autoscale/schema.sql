@@ -10,5 +10,126 @@ CREATE TABLE services (
     upscale_cooldown VARCHAR(255) NOT NULL DEFAULT '1m',
     downscale_cooldown VARCHAR(255) NOT NULL DEFAULT '2m',
     min_replica_count INTEGER NOT NULL DEFAULT 1,
-    max_replica_count INTEGER NOT NULL DEFAULT 10
+    max_replica_count INTEGER NOT NULL DEFAULT 10,
+    mode VARCHAR(255) NOT NULL DEFAULT 'active',
+
+    policy VARCHAR(255) NOT NULL DEFAULT 'heuristic',
+    target_cpu_utilization DOUBLE PRECISION NOT NULL DEFAULT 0.60,
+    target_memory_utilization DOUBLE PRECISION NOT NULL DEFAULT 0.70,
+    max_scale_up_step INTEGER NOT NULL DEFAULT 4,
+    max_scale_down_step INTEGER NOT NULL DEFAULT 1,
+    scale_down_stabilization VARCHAR(255) NOT NULL DEFAULT '5m',
+
+    project_id VARCHAR(255) NOT NULL DEFAULT '',
+    environment_id VARCHAR(255) NOT NULL DEFAULT '',
+
+    seasonal_period VARCHAR(255) NOT NULL DEFAULT 'daily',
+    predictive_lead_time VARCHAR(255) NOT NULL DEFAULT '15m',
+
+    replica_group VARCHAR(255) NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    replica_hourly_cost DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+CREATE TABLE replica_budgets (
+    replica_group VARCHAR(255) PRIMARY KEY,
+    max_replicas INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE TABLE service_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    cron_expression VARCHAR(255) NOT NULL,
+    duration VARCHAR(255) NOT NULL,
+    timezone VARCHAR(255) NOT NULL DEFAULT 'UTC',
+    min_replica_count INTEGER,
+    max_replica_count INTEGER,
+    pinned_replica_count INTEGER,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_service_schedules_service_id ON service_schedules(service_id);
+
+CREATE TABLE service_metrics (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(255) NOT NULL,
+    query TEXT NOT NULL,
+    target_value DOUBLE PRECISION NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (service_id, name)
+);
+
+CREATE TABLE service_overrides (
+    service_id VARCHAR(255) PRIMARY KEY REFERENCES services(service_id) ON DELETE CASCADE,
+    replica_count INTEGER NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
 );
+
+CREATE TABLE service_regions (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    region VARCHAR(255) NOT NULL,
+    min_replica_count INTEGER NOT NULL,
+    max_replica_count INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (service_id, region)
+);
+
+CREATE TABLE service_scaling_states (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    last_upscale_at BIGINT NOT NULL DEFAULT 0,
+    last_downscale_at BIGINT NOT NULL DEFAULT 0,
+    consecutive_high_load INTEGER NOT NULL DEFAULT 0,
+    consecutive_low_load INTEGER NOT NULL DEFAULT 0,
+    updated_at BIGINT NOT NULL,
+    recommendations JSONB NOT NULL DEFAULT '[]',
+    region VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (service_id, region)
+);
+
+CREATE TABLE service_metric_samples (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    cpu_percent DOUBLE PRECISION NOT NULL,
+    memory_percent DOUBLE PRECISION NOT NULL,
+    sampled_at BIGINT NOT NULL,
+    region VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_service_metric_samples_service_id_region_sampled_at ON service_metric_samples(service_id, region, sampled_at);
+
+CREATE TABLE service_metric_rollups (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    region VARCHAR(255) NOT NULL DEFAULT '',
+    bucket_start BIGINT NOT NULL,
+    cpu_demand_sum DOUBLE PRECISION NOT NULL,
+    memory_demand_sum DOUBLE PRECISION NOT NULL,
+    samples INTEGER NOT NULL,
+    PRIMARY KEY (service_id, region, bucket_start)
+);
+
+CREATE INDEX idx_service_metric_rollups_bucket_start ON service_metric_rollups(bucket_start);
+
+CREATE TABLE scaling_events (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    old_replicas INTEGER NOT NULL,
+    new_replicas INTEGER NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    scaling_context JSONB NOT NULL,
+    created_at BIGINT NOT NULL,
+    region VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_scaling_events_service_id_created_at ON scaling_events(service_id, created_at);
@@ -5,6 +5,7 @@ type Config struct {
 	Environment          string `env:"ENVIRONMENT"`
 	DatabaseUrl          string `env:"DATABASE_URL"`
 	RailwayApiKey        string `env:"RAILWAY_API_KEY"`
+	RailwayApiUrl        string `env:"RAILWAY_API_URL" envDefault:"https://backboard.railway.app/graphql/v2"`
 	RailwayProjectId     string `env:"RAILWAY_PROJECT_ID"`
 	RailwayEnvironmentId string `env:"RAILWAY_ENVIRONMENT_ID"`
 }
@@ -126,6 +126,14 @@ func main() {
 			r.Patch("/toggle-service-registered", func(w http.ResponseWriter, r *http.Request) {
 				handleError(autoscaleService.ToggleServiceRegistered(w, r), w, "autoscale/toggle")
 			})
+
+			r.Get("/services/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+				handleError(autoscaleService.ListServiceEvents(w, r), w, "autoscale/list-events")
+			})
+
+			r.Get("/services/{id}/forecast", func(w http.ResponseWriter, r *http.Request) {
+				handleError(autoscaleService.GetServiceForecast(w, r), w, "autoscale/forecast")
+			})
 		})
 
 		r.Route("/feature-flags", func(r chi.Router) {
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/ferretcode/switchyard/dashboard/internal/types"
+	"github.com/go-chi/chi/v5"
 )
 
 type AutoscaleService struct {
@@ -115,9 +116,12 @@ func (a *AutoscaleService) ToggleServiceRegistered(w http.ResponseWriter, r *htt
 }
 
 func (a *AutoscaleService) ListServices(w http.ResponseWriter, r *http.Request) error {
-	req, err := http.NewRequest(
-		"GET", a.Config.AutoscaleServiceUrl+"/autoscale/list-services", nil,
-	)
+	url := a.Config.AutoscaleServiceUrl + "/autoscale/list-services"
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return err
 	}
@@ -145,6 +149,24 @@ func (a *AutoscaleService) ListServices(w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
+func (a *AutoscaleService) ListServiceEvents(w http.ResponseWriter, r *http.Request) error {
+	url := a.Config.AutoscaleServiceUrl + "/autoscale/services/" + chi.URLParam(r, "id") + "/events"
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+
+	return PropagateRequest(w, r, "GET", url)
+}
+
+func (a *AutoscaleService) GetServiceForecast(w http.ResponseWriter, r *http.Request) error {
+	url := a.Config.AutoscaleServiceUrl + "/autoscale/services/" + chi.URLParam(r, "id") + "/forecast"
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+
+	return PropagateRequest(w, r, "GET", url)
+}
+
 func newBoolPtr(value bool) *bool {
 	b := value
 	return &b
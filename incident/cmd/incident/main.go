@@ -67,7 +67,7 @@ func main() {
 
 	gqlClient, err := railway.NewClient(&railway.GraphQLClient{
 		AuthToken: config.RailwayApiKey,
-		BaseURL:   "https://backboard.railway.app/graphql/v2",
+		BaseURL:   config.RailwayApiUrl,
 	})
 	if err != nil {
 		logger.Error("error creating graphql client", "err", err)
@@ -10,10 +10,15 @@ import (
 
 	"github.com/ferretcode/switchyard/incident/internal/railway"
 	"github.com/ferretcode/switchyard/incident/internal/railway/gql"
-	"github.com/ferretcode/switchyard/incident/internal/webhook"
 	"github.com/ferretcode/switchyard/incident/pkg/types"
 )
 
+// IncidentReporter is told about the deployment status changes the monitor is interested in.
+// It's the webhook service outside of tests
+type IncidentReporter interface {
+	SendDeploymentIncidentReport(message string, serviceId string, deploymentId string, environmentId string) error
+}
+
 type ServiceMonitorService struct {
 	Logger             *slog.Logger
 	Config             *types.Config
@@ -21,10 +26,10 @@ type ServiceMonitorService struct {
 	PrometheusCounters *types.PrometheusCounters
 	GraphQLClient      *railway.GraphQLClient
 	DeploymentCache    *types.DeploymentCache
-	WebhookService     *webhook.WebhookService
+	WebhookService     IncidentReporter
 }
 
-func NewServiceMonitorService(logger *slog.Logger, incidentStats *types.IncidentStats, config *types.Config, prometheusCounters *types.PrometheusCounters, graphQLClient *railway.GraphQLClient, deploymentCache *types.DeploymentCache, webhookService *webhook.WebhookService) ServiceMonitorService {
+func NewServiceMonitorService(logger *slog.Logger, incidentStats *types.IncidentStats, config *types.Config, prometheusCounters *types.PrometheusCounters, graphQLClient *railway.GraphQLClient, deploymentCache *types.DeploymentCache, webhookService IncidentReporter) ServiceMonitorService {
 	return ServiceMonitorService{
 		Logger:             logger,
 		IncidentStats:      incidentStats,
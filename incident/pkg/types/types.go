@@ -17,6 +17,7 @@ type Config struct {
 	ServiceMonitorPollingTimeout          time.Duration `env:"SERVICE_MONITOR_POLLING_TIMEOUT" json:"service_monitor_polling_timeout,omitempty"`
 	ServiceMonitorInterestedStatusChanges []string      `env:"SERVICE_MONITOR_INTERESTED_STATUS_CHANGES" json:"service_monitor_interested_status_changes,omitempty"`
 	RailwayApiKey                         string        `env:"RAILWAY_API_KEY" json:"railway_api_key,omitempty"`
+	RailwayApiUrl                         string        `env:"RAILWAY_API_URL" envDefault:"https://backboard.railway.app/graphql/v2" json:"railway_api_url,omitempty"`
 	RailwayEnvironmentId                  string        `env:"RAILWAY_ENVIRONMENT_ID" json:"railway_environment_id,omitempty"`
 	RailwayServiceIds                     []string      `env:"RAILWAY_SERVICE_IDS" json:"railway_service_ids,omitempty"`
 	IncidentReportWebhookUrl              string        `env:"INCIDENT_REPORT_WEBHOOK_URL" json:"incident_report_webhook_url,omitempty"`
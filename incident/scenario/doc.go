@@ -0,0 +1,3 @@
+// Package scenario runs the incident service's deployment monitor against railway-fake. It's
+// a module of its own so railway-fake stays out of the incident service's dependencies
+package scenario
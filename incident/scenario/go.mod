@@ -0,0 +1,28 @@
+module github.com/ferretcode/switchyard/incident/scenario
+
+go 1.24.3
+
+require (
+	github.com/ferretcode/switchyard/incident v0.0.0
+	github.com/ferretcode/switchyard/railway-fake v0.0.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/coder/websocket v1.8.13 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/hasura/go-graphql-client v0.14.4 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_golang v1.23.0 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.65.0 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+replace (
+	github.com/ferretcode/switchyard/incident => ../
+	github.com/ferretcode/switchyard/railway-fake => ../../railway-fake
+)
@@ -0,0 +1,136 @@
+package scenario
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/switchyard/incident/internal/railway"
+	servicemonitor "github.com/ferretcode/switchyard/incident/internal/service_monitor"
+	"github.com/ferretcode/switchyard/incident/pkg/types"
+	railwayfake "github.com/ferretcode/switchyard/railway-fake"
+)
+
+type recordingReporter struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (r *recordingReporter) SendDeploymentIncidentReport(message string, serviceId string, deploymentId string, environmentId string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *recordingReporter) take() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	messages := r.messages
+	r.messages = nil
+
+	return messages
+}
+
+// waitForPolls waits until the monitor has made another two requests for the service, so
+// at least one whole poll ran after whatever the caller changed
+func waitForPolls(t *testing.T, fake *railwayfake.Server) {
+	t.Helper()
+
+	want := fake.Calls(railwayfake.OperationService) + 2
+
+	deadline := time.After(10 * time.Second)
+	for fake.Calls(railwayfake.OperationService) < want {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the monitor to poll")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestScenarioDeploymentStatusChanges(t *testing.T) {
+	fake := railwayfake.NewServer()
+	fake.AddProject("project", "switchyard")
+
+	if err := fake.AddEnvironment("project", "production", "production"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AddService("project", "api", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AddServiceInstance("api", "production", map[string]int{"us-west2": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	gqlClient, err := railway.NewClient(&railway.GraphQLClient{AuthToken: "token", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("error creating railway client: %v", err)
+	}
+
+	reporter := &recordingReporter{}
+
+	monitor := servicemonitor.NewServiceMonitorService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&types.IncidentStats{},
+		&types.Config{
+			RailwayEnvironmentId:                  "production",
+			RailwayServiceIds:                     []string{"api"},
+			ServiceMonitorPollingRate:             10 * time.Millisecond,
+			ServiceMonitorPollingTimeout:          time.Second,
+			ServiceMonitorInterestedStatusChanges: []string{railwayfake.StatusCrashed, railwayfake.StatusFailed},
+		},
+		&types.PrometheusCounters{},
+		gqlClient,
+		&types.DeploymentCache{Deployments: map[string]string{}},
+		reporter,
+	)
+
+	done := make(chan bool)
+	defer close(done)
+
+	go monitor.MonitorServices(done)
+
+	steps := []struct {
+		name string
+		// status is set on the latest deployment before polling, unless it's empty
+		status string
+		// failure makes the next request for the service fail
+		failure  string
+		messages []string
+	}{
+		{name: "new deployment"},
+		{name: "unchanged"},
+		{name: "crash", status: railwayfake.StatusCrashed, messages: []string{"deployment status changed: SUCCESS -> CRASHED"}},
+		{name: "still crashed"},
+		{name: "railway failing", failure: "railway is down"},
+		{name: "uninteresting change", status: railwayfake.StatusRemoved},
+		{name: "crash after a failed poll", status: railwayfake.StatusCrashed, messages: []string{"deployment status changed: REMOVED -> CRASHED"}},
+	}
+
+	for _, step := range steps {
+		if step.status != "" {
+			if err := fake.SetDeploymentStatus("api", "production", step.status); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if step.failure != "" {
+			fake.FailNext(railwayfake.OperationService, step.failure)
+		}
+
+		waitForPolls(t, fake)
+
+		if messages := reporter.take(); !slices.Equal(messages, step.messages) {
+			t.Errorf("%s: got reports %v, want %v", step.name, messages, step.messages)
+		}
+	}
+}
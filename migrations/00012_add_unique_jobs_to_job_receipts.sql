@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE job_receipts
+    ADD COLUMN unique_key TEXT,
+    ADD COLUMN run_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_job_receipts_unique_key ON job_receipts(unique_key) WHERE unique_key IS NOT NULL;
+CREATE INDEX idx_job_receipts_scheduled_run_at ON job_receipts(run_at) WHERE status = 'scheduled';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_job_receipts_scheduled_run_at;
+DROP INDEX IF EXISTS idx_job_receipts_unique_key;
+
+ALTER TABLE job_receipts
+    DROP COLUMN unique_key,
+    DROP COLUMN run_at;
+-- +goose StatementEnd
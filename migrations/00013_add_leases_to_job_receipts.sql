@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE job_receipts
+    ADD COLUMN lease_id TEXT,
+    ADD COLUMN lease_expires_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_job_receipts_pending_job_name ON job_receipts(job_name, created_at) WHERE status = 'pending';
+CREATE INDEX idx_job_receipts_running_lease_expires_at ON job_receipts(lease_expires_at) WHERE status = 'running';
+
+CREATE TABLE leased_job_names (
+    job_name VARCHAR(255) NOT NULL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_leased_job_names_service_id ON leased_job_names(service_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS leased_job_names;
+
+DROP INDEX IF EXISTS idx_job_receipts_running_lease_expires_at;
+DROP INDEX IF EXISTS idx_job_receipts_pending_job_name;
+
+ALTER TABLE job_receipts
+    DROP COLUMN lease_id,
+    DROP COLUMN lease_expires_at;
+-- +goose StatementEnd
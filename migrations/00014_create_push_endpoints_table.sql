@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE push_endpoints (
+    job_name VARCHAR(255) NOT NULL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    timeout_ms BIGINT NOT NULL,
+    max_retries INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_push_endpoints_service_id ON push_endpoints(service_id);
+
+ALTER TABLE job_receipts
+    ADD COLUMN response_status_code INTEGER,
+    ADD COLUMN response_body TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE job_receipts
+    DROP COLUMN response_status_code,
+    DROP COLUMN response_body;
+
+DROP TABLE IF EXISTS push_endpoints;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE service_scaling_states (
+    service_id VARCHAR(255) NOT NULL PRIMARY KEY REFERENCES services(service_id) ON DELETE CASCADE,
+    last_upscale_at BIGINT NOT NULL DEFAULT 0,
+    last_downscale_at BIGINT NOT NULL DEFAULT 0,
+    consecutive_high_load INTEGER NOT NULL DEFAULT 0,
+    consecutive_low_load INTEGER NOT NULL DEFAULT 0,
+    updated_at BIGINT NOT NULL,
+    recommendations JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE service_metric_samples (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    cpu_percent DOUBLE PRECISION NOT NULL,
+    memory_percent DOUBLE PRECISION NOT NULL,
+    sampled_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_service_metric_samples_service_id_sampled_at ON service_metric_samples(service_id, sampled_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_metric_samples;
+DROP TABLE IF EXISTS service_scaling_states;
+-- +goose StatementEnd
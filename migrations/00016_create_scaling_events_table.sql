@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE scaling_events (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    old_replicas INTEGER NOT NULL,
+    new_replicas INTEGER NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    scaling_context JSONB NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_scaling_events_service_id_created_at ON scaling_events(service_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scaling_events;
+-- +goose StatementEnd
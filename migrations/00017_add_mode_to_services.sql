@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE services
+    ADD COLUMN mode VARCHAR(255) NOT NULL DEFAULT 'active';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE services
+    DROP COLUMN mode;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE service_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    cron_expression VARCHAR(255) NOT NULL,
+    duration VARCHAR(255) NOT NULL,
+    timezone VARCHAR(255) NOT NULL DEFAULT 'UTC',
+    min_replica_count INTEGER,
+    max_replica_count INTEGER,
+    pinned_replica_count INTEGER,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_service_schedules_service_id ON service_schedules(service_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_schedules;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE services
+    ADD COLUMN policy VARCHAR(255) NOT NULL DEFAULT 'heuristic',
+    ADD COLUMN target_cpu_utilization DOUBLE PRECISION NOT NULL DEFAULT 0.60,
+    ADD COLUMN target_memory_utilization DOUBLE PRECISION NOT NULL DEFAULT 0.70,
+    ADD COLUMN max_scale_up_step INTEGER NOT NULL DEFAULT 4,
+    ADD COLUMN max_scale_down_step INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN scale_down_stabilization VARCHAR(255) NOT NULL DEFAULT '5m';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE services
+    DROP COLUMN policy,
+    DROP COLUMN target_cpu_utilization,
+    DROP COLUMN target_memory_utilization,
+    DROP COLUMN max_scale_up_step,
+    DROP COLUMN max_scale_down_step,
+    DROP COLUMN scale_down_stabilization;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE service_regions (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    region VARCHAR(255) NOT NULL,
+    min_replica_count INTEGER NOT NULL,
+    max_replica_count INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (service_id, region)
+);
+
+-- state and samples recorded before regions existed belong to the default region, ''
+ALTER TABLE service_scaling_states
+    ADD COLUMN region VARCHAR(255) NOT NULL DEFAULT '',
+    DROP CONSTRAINT service_scaling_states_pkey,
+    ADD PRIMARY KEY (service_id, region);
+
+ALTER TABLE service_metric_samples
+    ADD COLUMN region VARCHAR(255) NOT NULL DEFAULT '';
+
+DROP INDEX idx_service_metric_samples_service_id_sampled_at;
+CREATE INDEX idx_service_metric_samples_service_id_region_sampled_at ON service_metric_samples(service_id, region, sampled_at);
+
+ALTER TABLE scaling_events
+    ADD COLUMN region VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE scaling_events
+    DROP COLUMN region;
+
+DROP INDEX idx_service_metric_samples_service_id_region_sampled_at;
+DELETE FROM service_metric_samples WHERE region <> '';
+ALTER TABLE service_metric_samples
+    DROP COLUMN region;
+CREATE INDEX idx_service_metric_samples_service_id_sampled_at ON service_metric_samples(service_id, sampled_at);
+
+DELETE FROM service_scaling_states WHERE region <> '';
+ALTER TABLE service_scaling_states
+    DROP CONSTRAINT service_scaling_states_pkey,
+    DROP COLUMN region,
+    ADD PRIMARY KEY (service_id);
+
+DROP TABLE IF EXISTS service_regions;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- empty means RAILWAY_PROJECT_ID and RAILWAY_ENVIRONMENT_ID, which is where services registered before this lived
+ALTER TABLE services
+    ADD COLUMN project_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN environment_id VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE services
+    DROP COLUMN project_id,
+    DROP COLUMN environment_id;
+-- +goose StatementEnd
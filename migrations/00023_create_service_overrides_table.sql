@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE service_overrides (
+    service_id VARCHAR(255) PRIMARY KEY REFERENCES services(service_id) ON DELETE CASCADE,
+    replica_count INTEGER NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_overrides;
+-- +goose StatementEnd
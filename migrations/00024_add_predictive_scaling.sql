@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE services
+    ADD COLUMN seasonal_period VARCHAR(255) NOT NULL DEFAULT 'daily',
+    ADD COLUMN predictive_lead_time VARCHAR(255) NOT NULL DEFAULT '15m';
+
+-- metric samples are only kept for METRIC_HISTORY_SIZE evaluations, so seasonal forecasts
+-- are made from these averages instead, kept for SEASONAL_HISTORY_RETENTION. Demand is
+-- utilization times the replicas running, so a forecast made from it doesn't depend on how
+-- many replicas there were in the past
+CREATE TABLE service_metric_rollups (
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    region VARCHAR(255) NOT NULL DEFAULT '',
+    bucket_start BIGINT NOT NULL,
+    cpu_demand_sum DOUBLE PRECISION NOT NULL,
+    memory_demand_sum DOUBLE PRECISION NOT NULL,
+    samples INTEGER NOT NULL,
+    PRIMARY KEY (service_id, region, bucket_start)
+);
+
+CREATE INDEX idx_service_metric_rollups_bucket_start ON service_metric_rollups(bucket_start);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_metric_rollups;
+
+ALTER TABLE services
+    DROP COLUMN seasonal_period,
+    DROP COLUMN predictive_lead_time;
+-- +goose StatementEnd
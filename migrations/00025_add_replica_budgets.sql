@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE services
+    ADD COLUMN replica_group VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN priority INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN replica_hourly_cost DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE replica_budgets (
+    replica_group VARCHAR(255) PRIMARY KEY,
+    max_replicas INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS replica_budgets;
+
+ALTER TABLE services
+    DROP COLUMN replica_group,
+    DROP COLUMN priority,
+    DROP COLUMN replica_hourly_cost;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+
+	railwayfake "github.com/ferretcode/switchyard/railway-fake"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	port := flag.String("port", "3000", "port to listen on")
+	seedPath := flag.String("seed", "", "JSON file with the projects, services and load to start with")
+	deployDuration := flag.Duration("deploy-duration", 0, "how long redeploys stay DEPLOYING before going live")
+	flag.Parse()
+
+	server := railwayfake.NewServer()
+	server.SetDeployDuration(*deployDuration)
+
+	if *seedPath != "" {
+		seedFile, err := os.Open(*seedPath)
+		if err != nil {
+			logger.Error("error opening seed file", "err", err)
+			return
+		}
+
+		seed, err := railwayfake.ReadSeed(seedFile)
+		seedFile.Close()
+		if err != nil {
+			logger.Error("error reading seed file", "err", err)
+			return
+		}
+
+		if err := server.Seed(seed); err != nil {
+			logger.Error("error seeding fake railway server", "err", err)
+			return
+		}
+	}
+
+	logger.Info("fake railway api listening", "url", "http://localhost:"+*port+"/graphql/v2")
+
+	mux := http.NewServeMux()
+	mux.Handle("/graphql/v2", server)
+
+	if err := http.ListenAndServe(":"+*port, mux); err != nil {
+		logger.Error("error starting http server", "err", err)
+	}
+}
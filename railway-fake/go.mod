@@ -0,0 +1,3 @@
+module github.com/ferretcode/switchyard/railway-fake
+
+go 1.24.3
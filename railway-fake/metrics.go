@@ -0,0 +1,103 @@
+package railwayfake
+
+import "slices"
+
+// the per replica limits SetLoad reports, matching a Railway hobby plan service
+const (
+	CpuLimitPerReplica      = 8
+	MemoryLimitGbPerReplica = 8
+)
+
+// MetricFunc returns the value of a measurement given how many replicas are live in the
+// region, so scripted metrics can react to the service being scaled
+type MetricFunc func(replicas int) float64
+
+func Constant(value float64) MetricFunc {
+	return func(int) float64 {
+		return value
+	}
+}
+
+// PerReplica is a measurement that grows with the replicas, like a limit
+func PerReplica(value float64) MetricFunc {
+	return func(replicas int) float64 {
+		return value * float64(replicas)
+	}
+}
+
+// SetMetric scripts a Railway measurement (e.g. CPU_USAGE or NETWORK_TX_GB) of a service
+// in one region. Queries that don't group by region get the sum over all regions
+func (s *Server) SetMetric(serviceId string, environmentId string, region string, measurement string, metric MetricFunc) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instance, err := s.serviceInstance(serviceId, environmentId)
+	if err != nil {
+		return err
+	}
+
+	instance.metrics[metricKey{region: region, measurement: measurement}] = metric
+
+	return nil
+}
+
+// SetLoad scripts a region's cpu (in vCPUs) and memory (in GB) usage as a fixed demand
+// spread over its replicas, so utilization falls as replicas are added
+func (s *Server) SetLoad(serviceId string, environmentId string, region string, cpu float64, memoryGb float64) error {
+	metrics := map[string]MetricFunc{
+		"CPU_USAGE":       Constant(cpu),
+		"MEMORY_USAGE_GB": Constant(memoryGb),
+		"CPU_LIMIT":       PerReplica(CpuLimitPerReplica),
+		"MEMORY_LIMIT_GB": PerReplica(MemoryLimitGbPerReplica),
+	}
+
+	for measurement, metric := range metrics {
+		if err := s.SetMetric(serviceId, environmentId, region, measurement, metric); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// metricValue evaluates a measurement in one region, or summed over every region when
+// region is empty
+func (s *Server) metricValue(instance *serviceInstance, region string, measurement string) (float64, bool) {
+	replicas := s.liveReplicas(instance)
+
+	if region != "" {
+		metric, ok := instance.metrics[metricKey{region: region, measurement: measurement}]
+		if !ok {
+			return 0, false
+		}
+
+		return metric(replicas[region]), true
+	}
+
+	value, found := 0.0, false
+
+	for key, metric := range instance.metrics {
+		if key.measurement != measurement {
+			continue
+		}
+
+		value += metric(replicas[key.region])
+		found = true
+	}
+
+	return value, found
+}
+
+func (s *Server) metricRegions(instance *serviceInstance) []string {
+	var regions []string
+
+	for key := range instance.metrics {
+		if !slices.Contains(regions, key.region) {
+			regions = append(regions, key.region)
+		}
+	}
+
+	slices.Sort(regions)
+
+	return regions
+}
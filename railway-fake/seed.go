@@ -0,0 +1,84 @@
+package railwayfake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Seed describes the projects a server starts with, so it can be set up from a file
+type Seed struct {
+	Projects []SeedProject `json:"projects"`
+}
+
+type SeedProject struct {
+	Id           string            `json:"id"`
+	Name         string            `json:"name"`
+	Environments []SeedEnvironment `json:"environments"`
+	Services     []SeedService     `json:"services"`
+}
+
+type SeedEnvironment struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SeedService struct {
+	Id        string                `json:"id"`
+	Name      string                `json:"name"`
+	Instances []SeedServiceInstance `json:"instances"`
+}
+
+type SeedServiceInstance struct {
+	EnvironmentId string              `json:"environment_id"`
+	Replicas      map[string]int      `json:"replicas"`
+	Load          map[string]SeedLoad `json:"load"`
+}
+
+// SeedLoad is passed to SetLoad for a region
+type SeedLoad struct {
+	Cpu      float64 `json:"cpu"`
+	MemoryGb float64 `json:"memory_gb"`
+}
+
+func ReadSeed(r io.Reader) (Seed, error) {
+	seed := Seed{}
+
+	if err := json.NewDecoder(r).Decode(&seed); err != nil {
+		return Seed{}, fmt.Errorf("error parsing seed: %w", err)
+	}
+
+	return seed, nil
+}
+
+func (s *Server) Seed(seed Seed) error {
+	for _, project := range seed.Projects {
+		s.AddProject(project.Id, project.Name)
+
+		for _, environment := range project.Environments {
+			if err := s.AddEnvironment(project.Id, environment.Id, environment.Name); err != nil {
+				return err
+			}
+		}
+
+		for _, service := range project.Services {
+			if err := s.AddService(project.Id, service.Id, service.Name); err != nil {
+				return err
+			}
+
+			for _, instance := range service.Instances {
+				if err := s.AddServiceInstance(service.Id, instance.EnvironmentId, instance.Replicas); err != nil {
+					return err
+				}
+
+				for region, load := range instance.Load {
+					if err := s.SetLoad(service.Id, instance.EnvironmentId, region, load.Cpu, load.MemoryGb); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,444 @@
+// Package railwayfake is an in-memory stand-in for the parts of Railway's GraphQL API
+// that the switchyard services use, so they can be run end to end without a Railway
+// account. Point a service's RAILWAY_API_URL at the server's URL
+package railwayfake
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// operations are named after the root field of the query or mutation
+const (
+	OperationProject         = "project"
+	OperationServiceInstance = "serviceInstance"
+	OperationMetrics         = "metrics"
+	OperationUpdate          = "serviceInstanceUpdate"
+	OperationDeploy          = "serviceInstanceDeployV2"
+	OperationService         = "service"
+	OperationVariablesUpsert = "variableCollectionUpsert"
+)
+
+type Server struct {
+	projects       map[string]*project
+	projectIds     []string
+	services       map[string]*service
+	variables      map[variablesKey]map[string]string
+	calls          map[string]int
+	failures       map[string][]string
+	deployDuration time.Duration
+	sequence       int
+	now            func() time.Time
+	mutex          sync.Mutex
+}
+
+type variablesKey struct {
+	serviceId     string
+	environmentId string
+}
+
+func NewServer() *Server {
+	return &Server{
+		projects:  map[string]*project{},
+		services:  map[string]*service{},
+		variables: map[variablesKey]map[string]string{},
+		calls:     map[string]int{},
+		failures:  map[string][]string{},
+		now:       time.Now,
+	}
+}
+
+type graphqlRequest struct {
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	request := graphqlRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "error parsing graphql request", http.StatusBadRequest)
+		return
+	}
+
+	operation := rootField(request.Query)
+
+	data, err := s.execute(operation, request)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]any{
+			"data":   nil,
+			"errors": []graphqlError{{Message: err.Error()}},
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{operation: data},
+	})
+}
+
+func (s *Server) execute(operation string, request graphqlRequest) (any, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls[operation]++
+
+	if failures := s.failures[operation]; len(failures) > 0 {
+		s.failures[operation] = failures[1:]
+		return nil, fmt.Errorf("%s", failures[0])
+	}
+
+	switch operation {
+	case OperationProject:
+		return s.project(request.Variables)
+	case OperationServiceInstance:
+		return s.serviceInstanceQuery(request.Variables)
+	case OperationMetrics:
+		// the grouping is written into the query rather than passed as a variable
+		return s.metrics(request.Variables, strings.Contains(request.Query, "REGION"))
+	case OperationUpdate:
+		return s.serviceInstanceUpdate(request.Variables)
+	case OperationDeploy:
+		return s.serviceInstanceDeploy(request.Variables)
+	case OperationService:
+		return s.service(request.Variables)
+	case OperationVariablesUpsert:
+		return s.variableCollectionUpsert(request.Variables)
+	}
+
+	return nil, fmt.Errorf("operation %q is not supported by the fake railway server", operation)
+}
+
+// rootField is the name of the first field selected by a query, e.g. project for
+// `query Project($id: String!) { project(id: $id) { ... } }`
+func rootField(query string) string {
+	start := strings.Index(query, "{")
+	if start == -1 {
+		return ""
+	}
+
+	field := strings.TrimLeft(query[start+1:], " \t\r\n")
+
+	end := strings.IndexFunc(field, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end == -1 {
+		return field
+	}
+
+	return field[:end]
+}
+
+func (s *Server) project(rawVariables json.RawMessage) (any, error) {
+	variables := struct {
+		Id string `json:"id"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	project, ok := s.projects[variables.Id]
+	if !ok {
+		return nil, fmt.Errorf("project %s not found", variables.Id)
+	}
+
+	environmentEdges := []any{}
+	for _, environment := range project.environments {
+		environmentEdges = append(environmentEdges, edge(map[string]any{
+			"id":   environment.id,
+			"name": environment.name,
+		}))
+	}
+
+	serviceEdges := []any{}
+	for _, serviceId := range project.serviceIds {
+		service := s.services[serviceId]
+
+		instanceEdges := []any{}
+		for _, environment := range project.environments {
+			if instance, ok := service.instances[environment.id]; ok {
+				instanceEdges = append(instanceEdges, edge(s.serviceInstanceNode(instance)))
+			}
+		}
+
+		serviceEdges = append(serviceEdges, edge(map[string]any{
+			"id":               service.id,
+			"name":             service.name,
+			"serviceInstances": map[string]any{"edges": instanceEdges},
+		}))
+	}
+
+	return map[string]any{
+		"id":           project.id,
+		"name":         project.name,
+		"environments": map[string]any{"edges": environmentEdges},
+		"services":     map[string]any{"edges": serviceEdges},
+	}, nil
+}
+
+func (s *Server) serviceInstanceQuery(rawVariables json.RawMessage) (any, error) {
+	variables := struct {
+		ServiceId     string `json:"serviceId"`
+		EnvironmentId string `json:"environmentId"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	instance, err := s.serviceInstance(variables.ServiceId, variables.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.serviceInstanceNode(instance), nil
+}
+
+func (s *Server) serviceInstanceNode(instance *serviceInstance) map[string]any {
+	s.settle(instance)
+
+	latest := instance.deployments[len(instance.deployments)-1]
+
+	multiRegionConfig := map[string]any{}
+	for region, replicas := range latest.multiRegionConfig {
+		multiRegionConfig[region] = map[string]any{"numReplicas": replicas}
+	}
+
+	return map[string]any{
+		"id":            instance.id,
+		"serviceId":     instance.serviceId,
+		"environmentId": instance.environmentId,
+		"latestDeployment": map[string]any{
+			"canRedeploy": true,
+			"id":          latest.id,
+			"status":      latest.status,
+			"createdAt":   latest.createdAt.Format(time.RFC3339),
+			"meta": map[string]any{
+				"serviceManifest": map[string]any{
+					"deploy": map[string]any{"multiRegionConfig": multiRegionConfig},
+				},
+			},
+		},
+	}
+}
+
+func (s *Server) metrics(rawVariables json.RawMessage, groupByRegion bool) (any, error) {
+	variables := struct {
+		ServiceId     string   `json:"serviceId"`
+		EnvironmentId string   `json:"environmentId"`
+		Measurements  []string `json:"measurements"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	instance, err := s.serviceInstance(variables.ServiceId, variables.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := []string{""}
+	if groupByRegion {
+		regions = s.metricRegions(instance)
+	}
+
+	ts := s.now().Unix()
+	metrics := []any{}
+
+	for _, region := range regions {
+		for _, measurement := range variables.Measurements {
+			values := []any{}
+
+			if value, ok := s.metricValue(instance, region, measurement); ok {
+				values = append(values, map[string]any{"ts": ts, "value": value})
+			}
+
+			metric := map[string]any{
+				"measurement": measurement,
+				"values":      values,
+			}
+
+			if groupByRegion {
+				metric["tags"] = map[string]any{"region": region}
+			}
+
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return metrics, nil
+}
+
+func (s *Server) serviceInstanceUpdate(rawVariables json.RawMessage) (any, error) {
+	variables := struct {
+		ServiceId         string                      `json:"serviceId"`
+		EnvironmentId     string                      `json:"environmentId"`
+		MultiRegionConfig map[string]*json.RawMessage `json:"multiRegionConfig"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	instance, err := s.serviceInstance(variables.ServiceId, variables.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	multiRegionConfig := map[string]int{}
+
+	for region, rawRegionConfig := range variables.MultiRegionConfig {
+		// like Railway, a null region config takes the service out of the region
+		if rawRegionConfig == nil {
+			continue
+		}
+
+		regionConfig := struct {
+			NumReplicas int `json:"numReplicas"`
+		}{}
+
+		if err := json.Unmarshal(*rawRegionConfig, &regionConfig); err != nil {
+			return nil, fmt.Errorf("error parsing multiRegionConfig for %s: %w", region, err)
+		}
+
+		multiRegionConfig[region] = regionConfig.NumReplicas
+	}
+
+	instance.multiRegionConfig = multiRegionConfig
+
+	return true, nil
+}
+
+func (s *Server) serviceInstanceDeploy(rawVariables json.RawMessage) (any, error) {
+	variables := struct {
+		ServiceId     string `json:"serviceId"`
+		EnvironmentId string `json:"environmentId"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	instance, err := s.serviceInstance(variables.ServiceId, variables.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	deployment := &deployment{
+		id:                s.nextId("deployment"),
+		status:            StatusDeploying,
+		multiRegionConfig: maps.Clone(instance.multiRegionConfig),
+		createdAt:         now,
+		updatedAt:         now,
+		readyAt:           now.Add(s.deployDuration),
+	}
+
+	instance.deployments = append(instance.deployments, deployment)
+	s.settle(instance)
+
+	return deployment.id, nil
+}
+
+// service lists the deployments of a service across its environments, newest first
+func (s *Server) service(rawVariables json.RawMessage) (any, error) {
+	// the incident service names the variable serviceId rather than id
+	variables := struct {
+		Id        string `json:"id"`
+		ServiceId string `json:"serviceId"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	variables.Id = cmp.Or(variables.Id, variables.ServiceId)
+
+	service, ok := s.services[variables.Id]
+	if !ok {
+		return nil, fmt.Errorf("service %s not found", variables.Id)
+	}
+
+	var deployments []*deployment
+	for _, instance := range service.instances {
+		s.settle(instance)
+		deployments = append(deployments, instance.deployments...)
+	}
+
+	deploymentEdges := []any{}
+	for i := len(deployments) - 1; i >= 0; i-- {
+		deployment := deployments[i]
+
+		deploymentEdges = append(deploymentEdges, edge(map[string]any{
+			"id":              deployment.id,
+			"status":          deployment.status,
+			"createdAt":       deployment.createdAt.Format(time.RFC3339),
+			"updatedAt":       deployment.updatedAt.Format(time.RFC3339),
+			"statusUpdatedAt": deployment.updatedAt.Format(time.RFC3339),
+		}))
+	}
+
+	return map[string]any{
+		"id":          service.id,
+		"name":        service.name,
+		"deployments": map[string]any{"edges": deploymentEdges},
+	}, nil
+}
+
+func (s *Server) variableCollectionUpsert(rawVariables json.RawMessage) (any, error) {
+	variables := struct {
+		ProjectId     string            `json:"projectId"`
+		EnvironmentId string            `json:"environmentId"`
+		ServiceId     string            `json:"serviceId"`
+		Variables     map[string]string `json:"variables"`
+	}{}
+
+	if err := json.Unmarshal(rawVariables, &variables); err != nil {
+		return nil, fmt.Errorf("error parsing variables: %w", err)
+	}
+
+	if _, ok := s.projects[variables.ProjectId]; !ok {
+		return nil, fmt.Errorf("project %s not found", variables.ProjectId)
+	}
+
+	if variables.ServiceId != "" {
+		if _, err := s.serviceInstance(variables.ServiceId, variables.EnvironmentId); err != nil {
+			return nil, err
+		}
+	}
+
+	key := variablesKey{serviceId: variables.ServiceId, environmentId: variables.EnvironmentId}
+
+	if s.variables[key] == nil {
+		s.variables[key] = map[string]string{}
+	}
+
+	maps.Copy(s.variables[key], variables.Variables)
+
+	return true, nil
+}
+
+func edge(node any) map[string]any {
+	return map[string]any{"node": node}
+}
@@ -0,0 +1,262 @@
+package railwayfake
+
+import (
+	"fmt"
+	"maps"
+	"time"
+)
+
+// the statuses Railway gives deployments, as far as the switchyard services care
+const (
+	StatusBuilding  = "BUILDING"
+	StatusDeploying = "DEPLOYING"
+	StatusSuccess   = "SUCCESS"
+	StatusFailed    = "FAILED"
+	StatusCrashed   = "CRASHED"
+	StatusRemoved   = "REMOVED"
+)
+
+type project struct {
+	id           string
+	name         string
+	environments []environment
+	serviceIds   []string
+}
+
+type environment struct {
+	id   string
+	name string
+}
+
+type service struct {
+	id        string
+	name      string
+	projectId string
+	instances map[string]*serviceInstance
+}
+
+type serviceInstance struct {
+	id            string
+	serviceId     string
+	environmentId string
+	// multiRegionConfig is what the next deployment will run, like Railway's staged changes
+	multiRegionConfig map[string]int
+	deployments       []*deployment
+	metrics           map[metricKey]MetricFunc
+}
+
+type metricKey struct {
+	region      string
+	measurement string
+}
+
+type deployment struct {
+	id                string
+	status            string
+	multiRegionConfig map[string]int
+	createdAt         time.Time
+	updatedAt         time.Time
+	// readyAt is when a deployment started by serviceInstanceDeployV2 goes live, zero once it has
+	readyAt time.Time
+}
+
+// AddProject creates an empty project
+func (s *Server) AddProject(projectId string, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.projects[projectId]; !ok {
+		s.projectIds = append(s.projectIds, projectId)
+	}
+
+	s.projects[projectId] = &project{id: projectId, name: name}
+}
+
+func (s *Server) AddEnvironment(projectId string, environmentId string, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	project, ok := s.projects[projectId]
+	if !ok {
+		return fmt.Errorf("project %s does not exist", projectId)
+	}
+
+	project.environments = append(project.environments, environment{id: environmentId, name: name})
+
+	return nil
+}
+
+func (s *Server) AddService(projectId string, serviceId string, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	project, ok := s.projects[projectId]
+	if !ok {
+		return fmt.Errorf("project %s does not exist", projectId)
+	}
+
+	project.serviceIds = append(project.serviceIds, serviceId)
+	s.services[serviceId] = &service{
+		id:        serviceId,
+		name:      name,
+		projectId: projectId,
+		instances: map[string]*serviceInstance{},
+	}
+
+	return nil
+}
+
+// AddServiceInstance deploys the service to an environment, with replicas being the
+// number of replicas in each region. The first deployment is live straight away
+func (s *Server) AddServiceInstance(serviceId string, environmentId string, replicas map[string]int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	service, ok := s.services[serviceId]
+	if !ok {
+		return fmt.Errorf("service %s does not exist", serviceId)
+	}
+
+	now := s.now()
+
+	instance := &serviceInstance{
+		id:                s.nextId("service-instance"),
+		serviceId:         serviceId,
+		environmentId:     environmentId,
+		multiRegionConfig: maps.Clone(replicas),
+		metrics:           map[metricKey]MetricFunc{},
+	}
+
+	instance.deployments = append(instance.deployments, &deployment{
+		id:                s.nextId("deployment"),
+		status:            StatusSuccess,
+		multiRegionConfig: maps.Clone(replicas),
+		createdAt:         now,
+		updatedAt:         now,
+	})
+
+	service.instances[environmentId] = instance
+
+	return nil
+}
+
+// SetDeployDuration is how long deployments started through serviceInstanceDeployV2
+// stay DEPLOYING before they go live. They go live immediately by default
+func (s *Server) SetDeployDuration(duration time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.deployDuration = duration
+}
+
+// SetDeploymentStatus changes the status of the service instance's latest deployment,
+// e.g. to script a crash or a deployment that never finishes
+func (s *Server) SetDeploymentStatus(serviceId string, environmentId string, status string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instance, err := s.serviceInstance(serviceId, environmentId)
+	if err != nil {
+		return err
+	}
+
+	latest := instance.deployments[len(instance.deployments)-1]
+	latest.status = status
+	latest.updatedAt = s.now()
+	latest.readyAt = time.Time{}
+
+	return nil
+}
+
+// Replicas returns the replicas running in a region, i.e. those of the service
+// instance's live deployment rather than any staged changes
+func (s *Server) Replicas(serviceId string, environmentId string, region string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	instance, err := s.serviceInstance(serviceId, environmentId)
+	if err != nil {
+		return 0
+	}
+
+	return s.liveReplicas(instance)[region]
+}
+
+// Variables returns the variables upserted for a service in an environment. An empty
+// serviceId returns the environment's shared variables
+func (s *Server) Variables(serviceId string, environmentId string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return maps.Clone(s.variables[variablesKey{serviceId: serviceId, environmentId: environmentId}])
+}
+
+// Calls returns how many requests were made for an operation, e.g. OperationDeploy
+func (s *Server) Calls(operation string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.calls[operation]
+}
+
+// FailNext makes the next request for an operation return a GraphQL error with message
+func (s *Server) FailNext(operation string, message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures[operation] = append(s.failures[operation], message)
+}
+
+func (s *Server) serviceInstance(serviceId string, environmentId string) (*serviceInstance, error) {
+	service, ok := s.services[serviceId]
+	if !ok {
+		return nil, fmt.Errorf("service %s does not exist", serviceId)
+	}
+
+	instance, ok := service.instances[environmentId]
+	if !ok {
+		return nil, fmt.Errorf("service %s has no instance in environment %s", serviceId, environmentId)
+	}
+
+	return instance, nil
+}
+
+// settle moves deployments that are due to go live to SUCCESS, removing the deployment
+// they replace the way Railway does
+func (s *Server) settle(instance *serviceInstance) {
+	now := s.now()
+
+	for i, deployment := range instance.deployments {
+		if deployment.readyAt.IsZero() || now.Before(deployment.readyAt) {
+			continue
+		}
+
+		deployment.status = StatusSuccess
+		deployment.updatedAt = deployment.readyAt
+		deployment.readyAt = time.Time{}
+
+		for _, previous := range instance.deployments[:i] {
+			if previous.status == StatusSuccess {
+				previous.status = StatusRemoved
+				previous.updatedAt = deployment.updatedAt
+			}
+		}
+	}
+}
+
+func (s *Server) liveReplicas(instance *serviceInstance) map[string]int {
+	s.settle(instance)
+
+	for i := len(instance.deployments) - 1; i >= 0; i-- {
+		if instance.deployments[i].status == StatusSuccess {
+			return instance.deployments[i].multiRegionConfig
+		}
+	}
+
+	return map[string]int{}
+}
+
+func (s *Server) nextId(prefix string) string {
+	s.sequence++
+	return fmt.Sprintf("%s-%d", prefix, s.sequence)
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/caarlos0/env/v10"
 	messagebus "github.com/ferretcode/switchyard/scheduler/internal/message_bus"
+	postgresqueue "github.com/ferretcode/switchyard/scheduler/internal/postgres_queue"
+	pushdelivery "github.com/ferretcode/switchyard/scheduler/internal/push_delivery"
 	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
 	"github.com/ferretcode/switchyard/scheduler/internal/scheduler"
 	"github.com/ferretcode/switchyard/scheduler/internal/watchdog"
@@ -49,25 +51,44 @@ func main() {
 	}
 	defer conn.Close()
 
-	options, err := redis.ParseURL(config.CacheUrl)
-	if err != nil {
-		logger.Error("error parsing redis url", "err", err)
-		return
-	}
+	queries := repositories.New(conn)
 
-	redisConn := redis.NewClient(options)
+	var schedulerService scheduler.SchedulerService
 
-	messageBusConn, err := amqp.Dial(config.MessageBusUrl)
-	if err != nil {
-		logger.Error("error connecting to the message bus", "err", err)
-	}
-	defer messageBusConn.Close()
+	switch config.SchedulerMode {
+	case types.SchedulerModePostgres:
+		// job_receipts doubles as the queue, so neither RabbitMQ nor Redis is needed
+		postgresQueueService := postgresqueue.NewPostgresQueueService(logger, &config, ctx, queries)
+		watchdogService := watchdog.NewWatchdogService(logger, &config, nil, nil, ctx, queries)
+		schedulerService = scheduler.NewSchedulerService(logger, &config, queries, conn, ctx, &postgresQueueService, &postgresQueueService)
 
-	queries := repositories.New(conn)
+		go watchdogService.WatchExpiredLeases()
+	case types.SchedulerModeRabbitMQ, "":
+		options, err := redis.ParseURL(config.CacheUrl)
+		if err != nil {
+			logger.Error("error parsing redis url", "err", err)
+			return
+		}
 
-	messageBusService := messagebus.NewMessageBusService(logger, messageBusConn, &config, redisConn, ctx, queries)
-	watchdogService := watchdog.NewWatchdogService(logger, &config, redisConn, &messageBusService, ctx, queries)
-	schedulerService := scheduler.NewSchedulerService(logger, queries, ctx, &messageBusService)
+		redisConn := redis.NewClient(options)
+
+		messageBusConn, err := amqp.Dial(config.MessageBusUrl)
+		if err != nil {
+			logger.Error("error connecting to the message bus", "err", err)
+		}
+		defer messageBusConn.Close()
+
+		messageBusService := messagebus.NewMessageBusService(logger, messageBusConn, &config, redisConn, ctx, queries)
+		watchdogService := watchdog.NewWatchdogService(logger, &config, redisConn, &messageBusService, ctx, queries)
+		schedulerService = scheduler.NewSchedulerService(logger, &config, queries, conn, ctx, &messageBusService, &messageBusService)
+
+		go messageBusService.SubscribeToJobFinishedMessages()
+		go messageBusService.WatchExpiredLeases()
+		go watchdogService.WatchStuckJobs()
+	default:
+		logger.Error("unknown scheduler mode", "mode", config.SchedulerMode)
+		return
+	}
 
 	r := chi.NewRouter()
 
@@ -90,10 +111,36 @@ func main() {
 		r.Get("/get-job-statistics/{name}", func(w http.ResponseWriter, r *http.Request) {
 			handleError(schedulerService.GetJobStatistics(w, r), w, "scheduler/get-job-statistics")
 		})
+
+		r.Put("/register-job-schema/{name}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.RegisterJobSchema(w, r), w, "scheduler/register-job-schema")
+		})
+
+		r.Get("/get-job-schema/{name}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.GetJobSchema(w, r), w, "scheduler/get-job-schema")
+		})
+
+		r.Delete("/unregister-job-schema/{name}", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.UnregisterJobSchema(w, r), w, "scheduler/unregister-job-schema")
+		})
+
+		r.Post("/lease-job", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.LeaseJob(w, r), w, "scheduler/lease-job")
+		})
+
+		r.Post("/heartbeat-job", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.HeartbeatJob(w, r), w, "scheduler/heartbeat-job")
+		})
+
+		r.Post("/finish-job", func(w http.ResponseWriter, r *http.Request) {
+			handleError(schedulerService.FinishJob(w, r), w, "scheduler/finish-job")
+		})
 	})
 
-	go messageBusService.SubscribeToJobFinishedMessages()
-	go watchdogService.WatchStuckJobs()
+	pushDeliveryService := pushdelivery.NewPushDeliveryService(logger, &config, ctx, queries, schedulerService.Leaser)
+
+	go schedulerService.DispatchScheduledJobs()
+	go pushDeliveryService.DeliverPushJobs()
 
 	http.ListenAndServe(":"+config.Port, r)
 }
@@ -0,0 +1,335 @@
+package messagebus
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+type jobLease struct {
+	delivery  amqp.Delivery
+	jobId     string
+	expiresAt time.Time
+}
+
+func leasedJobQueueName(jobName string) string {
+	return "jobs." + jobName
+}
+
+// jobQueue picks the queue a job is published to. A job name registered for leasing
+// or push delivery gets its own, so a lease never has to look past other jobs' messages
+// and the AMQP workers on the shared jobs queue never see it
+func (m *MessageBusService) jobQueue(channel *amqp.Channel, queue amqp.Queue, jobName string) (amqp.Queue, error) {
+	leased, err := m.Queries.IsJobNameLeased(m.Context, jobName)
+	if err != nil {
+		return amqp.Queue{}, fmt.Errorf("error checking leased job names: %w", err)
+	}
+
+	if !leased {
+		return queue, nil
+	}
+
+	return declareJobQueue(channel, leasedJobQueueName(jobName))
+}
+
+// RouteJobName moves the jobs already queued for the job name over to the queue it's
+// published to now. Only the messages on the queue when it starts are looked at, anything
+// published afterwards is already routed by jobQueue
+func (m *MessageBusService) RouteJobName(jobName string) error {
+	leased, err := m.Queries.IsJobNameLeased(m.Context, jobName)
+	if err != nil {
+		return fmt.Errorf("error checking leased job names: %w", err)
+	}
+
+	from, to := "jobs", leasedJobQueueName(jobName)
+	if !leased {
+		from, to = to, from
+	}
+
+	// messages for other job names are left unacked, so closing the channel puts them back in order
+	channel, err := m.Conn.Channel()
+	if err != nil {
+		return fmt.Errorf("error opening channel: %w", err)
+	}
+	defer channel.Close()
+
+	queue, err := declareJobQueue(channel, from)
+	if err != nil {
+		return fmt.Errorf("error declaring queue %s: %w", from, err)
+	}
+
+	if _, err := declareJobQueue(channel, to); err != nil {
+		return fmt.Errorf("error declaring queue %s: %w", to, err)
+	}
+
+	moved := 0
+
+	for range queue.Messages {
+		delivery, ok, err := channel.Get(from, false)
+		if err != nil {
+			return fmt.Errorf("error fetching job from message queue: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		jobMessage := JobMessage{}
+
+		if err := json.Unmarshal(delivery.Body, &jobMessage); err != nil || jobMessage.JobName != jobName {
+			continue
+		}
+
+		err = channel.PublishWithContext(m.Context,
+			"",
+			to,
+			false,
+			false,
+			amqp.Publishing{
+				ContentType:  delivery.ContentType,
+				Body:         delivery.Body,
+				DeliveryMode: amqp.Persistent,
+			})
+		if err != nil {
+			return fmt.Errorf("error publishing job to %s: %w", to, err)
+		}
+
+		if err := delivery.Ack(false); err != nil {
+			return fmt.Errorf("error acknowledging job: %w", err)
+		}
+
+		moved++
+	}
+
+	if moved > 0 {
+		m.Logger.Info("moved queued jobs to the job name's new queue", "job-name", jobName, "from", from, "to", to, "count", moved)
+	}
+
+	return nil
+}
+
+func (m *MessageBusService) LeaseJob(jobNames []string) (repositories.JobReceipt, error) {
+	m.leaseMutex.Lock()
+	defer m.leaseMutex.Unlock()
+
+	channel, err := m.getLeaseChannel()
+	if err != nil {
+		return repositories.JobReceipt{}, err
+	}
+
+	// start from a different job name each time so one busy job can't starve the rest
+	m.leaseCursor++
+
+	for i := range jobNames {
+		jobName := jobNames[(m.leaseCursor+i)%len(jobNames)]
+
+		queueName, err := m.leaseQueue(channel, jobName)
+		if err != nil {
+			return repositories.JobReceipt{}, err
+		}
+
+		delivery, ok, err := channel.Get(queueName, false)
+		if err != nil {
+			return repositories.JobReceipt{}, fmt.Errorf("error fetching job from message queue: %w", err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		jobMessage := JobMessage{}
+
+		if err := json.Unmarshal(delivery.Body, &jobMessage); err != nil {
+			m.Logger.Error("error decoding job message, discarding it", "err", err)
+			delivery.Nack(false, false)
+			continue
+		}
+
+		leaseId := uuid.NewString()
+		expiresAt := time.Now().Add(m.leaseDuration())
+
+		m.leases[leaseId] = &jobLease{
+			delivery:  delivery,
+			jobId:     jobMessage.JobId,
+			expiresAt: expiresAt,
+		}
+
+		jobKey := "jobs:" + jobMessage.JobId
+
+		// kept around so a repeated finish for the same lease can be recognised
+		if err := m.RedisConn.HSet(m.Context, jobKey, "lease_id", leaseId).Err(); err != nil {
+			m.Logger.Error("error storing lease id", "err", err, "job-id", jobMessage.JobId)
+		}
+
+		m.setPendingSince(jobMessage.JobId, expiresAt)
+
+		retryCount, _ := m.RedisConn.HGet(m.Context, jobKey, "retry_count").Int()
+
+		m.Logger.Info("job leased over http", "job-id", jobMessage.JobId)
+
+		return repositories.JobReceipt{
+			JobID:          jobMessage.JobId,
+			JobName:        jobMessage.JobName,
+			JobContext:     jobMessage.JobContext,
+			Status:         "running",
+			RetryCount:     int32(retryCount),
+			LeaseID:        sql.NullString{String: leaseId, Valid: true},
+			LeaseExpiresAt: expiresAt.Unix(),
+		}, nil
+	}
+
+	return repositories.JobReceipt{}, sql.ErrNoRows
+}
+
+// leaseQueue declares the job name's own queue on the lease channel
+func (m *MessageBusService) leaseQueue(channel *amqp.Channel, jobName string) (string, error) {
+	queueName := leasedJobQueueName(jobName)
+
+	if m.leaseQueues[jobName] {
+		return queueName, nil
+	}
+
+	if _, err := declareJobQueue(channel, queueName); err != nil {
+		return "", fmt.Errorf("error declaring queue for %s: %w", jobName, err)
+	}
+
+	m.leaseQueues[jobName] = true
+
+	return queueName, nil
+}
+
+// setPendingSince moves a job's jobs:pending score, which the watchdog retries it from.
+// A leased job sits at its lease expiry, so it's only retried once the worker stops heartbeating
+func (m *MessageBusService) setPendingSince(jobId string, since time.Time) {
+	err := m.RedisConn.ZAddXX(m.Context, "jobs:pending", redis.Z{
+		Score:  float64(since.Unix()),
+		Member: jobId,
+	}).Err()
+	if err != nil {
+		m.Logger.Error("error updating pending job score", "err", err, "job-id", jobId)
+	}
+}
+
+func (m *MessageBusService) HeartbeatJob(jobId string, leaseId string) (repositories.JobReceipt, error) {
+	m.leaseMutex.Lock()
+	defer m.leaseMutex.Unlock()
+
+	lease, ok := m.leases[leaseId]
+	if !ok || lease.jobId != jobId || time.Now().After(lease.expiresAt) {
+		return repositories.JobReceipt{}, sql.ErrNoRows
+	}
+
+	lease.expiresAt = time.Now().Add(m.leaseDuration())
+	m.setPendingSince(jobId, lease.expiresAt)
+
+	return repositories.JobReceipt{
+		JobID:          jobId,
+		Status:         "running",
+		LeaseID:        sql.NullString{String: leaseId, Valid: true},
+		LeaseExpiresAt: lease.expiresAt.Unix(),
+	}, nil
+}
+
+func (m *MessageBusService) FinishJob(jobId string, leaseId string, status string, message string) error {
+	m.leaseMutex.Lock()
+	defer m.leaseMutex.Unlock()
+
+	lease, ok := m.leases[leaseId]
+	if !ok || lease.jobId != jobId {
+		return m.checkAlreadyFinished(jobId, leaseId, status)
+	}
+
+	if err := m.finishJob(jobId, status, message); err != nil {
+		return err
+	}
+
+	delivery := lease.delivery
+	delete(m.leases, leaseId)
+
+	if err := delivery.Ack(false); err != nil {
+		return fmt.Errorf("error acknowledging job: %w", err)
+	}
+
+	return nil
+}
+
+// WatchExpiredLeases puts deliveries whose worker stopped heartbeating back on the queue
+func (m *MessageBusService) WatchExpiredLeases() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.releaseExpiredLeases()
+	}
+}
+
+func (m *MessageBusService) releaseExpiredLeases() {
+	m.leaseMutex.Lock()
+	defer m.leaseMutex.Unlock()
+
+	now := time.Now()
+
+	for leaseId, lease := range m.leases {
+		if now.Before(lease.expiresAt) {
+			continue
+		}
+
+		m.Logger.Info("job lease expired, requeueing", "job-id", lease.jobId)
+
+		if err := lease.delivery.Nack(false, true); err != nil {
+			m.Logger.Error("error requeueing job", "err", err, "job-id", lease.jobId)
+		}
+
+		m.setPendingSince(lease.jobId, now)
+
+		delete(m.leases, leaseId)
+	}
+}
+
+// checkAlreadyFinished lets a worker safely repeat a finish call that
+// succeeded, e.g. when the first response was lost
+func (m *MessageBusService) checkAlreadyFinished(jobId string, leaseId string, status string) error {
+	values, err := m.RedisConn.HMGet(m.Context, "jobs:"+jobId, "lease_id", "status").Result()
+	if err != nil {
+		return fmt.Errorf("error fetching job from Redis: %w", err)
+	}
+
+	if values[0] == leaseId && values[1] == status {
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
+func (m *MessageBusService) getLeaseChannel() (*amqp.Channel, error) {
+	if m.leaseChannel != nil && !m.leaseChannel.IsClosed() {
+		return m.leaseChannel, nil
+	}
+
+	// the broker requeues everything that was unacked on a closed channel
+	clear(m.leases)
+	clear(m.leaseQueues)
+
+	channel, _, err := m.declareQueueAndChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	m.leaseChannel = channel
+
+	return channel, nil
+}
+
+func (m *MessageBusService) leaseDuration() time.Duration {
+	if m.Config.WorkerLeaseDuration <= 0 {
+		return 30 * time.Second
+	}
+
+	return m.Config.WorkerLeaseDuration
+}
@@ -0,0 +1,65 @@
+package messagebus
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ferretcode/switchyard/scheduler/pkg/types"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestUnknownLease(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+
+	// job-2 was finished under lease-2, which is gone from the lease table since
+	redisServer.HSet("jobs:job-2", "lease_id", "lease-2", "status", "ok")
+
+	redisConn := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	m := NewMessageBusService(slog.New(slog.DiscardHandler), nil, &types.Config{}, redisConn, context.Background(), nil)
+
+	m.leases["lease-1"] = &jobLease{jobId: "job-1", expiresAt: time.Now().Add(time.Minute)}
+	m.leases["lease-3"] = &jobLease{jobId: "job-3", expiresAt: time.Now().Add(-time.Second)}
+
+	heartbeat := func(jobId string, leaseId string) func() error {
+		return func() error {
+			_, err := m.HeartbeatJob(jobId, leaseId)
+			return err
+		}
+	}
+
+	finish := func(jobId string, leaseId string, status string) func() error {
+		return func() error {
+			return m.FinishJob(jobId, leaseId, status, "")
+		}
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		err  error
+	}{
+		{name: "heartbeat for an unknown lease", call: heartbeat("job-1", "lease-other"), err: sql.ErrNoRows},
+		{name: "heartbeat for another job's lease", call: heartbeat("job-2", "lease-1"), err: sql.ErrNoRows},
+		{name: "heartbeat for an expired lease", call: heartbeat("job-3", "lease-3"), err: sql.ErrNoRows},
+		{name: "finish for an unknown lease", call: finish("job-1", "lease-other", "ok"), err: sql.ErrNoRows},
+		{name: "finish for a job that was never leased", call: finish("job-4", "lease-4", "ok"), err: sql.ErrNoRows},
+		{name: "finish repeated", call: finish("job-2", "lease-2", "ok")},
+		{name: "finish repeated with another status", call: finish("job-2", "lease-2", "error"), err: sql.ErrNoRows},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.call(); err != test.err {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	if _, ok := m.leases["lease-1"]; !ok {
+		t.Error("lease-1 was dropped by calls for other leases")
+	}
+}
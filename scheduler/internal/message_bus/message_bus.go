@@ -3,12 +3,13 @@ package messagebus
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
 	"github.com/ferretcode/switchyard/scheduler/pkg/types"
-	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
 )
@@ -20,16 +21,25 @@ type MessageBusService struct {
 	RedisConn *redis.Client
 	Queries   *repositories.Queries
 	Context   context.Context
+
+	// deliveries leased to HTTP workers stay unacked on leaseChannel until they finish
+	leaseChannel *amqp.Channel
+	leases       map[string]*jobLease
+	leaseQueues  map[string]bool
+	leaseCursor  int
+	leaseMutex   sync.Mutex
 }
 
 func NewMessageBusService(logger *slog.Logger, conn *amqp.Connection, config *types.Config, redisConn *redis.Client, context context.Context, queries *repositories.Queries) MessageBusService {
 	return MessageBusService{
-		Logger:    logger,
-		Conn:      conn,
-		RedisConn: redisConn,
-		Config:    config,
-		Context:   context,
-		Queries:   queries,
+		Logger:      logger,
+		Conn:        conn,
+		RedisConn:   redisConn,
+		Config:      config,
+		Context:     context,
+		Queries:     queries,
+		leases:      make(map[string]*jobLease),
+		leaseQueues: make(map[string]bool),
 	}
 }
 
@@ -40,6 +50,11 @@ func (m *MessageBusService) SendRetryJobMessage(jobName string, jobContext map[s
 	}
 	defer channel.Close()
 
+	queue, err = m.jobQueue(channel, queue, jobName)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -73,28 +88,26 @@ func (m *MessageBusService) SendRetryJobMessage(jobName string, jobContext map[s
 	return nil
 }
 
-func (m *MessageBusService) SendScheduleJobMessage(jobName string, jobContext map[string]any) error {
+func (m *MessageBusService) PublishJob(jobReceipt repositories.JobReceipt) error {
 	channel, queue, err := m.declareQueueAndChannel()
 	if err != nil {
 		return err
 	}
 	defer channel.Close()
 
-	contextBytes, err := json.Marshal(jobContext)
+	queue, err = m.jobQueue(channel, queue, jobReceipt.JobName)
 	if err != nil {
 		return err
 	}
 
-	jobId := uuid.NewString()
-
-	err = m.RedisConn.HSet(m.Context, "jobs:"+jobId, map[string]interface{}{
+	err = m.RedisConn.HSet(m.Context, "jobs:"+jobReceipt.JobID, map[string]interface{}{
 		"status":      "pending",
-		"created_at":  time.Now().Unix(),
+		"created_at":  jobReceipt.CreatedAt,
 		"updated_at":  time.Now().Unix(),
-		"retry_count": 0,
+		"retry_count": jobReceipt.RetryCount,
 		"message":     "",
-		"job_name":    jobName,
-		"job_context": string(contextBytes),
+		"job_name":    jobReceipt.JobName,
+		"job_context": string(jobReceipt.JobContext),
 	}).Err()
 	if err != nil {
 		return err
@@ -102,33 +115,19 @@ func (m *MessageBusService) SendScheduleJobMessage(jobName string, jobContext ma
 
 	err = m.RedisConn.ZAdd(m.Context, "jobs:pending", redis.Z{
 		Score:  float64(time.Now().Unix()),
-		Member: jobId,
+		Member: jobReceipt.JobID,
 	}).Err()
 	if err != nil {
 		return err
 	}
 
-	_, err = m.Queries.CreateJobReceipt(m.Context, repositories.CreateJobReceiptParams{
-		JobID:      jobId,
-		JobName:    jobName,
-		JobContext: json.RawMessage(contextBytes),
-		Status:     "pending",
-		RetryCount: 0,
-		Message:    "",
-		CreatedAt:  time.Now().Unix(),
-		UpdatedAt:  time.Now().Unix(),
-	})
-	if err != nil {
-		return err
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	scheduleJobBody := map[string]any{
-		"job_name":    jobName,
-		"job_context": jobContext,
-		"job_id":      jobId,
+		"job_name":    jobReceipt.JobName,
+		"job_context": jobReceipt.JobContext,
+		"job_id":      jobReceipt.JobID,
 	}
 
 	bodyBytes, err := json.Marshal(scheduleJobBody)
@@ -136,7 +135,7 @@ func (m *MessageBusService) SendScheduleJobMessage(jobName string, jobContext ma
 		return err
 	}
 
-	m.Logger.Info("publishing job receipt to message queue", "job-id", jobId)
+	m.Logger.Info("publishing job receipt to message queue", "job-id", jobReceipt.JobID)
 
 	err = channel.PublishWithContext(ctx,
 		"",
@@ -207,8 +206,6 @@ func (m *MessageBusService) handleMessageFinishedDelivery(delivery amqp.Delivery
 		return
 	}
 
-	jobKey := "jobs:" + finishJobMessage.JobId
-
 	updatedStatus := ""
 
 	switch finishJobMessage.Status {
@@ -218,53 +215,60 @@ func (m *MessageBusService) handleMessageFinishedDelivery(delivery amqp.Delivery
 		updatedStatus = "error"
 	}
 
+	if err := m.finishJob(finishJobMessage.JobId, updatedStatus, finishJobMessage.Message); err != nil {
+		m.Logger.Error("error finishing job", "err", err, "job-id", finishJobMessage.JobId)
+		return
+	}
+
+	delivery.Ack(false)
+}
+
+// finishJob records the outcome of a job in the receipt and in Redis, which is
+// what stops the watchdog from retrying it
+func (m *MessageBusService) finishJob(jobId string, status string, message string) error {
+	jobKey := "jobs:" + jobId
+
 	retryCount, err := m.RedisConn.HGet(m.Context, jobKey, "retry_count").Int()
 	if err != nil {
-		m.Logger.Error("error fetching retry count from Redis", "err", err)
-		return
+		return fmt.Errorf("error fetching retry count from Redis: %w", err)
 	}
 
 	jobName, err := m.RedisConn.HGet(m.Context, jobKey, "job_name").Result()
 	if err != nil {
-		m.Logger.Error("error fetching job name from Redis", "err", err)
-		return
+		return fmt.Errorf("error fetching job name from Redis: %w", err)
 	}
 
 	jobContext, err := m.RedisConn.HGet(m.Context, jobKey, "job_context").Result()
 	if err != nil {
-		m.Logger.Error("error fetching job context from Redis", "err", err)
-		return
+		return fmt.Errorf("error fetching job context from Redis: %w", err)
 	}
 
 	_, err = m.Queries.UpdateJobReceiptByJobID(m.Context, repositories.UpdateJobReceiptByJobIDParams{
-		JobID:      finishJobMessage.JobId,
+		JobID:      jobId,
 		JobName:    jobName,
 		JobContext: json.RawMessage(jobContext),
-		Status:     updatedStatus,
-		Message:    finishJobMessage.Message,
+		Status:     status,
+		Message:    message,
 		UpdatedAt:  time.Now().Unix(),
 		RetryCount: int32(retryCount),
 	})
 	if err != nil {
-		m.Logger.Error("error updating job receipt in database", "err", err)
-		return
+		return fmt.Errorf("error updating job receipt in database: %w", err)
 	}
 
-	err = m.RedisConn.HSet(m.Context, jobKey, "status", updatedStatus).Err()
+	err = m.RedisConn.HSet(m.Context, jobKey, "status", status).Err()
 	if err != nil {
-		m.Logger.Error("error updating job status", "err", err)
-		return
+		return fmt.Errorf("error updating job status: %w", err)
 	}
 
-	err = m.RedisConn.HSet(m.Context, jobKey, "message", finishJobMessage.Message).Err()
+	err = m.RedisConn.HSet(m.Context, jobKey, "message", message).Err()
 	if err != nil {
-		m.Logger.Error("error updating job message", "err", err)
-		return
+		return fmt.Errorf("error updating job message: %w", err)
 	}
 
-	m.Logger.Info("job has been processed successfully", "job-id", finishJobMessage.JobId)
+	m.Logger.Info("job has been processed successfully", "job-id", jobId)
 
-	delivery.Ack(false)
+	return nil
 }
 
 func (m *MessageBusService) declareQueueAndChannel() (*amqp.Channel, amqp.Queue, error) {
@@ -279,17 +283,21 @@ func (m *MessageBusService) declareQueueAndChannel() (*amqp.Channel, amqp.Queue,
 		false,
 	)
 
-	queue, err := channel.QueueDeclare(
-		"jobs",
+	queue, err := declareJobQueue(channel, "jobs")
+	if err != nil {
+		return &amqp.Channel{}, amqp.Queue{}, err
+	}
+
+	return channel, queue, nil
+}
+
+func declareJobQueue(channel *amqp.Channel, name string) (amqp.Queue, error) {
+	return channel.QueueDeclare(
+		name,
 		true,
 		false,
 		false,
 		false,
 		nil,
 	)
-	if err != nil {
-		return &amqp.Channel{}, amqp.Queue{}, err
-	}
-
-	return channel, queue, nil
 }
@@ -1,5 +1,7 @@
 package messagebus
 
+import "encoding/json"
+
 const (
 	OK = iota
 	ERROR
@@ -10,3 +12,9 @@ type FinishJobMessage struct {
 	Message string `json:"message"`
 	Status  int    `json:"status"`
 }
+
+type JobMessage struct {
+	JobName    string          `json:"job_name"`
+	JobContext json.RawMessage `json:"job_context"`
+	JobId      string          `json:"job_id"`
+}
@@ -0,0 +1,104 @@
+package postgresqueue
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+	"github.com/ferretcode/switchyard/scheduler/pkg/types"
+	"github.com/google/uuid"
+)
+
+type PostgresQueueService struct {
+	Logger  *slog.Logger
+	Config  *types.Config
+	Queries *repositories.Queries
+	Context context.Context
+}
+
+func NewPostgresQueueService(logger *slog.Logger, config *types.Config, context context.Context, queries *repositories.Queries) PostgresQueueService {
+	return PostgresQueueService{
+		Logger:  logger,
+		Config:  config,
+		Context: context,
+		Queries: queries,
+	}
+}
+
+// PublishJob has nothing to do here, a pending job receipt is already
+// visible to workers leasing from the table
+func (p *PostgresQueueService) PublishJob(jobReceipt repositories.JobReceipt) error {
+	p.Logger.Info("job receipt is ready to be leased", "job-id", jobReceipt.JobID)
+	return nil
+}
+
+// LeaseJob hands the oldest pending job for one of the given job names to the caller.
+// SKIP LOCKED lets concurrent workers lease different rows without waiting on each other.
+// sql.ErrNoRows is returned when there is nothing to lease
+func (p *PostgresQueueService) LeaseJob(jobNames []string) (repositories.JobReceipt, error) {
+	now := time.Now()
+
+	return p.Queries.LeaseJobReceipt(p.Context, repositories.LeaseJobReceiptParams{
+		LeaseID:        sql.NullString{String: uuid.NewString(), Valid: true},
+		LeaseExpiresAt: now.Add(p.leaseDuration()).Unix(),
+		UpdatedAt:      now.Unix(),
+		JobNames:       jobNames,
+	})
+}
+
+// RouteJobName has nothing to move, every job name is leased from the same table
+func (p *PostgresQueueService) RouteJobName(jobName string) error {
+	return nil
+}
+
+func (p *PostgresQueueService) HeartbeatJob(jobId string, leaseId string) (repositories.JobReceipt, error) {
+	now := time.Now()
+
+	return p.Queries.ExtendJobReceiptLease(p.Context, repositories.ExtendJobReceiptLeaseParams{
+		LeaseExpiresAt: now.Add(p.leaseDuration()).Unix(),
+		UpdatedAt:      now.Unix(),
+		JobID:          jobId,
+		LeaseID:        sql.NullString{String: leaseId, Valid: true},
+	})
+}
+
+// FinishJob returns sql.ErrNoRows when the lease has expired or belongs to someone else
+func (p *PostgresQueueService) FinishJob(jobId string, leaseId string, status string, message string) error {
+	_, err := p.Queries.FinishJobReceiptLease(p.Context, repositories.FinishJobReceiptLeaseParams{
+		Status:    status,
+		Message:   message,
+		UpdatedAt: time.Now().Unix(),
+		JobID:     jobId,
+		LeaseID:   sql.NullString{String: leaseId, Valid: true},
+	})
+	if err == sql.ErrNoRows {
+		// the lease id is kept on finished receipts, so a repeated finish call can be recognised
+		jobReceipt, err := p.Queries.GetJobReceiptByJobID(p.Context, jobId)
+		if err != nil {
+			return err
+		}
+
+		if jobReceipt.LeaseID.String == leaseId && jobReceipt.Status == status {
+			return nil
+		}
+
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return err
+	}
+
+	p.Logger.Info("job has been processed successfully", "job-id", jobId)
+
+	return nil
+}
+
+func (p *PostgresQueueService) leaseDuration() time.Duration {
+	if p.Config.WorkerLeaseDuration <= 0 {
+		return 30 * time.Second
+	}
+
+	return p.Config.WorkerLeaseDuration
+}
@@ -0,0 +1,260 @@
+package pushdelivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+	"github.com/ferretcode/switchyard/scheduler/internal/scheduler"
+	"github.com/ferretcode/switchyard/scheduler/pkg/types"
+)
+
+const (
+	idleInterval      = 1 * time.Second
+	heartbeatInterval = 10 * time.Second
+	baseRetryBackoff  = 5 * time.Second
+	maxRetryBackoff   = 5 * time.Minute
+	maxResponseBody   = 4096
+	maxMessageLength  = 255
+)
+
+type PushDeliveryService struct {
+	Logger  *slog.Logger
+	Config  *types.Config
+	Queries *repositories.Queries
+	Context context.Context
+	Leaser  scheduler.JobLeaser
+	Client  *http.Client
+}
+
+func NewPushDeliveryService(logger *slog.Logger, config *types.Config, context context.Context, queries *repositories.Queries, leaser scheduler.JobLeaser) PushDeliveryService {
+	return PushDeliveryService{
+		Logger:  logger,
+		Config:  config,
+		Context: context,
+		Queries: queries,
+		Leaser:  leaser,
+		Client:  &http.Client{},
+	}
+}
+
+// DeliverPushJobs leases jobs for every job name with a push endpoint and POSTs
+// them to the worker, so those workers never have to talk to the queue themselves
+func (p *PushDeliveryService) DeliverPushJobs() {
+	// workers can't tell our requests apart from anyone else's without a secret to check them against
+	if p.Config.PushSigningSecret == "" {
+		p.Logger.Error("PUSH_SIGNING_SECRET is not set, jobs won't be pushed to workers")
+		return
+	}
+
+	inFlight := make(chan struct{}, max(p.Config.WorkerUnackedMessageCount, 1))
+
+	for {
+		pushEndpoints, err := p.Queries.ListPushEndpoints(p.Context)
+		if err != nil {
+			p.Logger.Error("error fetching push endpoints", "err", err)
+			time.Sleep(idleInterval)
+			continue
+		}
+
+		if len(pushEndpoints) == 0 {
+			time.Sleep(idleInterval)
+			continue
+		}
+
+		endpointsByJobName := make(map[string]repositories.PushEndpoint, len(pushEndpoints))
+		jobNames := make([]string, 0, len(pushEndpoints))
+
+		for _, pushEndpoint := range pushEndpoints {
+			endpointsByJobName[pushEndpoint.JobName] = pushEndpoint
+			jobNames = append(jobNames, pushEndpoint.JobName)
+		}
+
+		inFlight <- struct{}{}
+
+		jobReceipt, err := p.Leaser.LeaseJob(jobNames)
+		if err != nil {
+			<-inFlight
+
+			if err != sql.ErrNoRows {
+				p.Logger.Error("error leasing push job", "err", err)
+			}
+
+			time.Sleep(idleInterval)
+			continue
+		}
+
+		go func() {
+			defer func() { <-inFlight }()
+			p.deliverJob(endpointsByJobName[jobReceipt.JobName], jobReceipt)
+		}()
+	}
+}
+
+func (p *PushDeliveryService) deliverJob(pushEndpoint repositories.PushEndpoint, jobReceipt repositories.JobReceipt) {
+	leaseId := jobReceipt.LeaseID.String
+
+	stopHeartbeat := p.keepLeaseAlive(jobReceipt.JobID, leaseId)
+	statusCode, responseBody, err := p.postJob(pushEndpoint, jobReceipt)
+	stopHeartbeat()
+
+	if err == nil {
+		recordErr := p.Queries.RecordJobReceiptResponse(p.Context, repositories.RecordJobReceiptResponseParams{
+			ResponseStatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: true},
+			ResponseBody:       sql.NullString{String: responseBody, Valid: true},
+			JobID:              jobReceipt.JobID,
+		})
+		if recordErr != nil {
+			p.Logger.Error("error recording push response", "err", recordErr, "job-id", jobReceipt.JobID)
+		}
+	}
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		p.finishJob(jobReceipt.JobID, leaseId, "ok", "")
+	case err == nil && statusCode < 500:
+		// the worker rejected the job, sending it again won't change that
+		p.finishJob(jobReceipt.JobID, leaseId, "error", fmt.Sprintf("worker responded with %d", statusCode))
+	default:
+		message := fmt.Sprintf("worker responded with %d", statusCode)
+		if err != nil {
+			message = "error delivering job: " + err.Error()
+		}
+
+		p.retryJob(pushEndpoint, jobReceipt, message)
+	}
+}
+
+func (p *PushDeliveryService) postJob(pushEndpoint repositories.PushEndpoint, jobReceipt repositories.JobReceipt) (int, string, error) {
+	bodyBytes, err := json.Marshal(scheduler.PushedJob{
+		JobId:      jobReceipt.JobID,
+		JobName:    jobReceipt.JobName,
+		JobContext: jobReceipt.JobContext,
+		RetryCount: jobReceipt.RetryCount,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(p.Context, time.Duration(pushEndpoint.TimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", pushEndpoint.Url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Switchyard-Job-Id", jobReceipt.JobID)
+	req.Header.Set("X-Switchyard-Timestamp", timestamp)
+	req.Header.Set("X-Switchyard-Signature", "sha256="+p.sign(timestamp, bodyBytes))
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	responseBytes, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	if err != nil {
+		return 0, "", err
+	}
+
+	return res.StatusCode, string(responseBytes), nil
+}
+
+// sign covers the timestamp as well as the body, so a captured request can't be replayed later on
+func (p *PushDeliveryService) sign(timestamp string, bodyBytes []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.Config.PushSigningSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(bodyBytes)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// keepLeaseAlive heartbeats while a slow worker is still answering, so the job isn't handed out twice
+func (p *PushDeliveryService) keepLeaseAlive(jobId string, leaseId string) func() {
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := p.Leaser.HeartbeatJob(jobId, leaseId); err != nil {
+					p.Logger.Error("error extending push job lease", "err", err, "job-id", jobId)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (p *PushDeliveryService) retryJob(pushEndpoint repositories.PushEndpoint, jobReceipt repositories.JobReceipt, message string) {
+	leaseId := jobReceipt.LeaseID.String
+
+	if jobReceipt.RetryCount >= pushEndpoint.MaxRetries {
+		p.finishJob(jobReceipt.JobID, leaseId, "error", message)
+		p.Logger.Error("push job marked as failed after max retries", "job-id", jobReceipt.JobID)
+		return
+	}
+
+	if !p.finishJob(jobReceipt.JobID, leaseId, "error", message) {
+		return
+	}
+
+	backoff := time.Duration(float64(baseRetryBackoff) * math.Pow(2, float64(jobReceipt.RetryCount)))
+	backoff = min(backoff, maxRetryBackoff)
+
+	// the scheduled job dispatcher picks the job up again once the backoff has passed
+	_, err := p.Queries.ScheduleJobReceiptRetry(p.Context, repositories.ScheduleJobReceiptRetryParams{
+		RetryCount: jobReceipt.RetryCount + 1,
+		Message:    truncate(message, maxMessageLength),
+		RunAt:      time.Now().Add(backoff).Unix(),
+		UpdatedAt:  time.Now().Unix(),
+		JobID:      jobReceipt.JobID,
+	})
+	if err != nil {
+		p.Logger.Error("error scheduling push job retry", "err", err, "job-id", jobReceipt.JobID)
+		return
+	}
+
+	p.Logger.Info("push job failed, retrying", "job-id", jobReceipt.JobID, "backoff", backoff)
+}
+
+func (p *PushDeliveryService) finishJob(jobId string, leaseId string, status string, message string) bool {
+	err := p.Leaser.FinishJob(jobId, leaseId, status, truncate(message, maxMessageLength))
+	if err != nil {
+		p.Logger.Error("error finishing push job", "err", err, "job-id", jobId)
+		return false
+	}
+
+	return true
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
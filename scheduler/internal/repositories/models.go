@@ -10,15 +10,44 @@ import (
 )
 
 type JobReceipt struct {
-	ID         int32           `json:"id"`
-	JobID      string          `json:"job_id"`
-	Status     string          `json:"status"`
-	RetryCount int32           `json:"retry_count"`
-	Message    string          `json:"message"`
-	JobName    string          `json:"job_name"`
-	JobContext json.RawMessage `json:"job_context"`
-	CreatedAt  int64           `json:"created_at"`
-	UpdatedAt  int64           `json:"updated_at"`
+	ID                 int32           `json:"id"`
+	JobID              string          `json:"job_id"`
+	Status             string          `json:"status"`
+	RetryCount         int32           `json:"retry_count"`
+	Message            string          `json:"message"`
+	JobName            string          `json:"job_name"`
+	JobContext         json.RawMessage `json:"job_context"`
+	CreatedAt          int64           `json:"created_at"`
+	UpdatedAt          int64           `json:"updated_at"`
+	UniqueKey          sql.NullString  `json:"unique_key"`
+	RunAt              int64           `json:"run_at"`
+	LeaseID            sql.NullString  `json:"lease_id"`
+	LeaseExpiresAt     int64           `json:"lease_expires_at"`
+	ResponseStatusCode sql.NullInt32   `json:"response_status_code"`
+	ResponseBody       sql.NullString  `json:"response_body"`
+}
+
+type JobSchema struct {
+	JobName   string          `json:"job_name"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+type LeasedJobName struct {
+	JobName   string `json:"job_name"`
+	ServiceID string `json:"service_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type PushEndpoint struct {
+	JobName    string `json:"job_name"`
+	ServiceID  string `json:"service_id"`
+	Url        string `json:"url"`
+	TimeoutMs  int64  `json:"timeout_ms"`
+	MaxRetries int32  `json:"max_retries"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
 }
 
 type Service struct {
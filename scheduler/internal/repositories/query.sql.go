@@ -9,6 +9,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+
+	"github.com/lib/pq"
 )
 
 const aggregateJobReceiptsByJobID = `-- name: AggregateJobReceiptsByJobID :one
@@ -57,6 +59,55 @@ func (q *Queries) AggregateJobReceiptsByJobID(ctx context.Context, jobName strin
 	return i, err
 }
 
+const claimDueJobReceipts = `-- name: ClaimDueJobReceipts :many
+UPDATE job_receipts
+SET
+    status = 'pending',
+    updated_at = $1
+WHERE status = 'scheduled'
+AND run_at <= $1
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+func (q *Queries) ClaimDueJobReceipts(ctx context.Context, now int64) ([]JobReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueJobReceipts, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobReceipt
+	for rows.Next() {
+		var i JobReceipt
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Status,
+			&i.RetryCount,
+			&i.Message,
+			&i.JobName,
+			&i.JobContext,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UniqueKey,
+			&i.RunAt,
+			&i.LeaseID,
+			&i.LeaseExpiresAt,
+			&i.ResponseStatusCode,
+			&i.ResponseBody,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createJobReceipt = `-- name: CreateJobReceipt :one
 INSERT INTO job_receipts (
     job_id,
@@ -66,11 +117,13 @@ INSERT INTO job_receipts (
     job_name,
     job_context,
     created_at,
-    updated_at
+    updated_at,
+    unique_key,
+    run_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
-RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
 `
 
 type CreateJobReceiptParams struct {
@@ -82,6 +135,8 @@ type CreateJobReceiptParams struct {
 	JobContext json.RawMessage `json:"job_context"`
 	CreatedAt  int64           `json:"created_at"`
 	UpdatedAt  int64           `json:"updated_at"`
+	UniqueKey  sql.NullString  `json:"unique_key"`
+	RunAt      int64           `json:"run_at"`
 }
 
 func (q *Queries) CreateJobReceipt(ctx context.Context, arg CreateJobReceiptParams) (JobReceipt, error) {
@@ -94,6 +149,8 @@ func (q *Queries) CreateJobReceipt(ctx context.Context, arg CreateJobReceiptPara
 		arg.JobContext,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.UniqueKey,
+		arg.RunAt,
 	)
 	var i JobReceipt
 	err := row.Scan(
@@ -106,6 +163,12 @@ func (q *Queries) CreateJobReceipt(ctx context.Context, arg CreateJobReceiptPara
 		&i.JobContext,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
 	)
 	return i, err
 }
@@ -163,6 +226,46 @@ func (q *Queries) DeleteJobReceiptByJobID(ctx context.Context, jobID string) err
 	return err
 }
 
+const deleteJobSchema = `-- name: DeleteJobSchema :exec
+DELETE FROM job_schemas
+WHERE job_name = $1
+`
+
+func (q *Queries) DeleteJobSchema(ctx context.Context, jobName string) error {
+	_, err := q.db.ExecContext(ctx, deleteJobSchema, jobName)
+	return err
+}
+
+const deleteLeasedJobNamesByServiceID = `-- name: DeleteLeasedJobNamesByServiceID :exec
+DELETE FROM leased_job_names
+WHERE service_id = $1
+`
+
+func (q *Queries) DeleteLeasedJobNamesByServiceID(ctx context.Context, serviceID string) error {
+	_, err := q.db.ExecContext(ctx, deleteLeasedJobNamesByServiceID, serviceID)
+	return err
+}
+
+const deletePushEndpoint = `-- name: DeletePushEndpoint :exec
+DELETE FROM push_endpoints
+WHERE job_name = $1
+`
+
+func (q *Queries) DeletePushEndpoint(ctx context.Context, jobName string) error {
+	_, err := q.db.ExecContext(ctx, deletePushEndpoint, jobName)
+	return err
+}
+
+const deletePushEndpointsByServiceID = `-- name: DeletePushEndpointsByServiceID :exec
+DELETE FROM push_endpoints
+WHERE service_id = $1
+`
+
+func (q *Queries) DeletePushEndpointsByServiceID(ctx context.Context, serviceID string) error {
+	_, err := q.db.ExecContext(ctx, deletePushEndpointsByServiceID, serviceID)
+	return err
+}
+
 const deleteService = `-- name: DeleteService :exec
 DELETE FROM services
 WHERE service_id = $1
@@ -173,8 +276,193 @@ func (q *Queries) DeleteService(ctx context.Context, serviceID string) error {
 	return err
 }
 
+const extendJobReceiptLease = `-- name: ExtendJobReceiptLease :one
+UPDATE job_receipts
+SET
+    lease_expires_at = $1,
+    updated_at = $2
+WHERE job_id = $3
+AND lease_id = $4
+AND status = 'running'
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type ExtendJobReceiptLeaseParams struct {
+	LeaseExpiresAt int64          `json:"lease_expires_at"`
+	UpdatedAt      int64          `json:"updated_at"`
+	JobID          string         `json:"job_id"`
+	LeaseID        sql.NullString `json:"lease_id"`
+}
+
+func (q *Queries) ExtendJobReceiptLease(ctx context.Context, arg ExtendJobReceiptLeaseParams) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, extendJobReceiptLease,
+		arg.LeaseExpiresAt,
+		arg.UpdatedAt,
+		arg.JobID,
+		arg.LeaseID,
+	)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
+const failExpiredJobReceiptLeases = `-- name: FailExpiredJobReceiptLeases :many
+UPDATE job_receipts
+SET
+    status = 'error',
+    message = 'marked as failed after max retries',
+    lease_id = NULL,
+    lease_expires_at = 0,
+    updated_at = $1
+WHERE status = 'running'
+AND lease_expires_at < $1
+AND retry_count >= $2
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type FailExpiredJobReceiptLeasesParams struct {
+	Now        int64 `json:"now"`
+	MaxRetries int32 `json:"max_retries"`
+}
+
+func (q *Queries) FailExpiredJobReceiptLeases(ctx context.Context, arg FailExpiredJobReceiptLeasesParams) ([]JobReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, failExpiredJobReceiptLeases, arg.Now, arg.MaxRetries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobReceipt
+	for rows.Next() {
+		var i JobReceipt
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Status,
+			&i.RetryCount,
+			&i.Message,
+			&i.JobName,
+			&i.JobContext,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UniqueKey,
+			&i.RunAt,
+			&i.LeaseID,
+			&i.LeaseExpiresAt,
+			&i.ResponseStatusCode,
+			&i.ResponseBody,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const finishJobReceiptLease = `-- name: FinishJobReceiptLease :one
+UPDATE job_receipts
+SET
+    status = $1,
+    message = $2,
+    lease_expires_at = 0,
+    updated_at = $3
+WHERE job_id = $4
+AND lease_id = $5
+AND status = 'running'
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type FinishJobReceiptLeaseParams struct {
+	Status    string         `json:"status"`
+	Message   string         `json:"message"`
+	UpdatedAt int64          `json:"updated_at"`
+	JobID     string         `json:"job_id"`
+	LeaseID   sql.NullString `json:"lease_id"`
+}
+
+func (q *Queries) FinishJobReceiptLease(ctx context.Context, arg FinishJobReceiptLeaseParams) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, finishJobReceiptLease,
+		arg.Status,
+		arg.Message,
+		arg.UpdatedAt,
+		arg.JobID,
+		arg.LeaseID,
+	)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
+const getActiveJobReceiptByUniqueKey = `-- name: GetActiveJobReceiptByUniqueKey :one
+SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body FROM job_receipts
+WHERE unique_key = $1
+AND status IN ('scheduled', 'pending', 'running')
+ORDER BY (status = 'scheduled') DESC, created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveJobReceiptByUniqueKey(ctx context.Context, uniqueKey sql.NullString) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, getActiveJobReceiptByUniqueKey, uniqueKey)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
 const getJobReceiptByID = `-- name: GetJobReceiptByID :one
-SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at FROM job_receipts
+SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body FROM job_receipts
 WHERE id = $1
 `
 
@@ -191,12 +479,18 @@ func (q *Queries) GetJobReceiptByID(ctx context.Context, id int32) (JobReceipt,
 		&i.JobContext,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
 	)
 	return i, err
 }
 
 const getJobReceiptByJobID = `-- name: GetJobReceiptByJobID :one
-SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at FROM job_receipts
+SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body FROM job_receipts
 WHERE job_id = $1
 `
 
@@ -213,6 +507,49 @@ func (q *Queries) GetJobReceiptByJobID(ctx context.Context, jobID string) (JobRe
 		&i.JobContext,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
+const getJobSchema = `-- name: GetJobSchema :one
+SELECT job_name, schema, created_at, updated_at FROM job_schemas
+WHERE job_name = $1
+`
+
+func (q *Queries) GetJobSchema(ctx context.Context, jobName string) (JobSchema, error) {
+	row := q.db.QueryRowContext(ctx, getJobSchema, jobName)
+	var i JobSchema
+	err := row.Scan(
+		&i.JobName,
+		&i.Schema,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPushEndpoint = `-- name: GetPushEndpoint :one
+SELECT job_name, service_id, url, timeout_ms, max_retries, created_at, updated_at FROM push_endpoints
+WHERE job_name = $1
+`
+
+func (q *Queries) GetPushEndpoint(ctx context.Context, jobName string) (PushEndpoint, error) {
+	row := q.db.QueryRowContext(ctx, getPushEndpoint, jobName)
+	var i PushEndpoint
+	err := row.Scan(
+		&i.JobName,
+		&i.ServiceID,
+		&i.Url,
+		&i.TimeoutMs,
+		&i.MaxRetries,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -282,8 +619,73 @@ func (q *Queries) GetServicesByJobName(ctx context.Context, jobName sql.NullStri
 	return items, nil
 }
 
+const isJobNameLeased = `-- name: IsJobNameLeased :one
+SELECT EXISTS (SELECT 1 FROM leased_job_names WHERE job_name = $1)
+    OR EXISTS (SELECT 1 FROM push_endpoints WHERE job_name = $1)
+`
+
+func (q *Queries) IsJobNameLeased(ctx context.Context, jobName string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isJobNameLeased, jobName)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const leaseJobReceipt = `-- name: LeaseJobReceipt :one
+UPDATE job_receipts
+SET
+    status = 'running',
+    lease_id = $1,
+    lease_expires_at = $2,
+    updated_at = $3
+WHERE job_id = (
+    SELECT job_id FROM job_receipts
+    WHERE status = 'pending'
+    AND job_name = ANY($4::text[])
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type LeaseJobReceiptParams struct {
+	LeaseID        sql.NullString `json:"lease_id"`
+	LeaseExpiresAt int64          `json:"lease_expires_at"`
+	UpdatedAt      int64          `json:"updated_at"`
+	JobNames       []string       `json:"job_names"`
+}
+
+func (q *Queries) LeaseJobReceipt(ctx context.Context, arg LeaseJobReceiptParams) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, leaseJobReceipt,
+		arg.LeaseID,
+		arg.LeaseExpiresAt,
+		arg.UpdatedAt,
+		pq.Array(arg.JobNames),
+	)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
 const listJobReceipts = `-- name: ListJobReceipts :many
-SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at FROM job_receipts
+SELECT id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body FROM job_receipts
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -312,6 +714,77 @@ func (q *Queries) ListJobReceipts(ctx context.Context, arg ListJobReceiptsParams
 			&i.JobContext,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.UniqueKey,
+			&i.RunAt,
+			&i.LeaseID,
+			&i.LeaseExpiresAt,
+			&i.ResponseStatusCode,
+			&i.ResponseBody,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLeasedJobNames = `-- name: ListLeasedJobNames :many
+SELECT job_name FROM leased_job_names
+WHERE job_name = ANY($1::text[])
+ORDER BY job_name
+`
+
+func (q *Queries) ListLeasedJobNames(ctx context.Context, jobNames []string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listLeasedJobNames, pq.Array(jobNames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var job_name string
+		if err := rows.Scan(&job_name); err != nil {
+			return nil, err
+		}
+		items = append(items, job_name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPushEndpoints = `-- name: ListPushEndpoints :many
+SELECT job_name, service_id, url, timeout_ms, max_retries, created_at, updated_at FROM push_endpoints
+ORDER BY job_name
+`
+
+func (q *Queries) ListPushEndpoints(ctx context.Context) ([]PushEndpoint, error) {
+	rows, err := q.db.QueryContext(ctx, listPushEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PushEndpoint
+	for rows.Next() {
+		var i PushEndpoint
+		if err := rows.Scan(
+			&i.JobName,
+			&i.ServiceID,
+			&i.Url,
+			&i.TimeoutMs,
+			&i.MaxRetries,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -407,6 +880,181 @@ func (q *Queries) ListServicesWithJobs(ctx context.Context) ([]Service, error) {
 	return items, nil
 }
 
+const lockUniqueKey = `-- name: LockUniqueKey :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text))
+`
+
+func (q *Queries) LockUniqueKey(ctx context.Context, uniqueKey string) error {
+	_, err := q.db.ExecContext(ctx, lockUniqueKey, uniqueKey)
+	return err
+}
+
+const recordJobReceiptResponse = `-- name: RecordJobReceiptResponse :exec
+UPDATE job_receipts
+SET
+    response_status_code = $1,
+    response_body = $2
+WHERE job_id = $3
+`
+
+type RecordJobReceiptResponseParams struct {
+	ResponseStatusCode sql.NullInt32  `json:"response_status_code"`
+	ResponseBody       sql.NullString `json:"response_body"`
+	JobID              string         `json:"job_id"`
+}
+
+func (q *Queries) RecordJobReceiptResponse(ctx context.Context, arg RecordJobReceiptResponseParams) error {
+	_, err := q.db.ExecContext(ctx, recordJobReceiptResponse, arg.ResponseStatusCode, arg.ResponseBody, arg.JobID)
+	return err
+}
+
+const requeueExpiredJobReceiptLeases = `-- name: RequeueExpiredJobReceiptLeases :many
+UPDATE job_receipts
+SET
+    status = 'pending',
+    retry_count = retry_count + 1,
+    lease_id = NULL,
+    lease_expires_at = 0,
+    updated_at = $1
+WHERE status = 'running'
+AND lease_expires_at < $1
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+func (q *Queries) RequeueExpiredJobReceiptLeases(ctx context.Context, now int64) ([]JobReceipt, error) {
+	rows, err := q.db.QueryContext(ctx, requeueExpiredJobReceiptLeases, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobReceipt
+	for rows.Next() {
+		var i JobReceipt
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Status,
+			&i.RetryCount,
+			&i.Message,
+			&i.JobName,
+			&i.JobContext,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UniqueKey,
+			&i.RunAt,
+			&i.LeaseID,
+			&i.LeaseExpiresAt,
+			&i.ResponseStatusCode,
+			&i.ResponseBody,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleJobReceipt = `-- name: RescheduleJobReceipt :one
+UPDATE job_receipts
+SET
+    job_context = $2,
+    run_at = $3,
+    updated_at = $4
+WHERE job_id = $1
+AND status = 'scheduled'
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type RescheduleJobReceiptParams struct {
+	JobID      string          `json:"job_id"`
+	JobContext json.RawMessage `json:"job_context"`
+	RunAt      int64           `json:"run_at"`
+	UpdatedAt  int64           `json:"updated_at"`
+}
+
+func (q *Queries) RescheduleJobReceipt(ctx context.Context, arg RescheduleJobReceiptParams) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleJobReceipt,
+		arg.JobID,
+		arg.JobContext,
+		arg.RunAt,
+		arg.UpdatedAt,
+	)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
+const scheduleJobReceiptRetry = `-- name: ScheduleJobReceiptRetry :one
+UPDATE job_receipts
+SET
+    status = 'scheduled',
+    retry_count = $1,
+    message = $2,
+    run_at = $3,
+    updated_at = $4
+WHERE job_id = $5
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
+`
+
+type ScheduleJobReceiptRetryParams struct {
+	RetryCount int32  `json:"retry_count"`
+	Message    string `json:"message"`
+	RunAt      int64  `json:"run_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+	JobID      string `json:"job_id"`
+}
+
+func (q *Queries) ScheduleJobReceiptRetry(ctx context.Context, arg ScheduleJobReceiptRetryParams) (JobReceipt, error) {
+	row := q.db.QueryRowContext(ctx, scheduleJobReceiptRetry,
+		arg.RetryCount,
+		arg.Message,
+		arg.RunAt,
+		arg.UpdatedAt,
+		arg.JobID,
+	)
+	var i JobReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.RetryCount,
+		&i.Message,
+		&i.JobName,
+		&i.JobContext,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
 const setServiceJobName = `-- name: SetServiceJobName :one
 UPDATE services
 SET job_name = $1
@@ -470,7 +1118,7 @@ SET
     job_context = $6,
     updated_at = $7
 WHERE id = $1
-RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
 `
 
 type UpdateJobReceiptByIDParams struct {
@@ -504,6 +1152,12 @@ func (q *Queries) UpdateJobReceiptByID(ctx context.Context, arg UpdateJobReceipt
 		&i.JobContext,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
 	)
 	return i, err
 }
@@ -518,7 +1172,7 @@ SET
     job_context = $6,
     updated_at = $7
 WHERE job_id = $1
-RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at
+RETURNING id, job_id, status, retry_count, message, job_name, job_context, created_at, updated_at, unique_key, run_at, lease_id, lease_expires_at, response_status_code, response_body
 `
 
 type UpdateJobReceiptByJobIDParams struct {
@@ -552,6 +1206,12 @@ func (q *Queries) UpdateJobReceiptByJobID(ctx context.Context, arg UpdateJobRece
 		&i.JobContext,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.UniqueKey,
+		&i.RunAt,
+		&i.LeaseID,
+		&i.LeaseExpiresAt,
+		&i.ResponseStatusCode,
+		&i.ResponseBody,
 	)
 	return i, err
 }
@@ -586,3 +1246,125 @@ func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (S
 	)
 	return i, err
 }
+
+const upsertJobSchema = `-- name: UpsertJobSchema :one
+INSERT INTO job_schemas (
+    job_name,
+    schema,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    schema = EXCLUDED.schema,
+    updated_at = EXCLUDED.updated_at
+RETURNING job_name, schema, created_at, updated_at
+`
+
+type UpsertJobSchemaParams struct {
+	JobName   string          `json:"job_name"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt int64           `json:"created_at"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+func (q *Queries) UpsertJobSchema(ctx context.Context, arg UpsertJobSchemaParams) (JobSchema, error) {
+	row := q.db.QueryRowContext(ctx, upsertJobSchema,
+		arg.JobName,
+		arg.Schema,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i JobSchema
+	err := row.Scan(
+		&i.JobName,
+		&i.Schema,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertLeasedJobName = `-- name: UpsertLeasedJobName :one
+INSERT INTO leased_job_names (
+    job_name,
+    service_id,
+    created_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    service_id = EXCLUDED.service_id
+RETURNING job_name, service_id, created_at
+`
+
+type UpsertLeasedJobNameParams struct {
+	JobName   string `json:"job_name"`
+	ServiceID string `json:"service_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func (q *Queries) UpsertLeasedJobName(ctx context.Context, arg UpsertLeasedJobNameParams) (LeasedJobName, error) {
+	row := q.db.QueryRowContext(ctx, upsertLeasedJobName, arg.JobName, arg.ServiceID, arg.CreatedAt)
+	var i LeasedJobName
+	err := row.Scan(&i.JobName, &i.ServiceID, &i.CreatedAt)
+	return i, err
+}
+
+const upsertPushEndpoint = `-- name: UpsertPushEndpoint :one
+INSERT INTO push_endpoints (
+    job_name,
+    service_id,
+    url,
+    timeout_ms,
+    max_retries,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    service_id = EXCLUDED.service_id,
+    url = EXCLUDED.url,
+    timeout_ms = EXCLUDED.timeout_ms,
+    max_retries = EXCLUDED.max_retries,
+    updated_at = EXCLUDED.updated_at
+RETURNING job_name, service_id, url, timeout_ms, max_retries, created_at, updated_at
+`
+
+type UpsertPushEndpointParams struct {
+	JobName    string `json:"job_name"`
+	ServiceID  string `json:"service_id"`
+	Url        string `json:"url"`
+	TimeoutMs  int64  `json:"timeout_ms"`
+	MaxRetries int32  `json:"max_retries"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+func (q *Queries) UpsertPushEndpoint(ctx context.Context, arg UpsertPushEndpointParams) (PushEndpoint, error) {
+	row := q.db.QueryRowContext(ctx, upsertPushEndpoint,
+		arg.JobName,
+		arg.ServiceID,
+		arg.Url,
+		arg.TimeoutMs,
+		arg.MaxRetries,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i PushEndpoint
+	err := row.Scan(
+		&i.JobName,
+		&i.ServiceID,
+		&i.Url,
+		&i.TimeoutMs,
+		&i.MaxRetries,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+)
+
+// JobPublisher hands a job receipt that has already been written to the database over to workers
+type JobPublisher interface {
+	PublishJob(jobReceipt repositories.JobReceipt) error
+}
+
+// JobLeaser lets workers pull jobs over HTTP. Every method returns sql.ErrNoRows
+// when there is no job to lease or the lease is no longer held
+type JobLeaser interface {
+	LeaseJob(jobNames []string) (repositories.JobReceipt, error)
+	HeartbeatJob(jobId string, leaseId string) (repositories.JobReceipt, error)
+	FinishJob(jobId string, leaseId string, status string, message string) error
+	// RouteJobName moves jobs already queued for the job name to wherever it's
+	// delivered now that it's been registered or unregistered for leasing
+	RouteJobName(jobName string) error
+}
+
+const (
+	maxLeaseWait      = 60 * time.Second
+	leasePollInterval = 1 * time.Second
+)
+
+func (s *SchedulerService) LeaseJob(w http.ResponseWriter, r *http.Request) error {
+	requestBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	leaseJobRequest := LeaseJobRequest{}
+
+	if err := json.Unmarshal(requestBytes, &leaseJobRequest); err != nil {
+		return fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	var fieldErrors []FieldError
+
+	if len(leaseJobRequest.JobNames) == 0 {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/job_names", Message: "at least one job name is required"})
+	}
+
+	var wait time.Duration
+
+	if leaseJobRequest.Wait != "" {
+		wait, err = time.ParseDuration(leaseJobRequest.Wait)
+		if err != nil || wait < 0 || wait > maxLeaseWait {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/wait", Message: "wait must be a duration between 0s and 60s"})
+		}
+	}
+
+	leasedJobNames, err := s.Queries.ListLeasedJobNames(s.Context, leaseJobRequest.JobNames)
+	if err != nil {
+		return fmt.Errorf("error fetching leased job names: %w", err)
+	}
+
+	for _, jobName := range leaseJobRequest.JobNames {
+		if !slices.Contains(leasedJobNames, jobName) {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/job_names", Message: jobName + " isn't registered for leasing"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return writeValidationErrors(w, "invalid lease request", fieldErrors)
+	}
+
+	jobReceipt, err := s.waitForJob(r.Context(), leaseJobRequest.JobNames, wait)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		return fmt.Errorf("error leasing job: %w", err)
+	}
+
+	responseBytes, err := json.Marshal(LeasedJob{
+		JobId:          jobReceipt.JobID,
+		JobName:        jobReceipt.JobName,
+		JobContext:     jobReceipt.JobContext,
+		RetryCount:     jobReceipt.RetryCount,
+		LeaseId:        jobReceipt.LeaseID.String,
+		LeaseExpiresAt: jobReceipt.LeaseExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding leased job: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+// waitForJob long-polls the leaser until a job shows up, the wait runs out or the worker hangs up
+func (s *SchedulerService) waitForJob(ctx context.Context, jobNames []string, wait time.Duration) (repositories.JobReceipt, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		jobReceipt, err := s.Leaser.LeaseJob(jobNames)
+		if err != sql.ErrNoRows || time.Now().Add(leasePollInterval).After(deadline) {
+			return jobReceipt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return repositories.JobReceipt{}, sql.ErrNoRows
+		case <-time.After(leasePollInterval):
+		}
+	}
+}
+
+// routeJobNames is called after a registration changes, so jobs that were queued
+// before it follow their job name instead of being stranded
+func (s *SchedulerService) routeJobNames(jobNames ...string) error {
+	for _, jobName := range slices.Compact(slices.Sorted(slices.Values(jobNames))) {
+		if jobName == "" {
+			continue
+		}
+
+		if err := s.Leaser.RouteJobName(jobName); err != nil {
+			return fmt.Errorf("error routing queued %s jobs: %w", jobName, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SchedulerService) HeartbeatJob(w http.ResponseWriter, r *http.Request) error {
+	requestBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	heartbeatJobRequest := HeartbeatJobRequest{}
+
+	if err := json.Unmarshal(requestBytes, &heartbeatJobRequest); err != nil {
+		return fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	jobReceipt, err := s.Leaser.HeartbeatJob(heartbeatJobRequest.JobId, heartbeatJobRequest.LeaseId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Lease is not held for job "+heartbeatJobRequest.JobId, http.StatusConflict)
+			return nil
+		}
+		return fmt.Errorf("error extending job lease: %w", err)
+	}
+
+	responseBytes, err := json.Marshal(HeartbeatJobResponse{
+		LeaseExpiresAt: jobReceipt.LeaseExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding heartbeat response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+func (s *SchedulerService) FinishJob(w http.ResponseWriter, r *http.Request) error {
+	requestBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	finishJobRequest := FinishJobRequest{}
+
+	if err := json.Unmarshal(requestBytes, &finishJobRequest); err != nil {
+		return fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	var fieldErrors []FieldError
+
+	if finishJobRequest.JobId == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/job_id", Message: "job_id is required"})
+	}
+
+	if finishJobRequest.LeaseId == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/lease_id", Message: "lease_id is required"})
+	}
+
+	if finishJobRequest.Status != "ok" && finishJobRequest.Status != "error" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/status", Message: "status must be ok or error"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return writeValidationErrors(w, "invalid finish request", fieldErrors)
+	}
+
+	err = s.Leaser.FinishJob(finishJobRequest.JobId, finishJobRequest.LeaseId, finishJobRequest.Status, finishJobRequest.Message)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Lease is not held for job "+finishJobRequest.JobId, http.StatusConflict)
+			return nil
+		}
+		return fmt.Errorf("error finishing job: %w", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
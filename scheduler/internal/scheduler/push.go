@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"net/url"
+	"time"
+)
+
+const (
+	defaultPushTimeout    = 30 * time.Second
+	maxPushTimeout        = 5 * time.Minute
+	defaultPushMaxRetries = 3
+)
+
+func validateRegisterWorkerServiceRequest(request RegisterWorkerServiceRequest, pushSigningSecret string) (time.Duration, int32, []FieldError) {
+	var fieldErrors []FieldError
+
+	if request.ServiceId == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/service_id", Message: "service_id is required"})
+	}
+
+	if request.JobName == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/job_name", Message: "job_name is required"})
+	}
+
+	pushTimeout := defaultPushTimeout
+	var pushMaxRetries int32 = defaultPushMaxRetries
+
+	if request.Lease && request.PushUrl != "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/lease", Message: "lease can't be set along with push_url, the scheduler leases pushed jobs itself"})
+	}
+
+	if request.PushUrl == "" {
+		return pushTimeout, pushMaxRetries, fieldErrors
+	}
+
+	pushUrl, err := url.Parse(request.PushUrl)
+	if err != nil || (pushUrl.Scheme != "http" && pushUrl.Scheme != "https") || pushUrl.Host == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/push_url", Message: "push_url must be an absolute http or https url"})
+	}
+
+	// pushed jobs are only ever sent signed, see DeliverPushJobs
+	if pushSigningSecret == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "/push_url", Message: "push_url can't be set while PUSH_SIGNING_SECRET is unset"})
+	}
+
+	if request.PushTimeout != "" {
+		pushTimeout, err = time.ParseDuration(request.PushTimeout)
+		if err != nil || pushTimeout <= 0 || pushTimeout > maxPushTimeout {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/push_timeout", Message: "push_timeout must be a duration between 0s and 5m"})
+		}
+	}
+
+	if request.PushMaxRetries != nil {
+		pushMaxRetries = *request.PushMaxRetries
+		if pushMaxRetries < 0 {
+			fieldErrors = append(fieldErrors, FieldError{Field: "/push_max_retries", Message: "push_max_retries can't be negative"})
+		}
+	}
+
+	return pushTimeout, pushMaxRetries, fieldErrors
+}
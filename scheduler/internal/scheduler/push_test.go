@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestValidateRegisterWorkerServiceRequest(t *testing.T) {
+	negativeRetries := int32(-1)
+
+	tests := []struct {
+		name    string
+		request RegisterWorkerServiceRequest
+		// unsigned runs the validation without a PUSH_SIGNING_SECRET
+		unsigned bool
+		timeout  time.Duration
+		fields   []string
+	}{
+		{
+			name:    "pull worker",
+			request: RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email"},
+			timeout: defaultPushTimeout,
+		},
+		{
+			name:    "push worker",
+			request: RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email", PushUrl: "https://worker.example.com/jobs", PushTimeout: "10s"},
+			timeout: 10 * time.Second,
+		},
+		{
+			name:    "missing ids",
+			request: RegisterWorkerServiceRequest{},
+			timeout: defaultPushTimeout,
+			fields:  []string{"/job_name", "/service_id"},
+		},
+		{
+			name:    "relative push url",
+			request: RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email", PushUrl: "/jobs"},
+			timeout: defaultPushTimeout,
+			fields:  []string{"/push_url"},
+		},
+		{
+			name:    "push options out of range",
+			request: RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email", PushUrl: "http://worker/jobs", PushTimeout: "10m", PushMaxRetries: &negativeRetries},
+			timeout: 10 * time.Minute,
+			fields:  []string{"/push_max_retries", "/push_timeout"},
+		},
+		{
+			name:     "push worker without a signing secret",
+			request:  RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email", PushUrl: "https://worker.example.com/jobs"},
+			unsigned: true,
+			timeout:  defaultPushTimeout,
+			fields:   []string{"/push_url"},
+		},
+		{
+			name:     "pull worker without a signing secret",
+			request:  RegisterWorkerServiceRequest{ServiceId: "svc", JobName: "send-email", Lease: true},
+			unsigned: true,
+			timeout:  defaultPushTimeout,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pushSigningSecret := "secret"
+			if test.unsigned {
+				pushSigningSecret = ""
+			}
+
+			timeout, _, fieldErrors := validateRegisterWorkerServiceRequest(test.request, pushSigningSecret)
+
+			var fields []string
+			for _, fieldError := range fieldErrors {
+				fields = append(fields, fieldError.Field)
+			}
+			slices.Sort(fields)
+
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("got field errors %v, want %v", fields, test.fields)
+			}
+
+			if timeout != test.timeout {
+				t.Errorf("got timeout %s, want %s", timeout, test.timeout)
+			}
+		})
+	}
+}
@@ -8,25 +8,37 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
-	messagebus "github.com/ferretcode/switchyard/scheduler/internal/message_bus"
 	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+	"github.com/ferretcode/switchyard/scheduler/pkg/types"
 	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
 )
 
 type SchedulerService struct {
-	Logger            *slog.Logger
-	Queries           *repositories.Queries
-	Context           context.Context
-	MessageBusService *messagebus.MessageBusService
+	Logger    *slog.Logger
+	Config    *types.Config
+	Queries   *repositories.Queries
+	DB        *sqlx.DB
+	Context   context.Context
+	Publisher JobPublisher
+	Leaser    JobLeaser
+
+	schemaCache *jobSchemaCache
 }
 
-func NewSchedulerService(logger *slog.Logger, queries *repositories.Queries, context context.Context, messageBusService *messagebus.MessageBusService) SchedulerService {
+func NewSchedulerService(logger *slog.Logger, config *types.Config, queries *repositories.Queries, db *sqlx.DB, context context.Context, publisher JobPublisher, leaser JobLeaser) SchedulerService {
 	return SchedulerService{
-		Logger:            logger,
-		Queries:           queries,
-		Context:           context,
-		MessageBusService: messageBusService,
+		Logger:    logger,
+		Config:    config,
+		Queries:   queries,
+		DB:        db,
+		Context:   context,
+		Publisher: publisher,
+		Leaser:    leaser,
+
+		schemaCache: newJobSchemaCache(),
 	}
 }
 
@@ -51,44 +63,104 @@ func (s *SchedulerService) GetJobStatistics(w http.ResponseWriter, r *http.Reque
 }
 
 func (s *SchedulerService) RegisterWorkerService(w http.ResponseWriter, r *http.Request) error {
-	serviceId := chi.URLParam(r, "id")
-
-	existingService, err := s.Queries.GetService(s.Context, serviceId)
+	requestBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("service with id %s does not exist", serviceId)
-		}
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	registerWorkerServiceRequest := RegisterWorkerServiceRequest{}
+
+	if err := json.Unmarshal(requestBytes, &registerWorkerServiceRequest); err != nil {
+		return fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	pushTimeout, pushMaxRetries, fieldErrors := validateRegisterWorkerServiceRequest(registerWorkerServiceRequest, s.Config.PushSigningSecret)
+	if len(fieldErrors) > 0 {
+		return writeValidationErrors(w, "invalid worker service registration", fieldErrors)
+	}
+
+	serviceId := registerWorkerServiceRequest.ServiceId
+	jobName := sql.NullString{String: registerWorkerServiceRequest.JobName, Valid: true}
+
+	service, err := s.Queries.GetService(s.Context, serviceId)
+	if err != nil && err != sql.ErrNoRows {
 		return fmt.Errorf("error fetching service from database: %w", err)
 	}
 
-	// we want to keep the already existing service options, so we'll just remove the job name
-	if existingService.Enabled {
+	if err == sql.ErrNoRows {
+		_, err = s.Queries.CreateService(s.Context, repositories.CreateServiceParams{
+			ServiceID: serviceId,
+			JobName:   jobName,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating service: %w", err)
+		}
+	} else {
 		_, err = s.Queries.SetServiceJobName(s.Context, repositories.SetServiceJobNameParams{
 			ServiceID: serviceId,
-			JobName:   sql.NullString{String: "", Valid: false},
+			JobName:   jobName,
 		})
 		if err != nil {
-			return fmt.Errorf("error disabling service: %w", err)
+			return fmt.Errorf("error setting service job name: %w", err)
 		}
+	}
 
-		w.WriteHeader(200)
-		return nil
+	// a service works on a single job, so anything left over from a previous registration goes
+	err = s.Queries.DeleteLeasedJobNamesByServiceID(s.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error deleting leased job names: %w", err)
 	}
 
-	err = s.Queries.DeleteService(s.Context, serviceId)
+	err = s.Queries.DeletePushEndpointsByServiceID(s.Context, serviceId)
 	if err != nil {
-		return fmt.Errorf("error deleting service: %w", err)
+		return fmt.Errorf("error deleting push endpoints: %w", err)
 	}
 
-	w.WriteHeader(200)
-	return nil
+	now := time.Now().Unix()
+
+	if registerWorkerServiceRequest.Lease {
+		_, err = s.Queries.UpsertLeasedJobName(s.Context, repositories.UpsertLeasedJobNameParams{
+			JobName:   registerWorkerServiceRequest.JobName,
+			ServiceID: serviceId,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("error saving leased job name: %w", err)
+		}
+	}
+
+	if registerWorkerServiceRequest.PushUrl != "" {
+		_, err = s.Queries.UpsertPushEndpoint(s.Context, repositories.UpsertPushEndpointParams{
+			JobName:    registerWorkerServiceRequest.JobName,
+			ServiceID:  serviceId,
+			Url:        registerWorkerServiceRequest.PushUrl,
+			TimeoutMs:  pushTimeout.Milliseconds(),
+			MaxRetries: pushMaxRetries,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			return fmt.Errorf("error saving push endpoint: %w", err)
+		}
+	}
 
+	if err := s.routeJobNames(service.JobName.String, registerWorkerServiceRequest.JobName); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 func (s *SchedulerService) UnregisterWorkerService(w http.ResponseWriter, r *http.Request) error {
 	serviceId := chi.URLParam(r, "id")
 
-	_, err := s.Queries.SetServiceJobName(s.Context, repositories.SetServiceJobNameParams{
+	service, err := s.Queries.GetService(s.Context, serviceId)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error fetching service from database: %w", err)
+	}
+
+	_, err = s.Queries.SetServiceJobName(s.Context, repositories.SetServiceJobNameParams{
 		ServiceID: serviceId,
 		JobName:   sql.NullString{Valid: false},
 	})
@@ -96,6 +168,20 @@ func (s *SchedulerService) UnregisterWorkerService(w http.ResponseWriter, r *htt
 		return fmt.Errorf("error deleting service: %w", err)
 	}
 
+	err = s.Queries.DeleteLeasedJobNamesByServiceID(s.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error deleting leased job names: %w", err)
+	}
+
+	err = s.Queries.DeletePushEndpointsByServiceID(s.Context, serviceId)
+	if err != nil {
+		return fmt.Errorf("error deleting push endpoints: %w", err)
+	}
+
+	if err := s.routeJobNames(service.JobName.String); err != nil {
+		return err
+	}
+
 	w.WriteHeader(200)
 	return nil
 }
@@ -112,10 +198,160 @@ func (s *SchedulerService) ScheduleJob(w http.ResponseWriter, r *http.Request) e
 		return fmt.Errorf("error parsing request body: %w", err)
 	}
 
-	err = s.MessageBusService.SendScheduleJobMessage(scheduleJobRequest.JobName, scheduleJobRequest.JobContext)
+	if scheduleJobRequest.JobName == "" {
+		http.Error(w, "Job name is required", http.StatusBadRequest)
+		return nil
+	}
+
+	jobSchema, err := s.Queries.GetJobSchema(s.Context, scheduleJobRequest.JobName)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error fetching job schema: %w", err)
+	}
+
+	if err == nil {
+		schema, err := s.schemaCache.get(jobSchema.JobName, jobSchema.Schema)
+		if err != nil {
+			return fmt.Errorf("error compiling job schema: %w", err)
+		}
+
+		fieldErrors, err := validateJobContext(schema, scheduleJobRequest.JobContext)
+		if err != nil {
+			return fmt.Errorf("error validating job context: %w", err)
+		}
+
+		if len(fieldErrors) > 0 {
+			return writeValidationErrors(w, "job context does not match the schema registered for "+scheduleJobRequest.JobName, fieldErrors)
+		}
+	}
+
+	var response ScheduleJobResponse
+
+	if scheduleJobRequest.Unique != nil {
+		if fieldErrors := validateUniqueJobOptions(scheduleJobRequest.Unique); len(fieldErrors) > 0 {
+			return writeValidationErrors(w, "invalid unique job options", fieldErrors)
+		}
+
+		response, err = s.scheduleUniqueJob(scheduleJobRequest)
+		if err != nil {
+			return err
+		}
+	} else {
+		contextBytes, err := json.Marshal(scheduleJobRequest.JobContext)
+		if err != nil {
+			return fmt.Errorf("error encoding job context: %w", err)
+		}
+
+		jobReceipt, err := s.createJobReceipt(s.Queries, scheduleJobRequest.JobName, contextBytes, "", "pending", time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := s.Publisher.PublishJob(jobReceipt); err != nil {
+			return err
+		}
+
+		response = ScheduleJobResponse{JobId: jobReceipt.JobID, Status: "queued"}
+	}
+
+	responseBytes, err := json.Marshal(response)
 	if err != nil {
-		return err
+		return fmt.Errorf("error encoding schedule job response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+func (s *SchedulerService) RegisterJobSchema(w http.ResponseWriter, r *http.Request) error {
+	jobName := chi.URLParam(r, "name")
+
+	schemaBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	if !json.Valid(schemaBytes) {
+		http.Error(w, "Job schema must be valid JSON", http.StatusBadRequest)
+		return nil
+	}
+
+	// reject schemas we can't compile now, rather than failing every schedule request later
+	if _, err := compileJobSchema(jobName, schemaBytes); err != nil {
+		http.Error(w, "Invalid job schema: "+err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	now := time.Now().Unix()
+
+	_, err = s.Queries.UpsertJobSchema(s.Context, repositories.UpsertJobSchemaParams{
+		JobName:   jobName,
+		Schema:    json.RawMessage(schemaBytes),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("error saving job schema: %w", err)
+	}
+
+	s.schemaCache.invalidate(jobName)
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *SchedulerService) GetJobSchema(w http.ResponseWriter, r *http.Request) error {
+	jobName := chi.URLParam(r, "name")
+
+	jobSchema, err := s.Queries.GetJobSchema(s.Context, jobName)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "No schema is registered for job "+jobName, http.StatusNotFound)
+			return nil
+		}
+		return fmt.Errorf("error fetching job schema: %w", err)
 	}
 
+	responseBytes, err := json.Marshal(jobSchema)
+	if err != nil {
+		return fmt.Errorf("error encoding job schema: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBytes)
+
+	return nil
+}
+
+func (s *SchedulerService) UnregisterJobSchema(w http.ResponseWriter, r *http.Request) error {
+	jobName := chi.URLParam(r, "name")
+
+	err := s.Queries.DeleteJobSchema(s.Context, jobName)
+	if err != nil {
+		return fmt.Errorf("error deleting job schema: %w", err)
+	}
+
+	s.schemaCache.invalidate(jobName)
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func writeValidationErrors(w http.ResponseWriter, message string, fieldErrors []FieldError) error {
+	responseBytes, err := json.Marshal(ValidationErrorResponse{
+		Error:  message,
+		Fields: fieldErrors,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding validation errors: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(responseBytes)
+
 	return nil
 }
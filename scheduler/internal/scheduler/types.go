@@ -1,11 +1,82 @@
 package scheduler
 
+import "encoding/json"
+
 type RegisterWorkerServiceRequest struct {
-	ServiceId string `json:"service_id"`
-	JobName   string `json:"job_name"`
+	ServiceId      string `json:"service_id"`
+	JobName        string `json:"job_name"`
+	Lease          bool   `json:"lease,omitempty"`
+	PushUrl        string `json:"push_url,omitempty"`
+	PushTimeout    string `json:"push_timeout,omitempty"`
+	PushMaxRetries *int32 `json:"push_max_retries,omitempty"`
 }
 
+const (
+	UniqueModeDrop     = "drop"
+	UniqueModeCoalesce = "coalesce"
+	UniqueModeDebounce = "debounce"
+)
+
 type ScheduleJobRequest struct {
-	JobName    string         `json:"job_name"`
-	JobContext map[string]any `json:"job_context"`
+	JobName    string            `json:"job_name"`
+	JobContext map[string]any    `json:"job_context"`
+	Unique     *UniqueJobOptions `json:"unique,omitempty"`
+}
+
+type UniqueJobOptions struct {
+	Fields         []string `json:"fields"`
+	Mode           string   `json:"mode"`
+	DebouncePeriod string   `json:"debounce_period,omitempty"`
+}
+
+type ScheduleJobResponse struct {
+	JobId  string `json:"job_id,omitempty"`
+	Status string `json:"status"`
+}
+
+type LeaseJobRequest struct {
+	JobNames []string `json:"job_names"`
+	Wait     string   `json:"wait,omitempty"`
+}
+
+type LeasedJob struct {
+	JobId          string          `json:"job_id"`
+	JobName        string          `json:"job_name"`
+	JobContext     json.RawMessage `json:"job_context"`
+	RetryCount     int32           `json:"retry_count"`
+	LeaseId        string          `json:"lease_id"`
+	LeaseExpiresAt int64           `json:"lease_expires_at"`
+}
+
+type HeartbeatJobRequest struct {
+	JobId   string `json:"job_id"`
+	LeaseId string `json:"lease_id"`
+}
+
+type HeartbeatJobResponse struct {
+	LeaseExpiresAt int64 `json:"lease_expires_at"`
+}
+
+type PushedJob struct {
+	JobId      string          `json:"job_id"`
+	JobName    string          `json:"job_name"`
+	JobContext json.RawMessage `json:"job_context"`
+	RetryCount int32           `json:"retry_count"`
+}
+
+type FinishJobRequest struct {
+	JobId   string `json:"job_id"`
+	LeaseId string `json:"lease_id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
 }
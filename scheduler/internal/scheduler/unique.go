@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ferretcode/switchyard/scheduler/internal/repositories"
+	"github.com/google/uuid"
+)
+
+func validateUniqueJobOptions(options *UniqueJobOptions) []FieldError {
+	var fieldErrors []FieldError
+
+	if !slices.Contains([]string{UniqueModeDrop, UniqueModeCoalesce, UniqueModeDebounce}, options.Mode) {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   "/unique/mode",
+			Message: "mode must be one of drop, coalesce or debounce",
+		})
+	}
+
+	if options.Mode == UniqueModeDebounce {
+		debouncePeriod, err := time.ParseDuration(options.DebouncePeriod)
+		if err != nil || debouncePeriod <= 0 {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   "/unique/debounce_period",
+				Message: "debounce_period must be a positive duration, e.g. 30s",
+			})
+		}
+	}
+
+	return fieldErrors
+}
+
+// uniqueJobKey identifies a job by its name and the selected context fields, so
+// that two submissions touching the same fields resolve to the same key
+func uniqueJobKey(jobName string, jobContext map[string]any, fields []string) (string, error) {
+	selected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		selected[field] = jobContext[field]
+	}
+
+	// json.Marshal sorts map keys, so the hash doesn't depend on field order
+	selectedBytes, err := json.Marshal(selected)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(selectedBytes)
+
+	return jobName + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+func (s *SchedulerService) scheduleUniqueJob(scheduleJobRequest ScheduleJobRequest) (ScheduleJobResponse, error) {
+	uniqueKey, err := uniqueJobKey(scheduleJobRequest.JobName, scheduleJobRequest.JobContext, scheduleJobRequest.Unique.Fields)
+	if err != nil {
+		return ScheduleJobResponse{}, fmt.Errorf("error building unique job key: %w", err)
+	}
+
+	contextBytes, err := json.Marshal(scheduleJobRequest.JobContext)
+	if err != nil {
+		return ScheduleJobResponse{}, fmt.Errorf("error encoding job context: %w", err)
+	}
+
+	tx, err := s.DB.BeginTx(s.Context, nil)
+	if err != nil {
+		return ScheduleJobResponse{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := s.Queries.WithTx(tx)
+
+	// serialize submissions for the same key so two concurrent requests can't both create a job
+	if err := queries.LockUniqueKey(s.Context, uniqueKey); err != nil {
+		return ScheduleJobResponse{}, fmt.Errorf("error locking unique job key: %w", err)
+	}
+
+	existingJob, err := queries.GetActiveJobReceiptByUniqueKey(s.Context, sql.NullString{String: uniqueKey, Valid: true})
+	if err != nil && err != sql.ErrNoRows {
+		return ScheduleJobResponse{}, fmt.Errorf("error fetching existing unique job: %w", err)
+	}
+
+	hasExistingJob := err == nil
+	now := time.Now()
+
+	var response ScheduleJobResponse
+	var publishReceipt *repositories.JobReceipt
+
+	switch scheduleJobRequest.Unique.Mode {
+	case UniqueModeDrop, UniqueModeCoalesce:
+		if hasExistingJob {
+			if scheduleJobRequest.Unique.Mode == UniqueModeDrop {
+				response = ScheduleJobResponse{Status: "dropped"}
+			} else {
+				response = ScheduleJobResponse{JobId: existingJob.JobID, Status: "coalesced"}
+			}
+			break
+		}
+
+		jobReceipt, err := s.createJobReceipt(queries, scheduleJobRequest.JobName, contextBytes, uniqueKey, "pending", now)
+		if err != nil {
+			return ScheduleJobResponse{}, err
+		}
+
+		publishReceipt = &jobReceipt
+		response = ScheduleJobResponse{JobId: jobReceipt.JobID, Status: "queued"}
+	case UniqueModeDebounce:
+		debouncePeriod, _ := time.ParseDuration(scheduleJobRequest.Unique.DebouncePeriod)
+		runAt := now.Add(debouncePeriod)
+
+		// a job that hasn't been handed to a worker yet is pushed back; one that is
+		// already running gets a follow-up run once things go quiet again
+		if hasExistingJob && existingJob.Status == "scheduled" {
+			_, err := queries.RescheduleJobReceipt(s.Context, repositories.RescheduleJobReceiptParams{
+				JobID:      existingJob.JobID,
+				JobContext: json.RawMessage(contextBytes),
+				RunAt:      runAt.Unix(),
+				UpdatedAt:  now.Unix(),
+			})
+			if err != nil {
+				return ScheduleJobResponse{}, fmt.Errorf("error rescheduling debounced job: %w", err)
+			}
+
+			response = ScheduleJobResponse{JobId: existingJob.JobID, Status: "debounced"}
+			break
+		}
+
+		jobReceipt, err := s.createJobReceipt(queries, scheduleJobRequest.JobName, contextBytes, uniqueKey, "scheduled", runAt)
+		if err != nil {
+			return ScheduleJobResponse{}, err
+		}
+
+		response = ScheduleJobResponse{JobId: jobReceipt.JobID, Status: "scheduled"}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ScheduleJobResponse{}, fmt.Errorf("error committing unique job: %w", err)
+	}
+
+	if publishReceipt != nil {
+		if err := s.Publisher.PublishJob(*publishReceipt); err != nil {
+			// nothing retries a pending job that was never published, so fail it to
+			// free the unique key for the next submission
+			s.failUnpublishedJob(*publishReceipt, err)
+			return ScheduleJobResponse{}, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *SchedulerService) DispatchScheduledJobs() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.dispatchDueJobs()
+	}
+}
+
+func (s *SchedulerService) dispatchDueJobs() {
+	now := time.Now().Unix()
+
+	jobReceipts, err := s.Queries.ClaimDueJobReceipts(s.Context, now)
+	if err != nil {
+		s.Logger.Error("error claiming scheduled jobs", "err", err)
+		return
+	}
+
+	for _, jobReceipt := range jobReceipts {
+		s.Logger.Info("dispatching scheduled job", "job-id", jobReceipt.JobID)
+
+		if err := s.Publisher.PublishJob(jobReceipt); err != nil {
+			s.Logger.Error("error dispatching scheduled job", "err", err, "job-id", jobReceipt.JobID)
+
+			// hand the job back to the dispatcher so the next tick retries it
+			_, err = s.Queries.UpdateJobReceiptByJobID(s.Context, repositories.UpdateJobReceiptByJobIDParams{
+				JobID:      jobReceipt.JobID,
+				JobName:    jobReceipt.JobName,
+				JobContext: jobReceipt.JobContext,
+				Status:     "scheduled",
+				Message:    jobReceipt.Message,
+				UpdatedAt:  now,
+				RetryCount: jobReceipt.RetryCount,
+			})
+			if err != nil {
+				s.Logger.Error("error returning job to the scheduled queue", "err", err, "job-id", jobReceipt.JobID)
+			}
+		}
+	}
+}
+
+func (s *SchedulerService) failUnpublishedJob(jobReceipt repositories.JobReceipt, publishErr error) {
+	_, err := s.Queries.UpdateJobReceiptByJobID(s.Context, repositories.UpdateJobReceiptByJobIDParams{
+		JobID:      jobReceipt.JobID,
+		JobName:    jobReceipt.JobName,
+		JobContext: jobReceipt.JobContext,
+		Status:     "error",
+		Message:    "error publishing job: " + publishErr.Error(),
+		UpdatedAt:  time.Now().Unix(),
+		RetryCount: jobReceipt.RetryCount,
+	})
+	if err != nil {
+		s.Logger.Error("error failing unpublished job", "err", err, "job-id", jobReceipt.JobID)
+	}
+}
+
+func (s *SchedulerService) createJobReceipt(queries *repositories.Queries, jobName string, contextBytes []byte, uniqueKey string, status string, runAt time.Time) (repositories.JobReceipt, error) {
+	now := time.Now().Unix()
+
+	jobReceipt, err := queries.CreateJobReceipt(s.Context, repositories.CreateJobReceiptParams{
+		JobID:      uuid.NewString(),
+		JobName:    jobName,
+		JobContext: json.RawMessage(contextBytes),
+		Status:     status,
+		RetryCount: 0,
+		Message:    "",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		UniqueKey:  sql.NullString{String: uniqueKey, Valid: uniqueKey != ""},
+		RunAt:      runAt.Unix(),
+	})
+	if err != nil {
+		return repositories.JobReceipt{}, fmt.Errorf("error creating job receipt: %w", err)
+	}
+
+	return jobReceipt, nil
+}
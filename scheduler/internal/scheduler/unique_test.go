@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+func TestUniqueJobKey(t *testing.T) {
+	base, err := uniqueJobKey("send-email", map[string]any{"user_id": 1, "template": "welcome", "attempt": 1}, []string{"user_id", "template"})
+	if err != nil {
+		t.Fatalf("error building unique job key: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		jobName    string
+		jobContext map[string]any
+		fields     []string
+		same       bool
+	}{
+		{
+			name:       "unselected fields are ignored",
+			jobName:    "send-email",
+			jobContext: map[string]any{"user_id": 1, "template": "welcome", "attempt": 2},
+			fields:     []string{"user_id", "template"},
+			same:       true,
+		},
+		{
+			name:       "field order doesn't matter",
+			jobName:    "send-email",
+			jobContext: map[string]any{"user_id": 1, "template": "welcome"},
+			fields:     []string{"template", "user_id"},
+			same:       true,
+		},
+		{
+			name:       "selected field changed",
+			jobName:    "send-email",
+			jobContext: map[string]any{"user_id": 2, "template": "welcome"},
+			fields:     []string{"user_id", "template"},
+		},
+		{
+			name:       "different job",
+			jobName:    "send-sms",
+			jobContext: map[string]any{"user_id": 1, "template": "welcome"},
+			fields:     []string{"user_id", "template"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := uniqueJobKey(test.jobName, test.jobContext, test.fields)
+			if err != nil {
+				t.Fatalf("error building unique job key: %v", err)
+			}
+
+			if (key == base) != test.same {
+				t.Errorf("got key %s, base key %s, want same: %v", key, base, test.same)
+			}
+		})
+	}
+}
+
+func TestUniqueJobKeyMissingField(t *testing.T) {
+	missing, err := uniqueJobKey("send-email", map[string]any{}, []string{"field"})
+	if err != nil {
+		t.Fatalf("error building unique job key: %v", err)
+	}
+
+	empty, err := uniqueJobKey("send-email", map[string]any{"field": ""}, []string{"field"})
+	if err != nil {
+		t.Fatalf("error building unique job key: %v", err)
+	}
+
+	if missing == empty {
+		t.Errorf("got the same key %s for a missing field and an empty string", missing)
+	}
+}
+
+func TestValidateUniqueJobOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options UniqueJobOptions
+		fields  int
+	}{
+		{name: "drop", options: UniqueJobOptions{Mode: UniqueModeDrop}},
+		{name: "coalesce", options: UniqueJobOptions{Mode: UniqueModeCoalesce}},
+		{name: "debounce", options: UniqueJobOptions{Mode: UniqueModeDebounce, DebouncePeriod: "30s"}},
+		{name: "unknown mode", options: UniqueJobOptions{Mode: "replace"}, fields: 1},
+		{name: "debounce without period", options: UniqueJobOptions{Mode: UniqueModeDebounce}, fields: 1},
+		{name: "negative debounce period", options: UniqueJobOptions{Mode: UniqueModeDebounce, DebouncePeriod: "-1s"}, fields: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if fieldErrors := validateUniqueJobOptions(&test.options); len(fieldErrors) != test.fields {
+				t.Errorf("got field errors %v, want %d", fieldErrors, test.fields)
+			}
+		})
+	}
+}
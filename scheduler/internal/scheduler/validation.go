@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// jobSchemaCache keeps compiled schemas per job name. Each entry keeps the bytes it was
+// compiled from, so a schema registered through another scheduler instance is recompiled
+type jobSchemaCache struct {
+	schemas map[string]cachedJobSchema
+	mutex   sync.Mutex
+}
+
+type cachedJobSchema struct {
+	SchemaBytes []byte
+	Schema      *jsonschema.Schema
+}
+
+func newJobSchemaCache() *jobSchemaCache {
+	return &jobSchemaCache{
+		schemas: map[string]cachedJobSchema{},
+	}
+}
+
+func (c *jobSchemaCache) get(jobName string, schemaBytes []byte) (*jsonschema.Schema, error) {
+	c.mutex.Lock()
+	cached, ok := c.schemas[jobName]
+	c.mutex.Unlock()
+
+	if ok && bytes.Equal(cached.SchemaBytes, schemaBytes) {
+		return cached.Schema, nil
+	}
+
+	schema, err := compileJobSchema(jobName, schemaBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.schemas[jobName] = cachedJobSchema{SchemaBytes: bytes.Clone(schemaBytes), Schema: schema}
+	c.mutex.Unlock()
+
+	return schema, nil
+}
+
+func (c *jobSchemaCache) invalidate(jobName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.schemas, jobName)
+}
+
+func compileJobSchema(jobName string, schemaBytes []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	// schemas come from API callers, so refs can't reach files or the network
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("can't load %s, only refs within the schema are allowed", url)
+	}
+
+	resourceName := fmt.Sprintf("%s.schema.json", jobName)
+
+	if err := compiler.AddResource(resourceName, bytes.NewReader(schemaBytes)); err != nil {
+		return nil, err
+	}
+
+	return compiler.Compile(resourceName)
+}
+
+// validateJobContext returns one field error per failing leaf of the schema,
+// so callers can see every invalid field instead of only the first one
+func validateJobContext(schema *jsonschema.Schema, jobContext map[string]any) ([]FieldError, error) {
+	err := schema.Validate(jobContext)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return nil, err
+	}
+
+	var fieldErrors []FieldError
+
+	var collect func(ve *jsonschema.ValidationError)
+	collect = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			field := ve.InstanceLocation
+			if field == "" {
+				field = "/"
+			}
+
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   field,
+				Message: ve.Message,
+			})
+			return
+		}
+
+		for _, cause := range ve.Causes {
+			collect(cause)
+		}
+	}
+	collect(validationError)
+
+	return fieldErrors, nil
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testJobSchema = `{
+	"type": "object",
+	"properties": {
+		"email": {"type": "string"},
+		"count": {"type": "integer", "minimum": 1},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"required": ["email"],
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}
+	}
+}`
+
+func TestValidateJobContext(t *testing.T) {
+	schema, err := compileJobSchema("send-email", []byte(testJobSchema))
+	if err != nil {
+		t.Fatalf("error compiling schema: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		jobContext map[string]any
+		fields     []string
+	}{
+		{
+			name:       "valid",
+			jobContext: map[string]any{"email": "a@b.c", "count": 2, "address": map[string]any{"city": "Paris"}},
+		},
+		{
+			name:       "missing required field",
+			jobContext: map[string]any{"count": 2},
+			fields:     []string{"/"},
+		},
+		{
+			name:       "every invalid field is reported",
+			jobContext: map[string]any{"email": 1, "count": 0},
+			fields:     []string{"/count", "/email"},
+		},
+		{
+			name:       "ref within the schema",
+			jobContext: map[string]any{"email": "a@b.c", "address": map[string]any{"city": 1}},
+			fields:     []string{"/address/city"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fieldErrors, err := validateJobContext(schema, test.jobContext)
+			if err != nil {
+				t.Fatalf("error validating job context: %v", err)
+			}
+
+			var fields []string
+			for _, fieldError := range fieldErrors {
+				fields = append(fields, fieldError.Field)
+			}
+			slices.Sort(fields)
+
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("got field errors %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestCompileJobSchemaRejectsExternalRefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "external.json")
+	if err := os.WriteFile(path, []byte(`{"type": "string"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := []string{
+		"file://" + path,
+		"http://127.0.0.1:1/schema.json",
+	}
+
+	for _, ref := range refs {
+		schemaBytes := []byte(`{"type": "object", "properties": {"a": {"$ref": "` + ref + `"}}}`)
+
+		if _, err := compileJobSchema("external", schemaBytes); err == nil {
+			t.Errorf("compiled a schema referencing %s", ref)
+		}
+	}
+}
+
+func TestJobSchemaCache(t *testing.T) {
+	cache := newJobSchemaCache()
+
+	first, err := cache.get("send-email", []byte(testJobSchema))
+	if err != nil {
+		t.Fatalf("error compiling schema: %v", err)
+	}
+
+	cached, err := cache.get("send-email", []byte(testJobSchema))
+	if err != nil {
+		t.Fatalf("error compiling schema: %v", err)
+	}
+	if cached != first {
+		t.Error("schema was recompiled for the same bytes")
+	}
+
+	changed, err := cache.get("send-email", []byte(`{"type": "object"}`))
+	if err != nil {
+		t.Fatalf("error compiling schema: %v", err)
+	}
+	if changed == first {
+		t.Error("changed schema wasn't recompiled")
+	}
+
+	cache.invalidate("send-email")
+
+	recompiled, err := cache.get("send-email", []byte(`{"type": "object"}`))
+	if err != nil {
+		t.Fatalf("error compiling schema: %v", err)
+	}
+	if recompiled == changed {
+		t.Error("invalidated schema wasn't recompiled")
+	}
+}
@@ -153,3 +153,43 @@ func (w *WatchdogService) checkStuckJobs() {
 		}
 	}
 }
+
+func (w *WatchdogService) WatchExpiredLeases() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.checkExpiredLeases()
+	}
+}
+
+// checkExpiredLeases is the postgres mode counterpart to checkStuckJobs, a job
+// whose worker stopped renewing its lease goes back to pending until it runs out of retries
+func (w *WatchdogService) checkExpiredLeases() {
+	now := time.Now().Unix()
+
+	w.Logger.Info("executing expired leases watchdog")
+
+	failedJobs, err := w.Queries.FailExpiredJobReceiptLeases(w.Context, repositories.FailExpiredJobReceiptLeasesParams{
+		Now:        now,
+		MaxRetries: int32(w.Config.WorkerMaxJobRetries),
+	})
+	if err != nil {
+		w.Logger.Error("error failing expired leases", "err", err)
+		return
+	}
+
+	for _, failedJob := range failedJobs {
+		w.Logger.Error("job marked as failed after max retries", "job-id", failedJob.JobID)
+	}
+
+	requeuedJobs, err := w.Queries.RequeueExpiredJobReceiptLeases(w.Context, now)
+	if err != nil {
+		w.Logger.Error("error requeueing expired leases", "err", err)
+		return
+	}
+
+	for _, requeuedJob := range requeuedJobs {
+		w.Logger.Info("job lease expired, retrying", "job-id", requeuedJob.JobID)
+	}
+}
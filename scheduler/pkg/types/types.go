@@ -2,6 +2,13 @@ package types
 
 import "time"
 
+const (
+	// in rabbitmq mode, HTTP leases live in the memory of the scheduler instance that
+	// handed them out, so leasing over HTTP and push delivery need a single instance
+	SchedulerModeRabbitMQ = "rabbitmq"
+	SchedulerModePostgres = "postgres"
+)
+
 type Config struct {
 	Port                      string        `env:"PORT" json:"port,omitempty"`
 	DatabaseUrl               string        `env:"DATABASE_URL" json:"database_url,omitempty"`
@@ -10,4 +17,7 @@ type Config struct {
 	WorkerUnackedMessageCount int           `env:"WORKER_UNACKED_MESSAGE_COUNT" json:"worker_unacked_message_count,omitempty"`
 	WorkerStuckJobThreshold   time.Duration `env:"WORKER_STUCK_JOB_THRESHOLD" json:"worker_stuck_job_threshold,omitempty"`
 	WorkerMaxJobRetries       int           `env:"WORKER_MAX_JOB_RETRIES" json:"worker_max_job_retries,omitempty"`
+	WorkerLeaseDuration       time.Duration `env:"WORKER_LEASE_DURATION" json:"worker_lease_duration,omitempty"`
+	SchedulerMode             string        `env:"SCHEDULER_MODE" json:"scheduler_mode,omitempty"`
+	PushSigningSecret         string        `env:"PUSH_SIGNING_SECRET" json:"push_signing_secret,omitempty"`
 }
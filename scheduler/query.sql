@@ -43,9 +43,11 @@ INSERT INTO job_receipts (
     job_name,
     job_context,
     created_at,
-    updated_at
+    updated_at,
+    unique_key,
+    run_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 RETURNING *;
 
@@ -62,6 +64,117 @@ SELECT * FROM job_receipts
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2;
 
+-- name: GetActiveJobReceiptByUniqueKey :one
+SELECT * FROM job_receipts
+WHERE unique_key = $1
+AND status IN ('scheduled', 'pending', 'running')
+ORDER BY (status = 'scheduled') DESC, created_at DESC
+LIMIT 1;
+
+-- name: LockUniqueKey :exec
+SELECT pg_advisory_xact_lock(hashtext(@unique_key::text));
+
+-- name: RescheduleJobReceipt :one
+UPDATE job_receipts
+SET
+    job_context = $2,
+    run_at = $3,
+    updated_at = $4
+WHERE job_id = $1
+AND status = 'scheduled'
+RETURNING *;
+
+-- name: ClaimDueJobReceipts :many
+UPDATE job_receipts
+SET
+    status = 'pending',
+    updated_at = @now
+WHERE status = 'scheduled'
+AND run_at <= @now
+RETURNING *;
+
+-- name: LeaseJobReceipt :one
+UPDATE job_receipts
+SET
+    status = 'running',
+    lease_id = @lease_id,
+    lease_expires_at = @lease_expires_at,
+    updated_at = @updated_at
+WHERE job_id = (
+    SELECT job_id FROM job_receipts
+    WHERE status = 'pending'
+    AND job_name = ANY(@job_names::text[])
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: FinishJobReceiptLease :one
+UPDATE job_receipts
+SET
+    status = @status,
+    message = @message,
+    lease_expires_at = 0,
+    updated_at = @updated_at
+WHERE job_id = @job_id
+AND lease_id = @lease_id
+AND status = 'running'
+RETURNING *;
+
+-- name: ExtendJobReceiptLease :one
+UPDATE job_receipts
+SET
+    lease_expires_at = @lease_expires_at,
+    updated_at = @updated_at
+WHERE job_id = @job_id
+AND lease_id = @lease_id
+AND status = 'running'
+RETURNING *;
+
+-- name: ScheduleJobReceiptRetry :one
+UPDATE job_receipts
+SET
+    status = 'scheduled',
+    retry_count = @retry_count,
+    message = @message,
+    run_at = @run_at,
+    updated_at = @updated_at
+WHERE job_id = @job_id
+RETURNING *;
+
+-- name: RecordJobReceiptResponse :exec
+UPDATE job_receipts
+SET
+    response_status_code = @response_status_code,
+    response_body = @response_body
+WHERE job_id = @job_id;
+
+-- name: FailExpiredJobReceiptLeases :many
+UPDATE job_receipts
+SET
+    status = 'error',
+    message = 'marked as failed after max retries',
+    lease_id = NULL,
+    lease_expires_at = 0,
+    updated_at = @now
+WHERE status = 'running'
+AND lease_expires_at < @now
+AND retry_count >= @max_retries
+RETURNING *;
+
+-- name: RequeueExpiredJobReceiptLeases :many
+UPDATE job_receipts
+SET
+    status = 'pending',
+    retry_count = retry_count + 1,
+    lease_id = NULL,
+    lease_expires_at = 0,
+    updated_at = @now
+WHERE status = 'running'
+AND lease_expires_at < @now
+RETURNING *;
+
 -- name: UpdateJobReceiptByID :one
 UPDATE job_receipts
 SET
@@ -124,4 +237,90 @@ RETURNING
     upscale_cooldown,
     downscale_cooldown,
     min_replica_count,
-    max_replica_count;
\ No newline at end of file
+    max_replica_count;
+
+-- name: GetJobSchema :one
+SELECT * FROM job_schemas
+WHERE job_name = $1;
+
+-- name: UpsertJobSchema :one
+INSERT INTO job_schemas (
+    job_name,
+    schema,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    schema = EXCLUDED.schema,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: DeleteJobSchema :exec
+DELETE FROM job_schemas
+WHERE job_name = $1;
+
+-- name: ListLeasedJobNames :many
+SELECT job_name FROM leased_job_names
+WHERE job_name = ANY(@job_names::text[])
+ORDER BY job_name;
+
+-- name: IsJobNameLeased :one
+SELECT EXISTS (SELECT 1 FROM leased_job_names WHERE job_name = $1)
+    OR EXISTS (SELECT 1 FROM push_endpoints WHERE job_name = $1);
+
+-- name: UpsertLeasedJobName :one
+INSERT INTO leased_job_names (
+    job_name,
+    service_id,
+    created_at
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    service_id = EXCLUDED.service_id
+RETURNING *;
+
+-- name: DeleteLeasedJobNamesByServiceID :exec
+DELETE FROM leased_job_names
+WHERE service_id = $1;
+
+-- name: GetPushEndpoint :one
+SELECT * FROM push_endpoints
+WHERE job_name = $1;
+
+-- name: ListPushEndpoints :many
+SELECT * FROM push_endpoints
+ORDER BY job_name;
+
+-- name: UpsertPushEndpoint :one
+INSERT INTO push_endpoints (
+    job_name,
+    service_id,
+    url,
+    timeout_ms,
+    max_retries,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (job_name) DO UPDATE
+SET
+    service_id = EXCLUDED.service_id,
+    url = EXCLUDED.url,
+    timeout_ms = EXCLUDED.timeout_ms,
+    max_retries = EXCLUDED.max_retries,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: DeletePushEndpoint :exec
+DELETE FROM push_endpoints
+WHERE job_name = $1;
+
+-- name: DeletePushEndpointsByServiceID :exec
+DELETE FROM push_endpoints
+WHERE service_id = $1;
@@ -23,5 +23,43 @@ CREATE TABLE job_receipts (
     job_name TEXT NOT NULL,
     job_context JSONB NOT NULL,
     created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    unique_key TEXT,
+    run_at BIGINT NOT NULL DEFAULT 0,
+    lease_id TEXT,
+    lease_expires_at BIGINT NOT NULL DEFAULT 0,
+    response_status_code INTEGER,
+    response_body TEXT
+);
+
+CREATE INDEX idx_job_receipts_unique_key ON job_receipts(unique_key) WHERE unique_key IS NOT NULL;
+CREATE INDEX idx_job_receipts_scheduled_run_at ON job_receipts(run_at) WHERE status = 'scheduled';
+CREATE INDEX idx_job_receipts_pending_job_name ON job_receipts(job_name, created_at) WHERE status = 'pending';
+CREATE INDEX idx_job_receipts_running_lease_expires_at ON job_receipts(lease_expires_at) WHERE status = 'running';
+
+CREATE TABLE job_schemas (
+    job_name VARCHAR(255) NOT NULL PRIMARY KEY,
+    schema JSONB NOT NULL,
+    created_at BIGINT NOT NULL,
     updated_at BIGINT NOT NULL
-);
\ No newline at end of file
+);
+
+CREATE TABLE leased_job_names (
+    job_name VARCHAR(255) NOT NULL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_leased_job_names_service_id ON leased_job_names(service_id);
+
+CREATE TABLE push_endpoints (
+    job_name VARCHAR(255) NOT NULL PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL REFERENCES services(service_id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    timeout_ms BIGINT NOT NULL,
+    max_retries INTEGER NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL
+);
+
+CREATE INDEX idx_push_endpoints_service_id ON push_endpoints(service_id);